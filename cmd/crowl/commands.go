@@ -0,0 +1,561 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"parkjunwoo.com/crowl/pkg/cdxj"
+	"parkjunwoo.com/crowl/pkg/crowl"
+	"parkjunwoo.com/crowl/pkg/warc"
+	"parkjunwoo.com/crowl/pkg/wrc"
+)
+
+func runList(ctx context.Context, args []string) error {
+	var cfg configOptions
+	var target targetOptions
+	fs := newFlagSet("list", "[-year Y -month M | -from D [-to D] | -crawl ID]")
+	cfg.register(fs)
+	target.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	cc, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	// 대상이 없으면 사용 가능한 CC-MAIN 크롤 목록 출력
+	if !target.isSet() {
+		crawls, err := cc.ListCrawls(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range crawls {
+			fmt.Printf("%s\t%s\t%s ~ %s\n", c.ID, c.Name, c.From, c.To)
+		}
+		return nil
+	}
+
+	paths, err := target.paths(ctx, cc)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	return nil
+}
+
+func runDownload(ctx context.Context, args []string) error {
+	var cfg configOptions
+	var target targetOptions
+	fs := newFlagSet("download", "(-year Y -month M | -from D [-to D] | -crawl ID) [-limit N]")
+	cfg.register(fs)
+	target.register(fs)
+	limit := fs.Int("limit", 0, "다운로드할 최대 파일 수 (0이면 전체)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	cc, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	paths, err := target.paths(ctx, cc)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(paths) > *limit {
+		paths = paths[:*limit]
+	}
+	return cc.Download(ctx, paths)
+}
+
+func runParse(ctx context.Context, args []string) error {
+	var cfg configOptions
+	var target targetOptions
+	fs := newFlagSet("parse", "(-year Y -month M | -from D [-to D] | -crawl ID | -file PATH [-out PATH]) [-stale]")
+	cfg.register(fs)
+	target.register(fs)
+	file := fs.String("file", "", "파싱할 로컬 WARC/WET/WAT 파일")
+	out := fs.String("out", "", "-file 결과 저장 경로 (기본값: data-dir 아래 같은 이름의 출력 파일 .wrc.gz, .jsonl.gz 등)")
+	stale := fs.Bool("stale", false, "정제 설정이나 crowl 버전이 바뀌었거나 URL 캡처가 대체된 완료 파일만 다시 처리 (url.keep은 첫 처리 뒤 한 번 더 실행해야 URL마다 캡처가 하나만 남음)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	cc, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	cc.Stale = *stale
+
+	switch {
+	case *file != "":
+		savePath := *out
+		if savePath == "" {
+			savePath = filepath.Join(cc.DataDir, cc.OutputFileName(*file))
+		}
+		return cc.ParseFile(ctx, *file, savePath)
+	case target.from != "":
+		from, to, err := target.dateRange()
+		if err != nil {
+			return err
+		}
+		return cc.GetNewsRange(ctx, from, to)
+	}
+
+	crawl, err := target.crawlTarget()
+	if err != nil {
+		return err
+	}
+	return cc.Process(ctx, crawl)
+}
+
+func runValidate(ctx context.Context, args []string) error {
+	fs := newFlagSet("validate", "-in PATH -out PATH")
+	config := fs.String("config", defaultConfigPath(), "설정 파일 경로 (환경 변수 CROWL_CONFIG)")
+	in := fs.String("in", "", "입력 wrc.gz 또는 jsonl.gz/jsonl.zst 파일")
+	out := fs.String("out", "", "판별 결과 저장 파일")
+	batchSize := fs.Int("batch-size", 0, "추론 배치 크기 (설정 파일 값 덮어쓰기)")
+	pyPath := fs.String("py-path", "", "추론 서버 스크립트 경로 (설정 파일 값 덮어쓰기)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		return fmt.Errorf("%w: -in과 -out이 필요합니다", errUsage)
+	}
+
+	vn, err := crowl.NewValidNews(*config)
+	if err != nil {
+		return fmt.Errorf("설정 파일 로드 오류(%s): %w", *config, err)
+	}
+	if *batchSize > 0 {
+		vn.BatchSize = *batchSize
+	}
+	if *pyPath != "" {
+		vn.PyPath = *pyPath
+	}
+	return vn.ProcessWRC(ctx, *in, *out)
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	var cfg configOptions
+	var target targetOptions
+	fs := newFlagSet("status", "(-year Y -month M | -crawl ID) [-v] [-status S]")
+	cfg.register(fs)
+	target.register(fs)
+	verbose := fs.Bool("v", false, "파일별 상태 모두 출력")
+	only := fs.String("status", "", "해당 상태의 파일만 출력 (pending|downloading|downloaded|parsing|done|failed)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	cc, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	crawl, err := target.crawlTarget()
+	if err != nil {
+		return err
+	}
+
+	saveDir := crawl.SaveDir(cc.DataDir)
+	states, err := cc.FileStates(crawl)
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	var corrupted, stale int
+	var inBytes, outBytes, records int64
+	var download, parse time.Duration
+	for _, st := range states {
+		counts[st.Status]++
+		if st.Corrupted {
+			corrupted++
+		}
+		if cc.IsStale(st) {
+			stale++
+		}
+		inBytes += st.Bytes
+		outBytes += st.OutputBytes
+		records += st.Records
+		download += st.Download
+		parse += st.Parse
+	}
+
+	fmt.Printf("크롤: %s\n", crawl.ID)
+	fmt.Printf("저장 디렉토리: %s\n", saveDir)
+	fmt.Printf("파일: %d (완료 %d, 대기 %d, 다운로드 %d/%d, 파싱 %d, 실패 %d, 손상 %d)\n",
+		len(states), counts[crowl.StatusDone], counts[crowl.StatusPending],
+		counts[crowl.StatusDownloading], counts[crowl.StatusDownloaded],
+		counts[crowl.StatusParsing], counts[crowl.StatusFailed], corrupted)
+	fmt.Printf("재처리 대상: %d (현재 설정 지문 %s, 버전 %s)\n", stale, cc.Fingerprint(), crowl.Version)
+	fmt.Printf("입력: %.1f MiB, 출력: %.1f MiB, 레코드: %d\n", float64(inBytes)/(1<<20), float64(outBytes)/(1<<20), records)
+	fmt.Printf("소요 시간: 다운로드 %s, 파싱 %s\n", download.Round(time.Second), parse.Round(time.Second))
+	if _, err := os.Stat(filepath.Join(saveDir, "index.cdxj")); err == nil {
+		fmt.Println("인덱스: index.cdxj")
+	}
+
+	for _, st := range states {
+		switch {
+		case *only != "":
+			if st.Status != *only {
+				continue
+			}
+		case !*verbose && st.Status != crowl.StatusFailed:
+			continue
+		}
+		printFileState(cc, st)
+	}
+	return nil
+}
+
+func printFileState(cc *crowl.CommonCrawl, st crowl.FileState) {
+	status := st.Status
+	if st.Corrupted {
+		status += "(손상)"
+	}
+	switch {
+	case st.Status == crowl.StatusDone && st.Superseded:
+		status += "(URL 대체)"
+	case cc.IsStale(st):
+		status += "(이전 설정)"
+	}
+	fmt.Printf("  %-50s %-12s %s", st.Name, status, st.UpdatedAt.Local().Format(time.DateTime))
+	if st.Status == crowl.StatusDone {
+		fmt.Printf("  레코드 %d, %.1f MiB, %s", st.Records, float64(st.OutputBytes)/(1<<20), (st.Download + st.Parse).Round(time.Second))
+	}
+	if st.Error != "" {
+		fmt.Printf("  %s", st.Error)
+	}
+	fmt.Println()
+}
+
+func runInspect(ctx context.Context, args []string) error {
+	fs := newFlagSet("inspect", "[-n N] [-offset O] FILE.warc.gz | [-n N] [-offset O] [-url S] [-host H] FILE.wrc.gz")
+	n := fs.Int("n", 0, "헤더(wrc는 본문 앞부분)를 출력할 레코드 수")
+	offset := fs.Int64("offset", -1, "해당 오프셋의 gzip 멤버 레코드 하나만 출력")
+	urlPattern := fs.String("url", "", "URL에 이 문자열이 들어간 레코드만 출력 (wrc)")
+	host := fs.String("host", "", "이 호스트(하위 도메인 포함)의 레코드만 출력 (wrc)")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	isWRC := strings.HasSuffix(fs.Arg(0), ".wrc.gz")
+	if !isWRC && (*urlPattern != "" || *host != "") {
+		return fmt.Errorf("%w: -url, -host는 wrc.gz 파일에만 사용할 수 있습니다", errUsage)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if isWRC {
+		if *offset >= 0 {
+			rec, err := wrc.ReadRecord(f, *offset)
+			if err != nil {
+				return err
+			}
+			printWRCRecord(rec)
+			return nil
+		}
+		return inspectWRC(f, *n, wrcMatcher(*urlPattern, *host))
+	}
+
+	if *offset >= 0 {
+		rec, err := warc.OpenRecord(f, *offset, 0)
+		if err != nil {
+			return err
+		}
+		printRecord(rec)
+		body, err := io.ReadAll(io.LimitReader(rec.Body, 2048))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n%s\n", body)
+		return nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	counts := map[string]int{}
+	var total int
+	var bodyBytes int64
+	wr := warc.NewReader(gz)
+	for {
+		rec, err := wr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%d번째 레코드 읽기 오류: %w", total+1, err)
+		}
+		if total < *n {
+			printRecord(rec)
+			fmt.Println()
+		}
+		counts[rec.Type()]++
+		bodyBytes += rec.ContentLength()
+		total++
+	}
+
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	fmt.Printf("레코드: %d (본문 %.1f MiB)\n", total, float64(bodyBytes)/(1<<20))
+	for _, t := range types {
+		fmt.Printf("  %-12s %d\n", t, counts[t])
+	}
+	return nil
+}
+
+// wrcMatcher는 -url, -host 조건에 맞는지 확인하는 함수를 반환합니다. 조건이 없으면 nil입니다.
+func wrcMatcher(pattern, host string) func(*wrc.Record) bool {
+	if pattern == "" && host == "" {
+		return nil
+	}
+	host = strings.ToLower(strings.TrimPrefix(host, "."))
+	return func(rec *wrc.Record) bool {
+		if pattern != "" && !strings.Contains(rec.URL, pattern) {
+			return false
+		}
+		if host != "" {
+			h := recordHost(rec.URL)
+			return h == host || strings.HasSuffix(h, "."+host)
+		}
+		return true
+	}
+}
+
+func recordHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// wrcSizeBuckets는 본문 크기 히스토그램 구간의 상한입니다 (마지막 구간은 그 이상 전부).
+var wrcSizeBuckets = []int{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// inspectWRC는 wrc.gz 파일의 형식 정보, 레코드 수, 판정·호스트별 개수, 본문 크기 히스토그램을 출력합니다.
+// v2 파일은 푸터의 레코드 수와 체크섬을 확인한 결과도 출력합니다.
+// match가 있으면 맞는 레코드의 URL을 grep처럼 출력하고 통계도 그 레코드들로만 냅니다.
+// 처음 n개 레코드는 본문 앞부분과 함께 출력합니다.
+func inspectWRC(r io.Reader, n int, match func(*wrc.Record) bool) error {
+	wr, err := wrc.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer wr.Close()
+
+	var total int
+	var bodyBytes int64
+	labels := map[string]int{}
+	hosts := map[string]int{}
+	sizes := make([]int, len(wrcSizeBuckets)+1)
+	var damaged error
+	for rec, err := range wr.All() {
+		if errors.Is(err, wrc.ErrTruncated) || errors.Is(err, wrc.ErrChecksum) {
+			damaged = err
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if match != nil && !match(rec) {
+			continue
+		}
+
+		if total < n {
+			printWRCRecord(rec)
+			fmt.Println()
+		} else if match != nil {
+			fmt.Println(rec.URL)
+		}
+		total++
+		bodyBytes += int64(len(rec.Content))
+		if rec.Label != "" {
+			labels[rec.Label]++
+		}
+		hosts[recordHost(rec.URL)]++
+		i := sort.SearchInts(wrcSizeBuckets, len(rec.Content)+1)
+		sizes[i]++
+	}
+	if match != nil && total > n {
+		fmt.Println()
+	}
+
+	h := wr.Header()
+	fmt.Printf("형식: v%d", h.Version)
+	if h.Version >= 2 {
+		fmt.Printf(" (원본 %s, crowl %s, 지문 %s)", h.Source, h.Crowl, h.Fingerprint)
+	}
+	fmt.Println()
+	fmt.Printf("레코드: %d (본문 %.1f MiB, 호스트 %d개)\n", total, float64(bodyBytes)/(1<<20), len(hosts))
+	switch {
+	case damaged != nil:
+		fmt.Printf("  ⚠️ %v\n", damaged)
+	case wr.Footer() != nil:
+		fmt.Printf("  푸터 확인: 레코드 %d개, %s\n", wr.Footer().Records, wr.Footer().Checksum)
+	}
+	if len(labels) > 0 {
+		fmt.Println("판정:")
+		printCounts(labels, 0)
+	}
+	fmt.Println("상위 호스트:")
+	printCounts(hosts, 10)
+
+	fmt.Println("본문 크기:")
+	for i, c := range sizes {
+		label := "≥ " + formatSize(wrcSizeBuckets[len(wrcSizeBuckets)-1])
+		if i < len(wrcSizeBuckets) {
+			label = "< " + formatSize(wrcSizeBuckets[i])
+		}
+		bar := ""
+		if total > 0 {
+			bar = strings.Repeat("#", c*40/total)
+		}
+		fmt.Printf("  %-9s %8d %s\n", label, c, bar)
+	}
+	return nil
+}
+
+// printCounts는 개수가 많은 순으로 최대 limit개(0이면 전부) 출력합니다.
+func printCounts(counts map[string]int, limit int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	for _, k := range keys {
+		fmt.Printf("  %-30s %d\n", k, counts[k])
+	}
+}
+
+func formatSize(n int) string {
+	if n >= 1<<20 {
+		return fmt.Sprintf("%d MiB", n>>20)
+	}
+	return fmt.Sprintf("%d KiB", n>>10)
+}
+
+func printWRCRecord(rec *wrc.Record) {
+	fmt.Println(rec.URL)
+	if rec.Label != "" {
+		fmt.Printf("판정: %s\n", rec.Label)
+	}
+	fmt.Printf("길이: %d\n", len(rec.Content))
+	body := rec.Content
+	if len(body) > 300 {
+		cut := 300
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = append(body[:cut:cut], "..."...)
+	}
+	fmt.Printf("%s\n", body)
+}
+
+func printRecord(rec *warc.Record) {
+	fmt.Println(rec.Version)
+	for _, f := range rec.Header {
+		fmt.Printf("%s: %s\n", f.Name, f.Value)
+	}
+}
+
+func runIndex(ctx context.Context, args []string) error {
+	var cfg configOptions
+	var target targetOptions
+	fs := newFlagSet("index", "(-year Y -month M | -from DATE [-to DATE] | -crawl ID) [-url URL | -domain HOST]")
+	cfg.register(fs)
+	target.register(fs)
+	url := fs.String("url", "", "조회할 URL")
+	domain := fs.String("domain", "", "조회할 호스트")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	crawls, err := target.crawls()
+	if err != nil {
+		return err
+	}
+
+	cc, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	if *url == "" && *domain == "" {
+		for _, crawl := range crawls {
+			if err := cc.MergeIndex(crawl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// -from/-to는 달마다의 인덱스를 조회하고 기간 밖의 항목은 거름
+	var lo, hi string
+	if target.from != "" {
+		from, to, err := target.dateRange()
+		if err != nil {
+			return err
+		}
+		lo, hi = from.Format(cdxj.TimestampFormat), to.Format(cdxj.TimestampFormat)
+	}
+	for _, crawl := range crawls {
+		var entries []cdxj.Entry
+		if *url != "" {
+			entries, err = cc.LookupURL(crawl, *url)
+		} else {
+			entries, err = cc.LookupDomain(crawl, strings.TrimSpace(*domain))
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if lo != "" && (e.Timestamp < lo || e.Timestamp >= hi) {
+				continue
+			}
+			fmt.Println(e.String())
+		}
+	}
+	return nil
+}
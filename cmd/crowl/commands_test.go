@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"parkjunwoo.com/crowl/pkg/cdxj"
+	"parkjunwoo.com/crowl/pkg/crowl"
+	"parkjunwoo.com/crowl/pkg/wrc"
+)
+
+// captureStdout는 fn을 실행하는 동안 표준 출력에 쓴 내용을 반환합니다.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	return <-out, err
+}
+
+// writeGzip은 content를 gzip 멤버 하나로 압축하여 dir/name에 기록합니다.
+func writeGzip(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	gw.Write(content)
+	gw.Close()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspectWRC(t *testing.T) {
+	dir := t.TempDir()
+
+	var v2 bytes.Buffer
+	w, err := wrc.NewWriter(&v2, wrc.Header{Source: "a.warc.gz", Crowl: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []*wrc.Record{
+		{URL: "https://news.example.com/1", Label: "news", Content: []byte("첫 기사")},
+		{URL: "https://www.example.org/2", Label: "other", Content: []byte("둘째")},
+		{URL: "https://news.example.com/3", Label: "news", Content: []byte("셋째 기사")},
+	} {
+		if _, _, err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	full := filepath.Join(dir, "full.wrc.gz")
+	if err := os.WriteFile(full, v2.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cut := filepath.Join(dir, "cut.wrc.gz")
+	if err := os.WriteFile(cut, v2.Bytes()[:v2.Len()-40], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	truncatedV1 := writeGzip(t, dir, "v1.wrc.gz", []byte("https://a.com/\n3\nabc\n\nhttps://b.com/\n100\nabc"))
+	noTrailer := writeGzip(t, dir, "trailer.wrc.gz", []byte("https://a.com/\n3\nabcd\n\nhttps://b.com/\n1\nx\n\n"))
+
+	tests := []struct {
+		name string
+		args []string
+		want []string // 출력에 들어가야 할 문자열
+		err  error
+	}{
+		{"v2", []string{full}, []string{"형식: v2 (원본 a.warc.gz", "레코드: 3 ", "푸터 확인: 레코드 3개", "판정:", "news", "other"}, nil},
+		{"판정 레코드 출력", []string{"-n", "1", full}, []string{"https://news.example.com/1\n판정: news\n길이: 10\n첫 기사"}, nil},
+		{"호스트 거르기", []string{"-host", "example.com", full}, []string{"https://news.example.com/3\n", "레코드: 2 "}, nil},
+		{"URL 거르기", []string{"-url", "/2", full}, []string{"https://www.example.org/2\n", "레코드: 1 "}, nil},
+		{"v2 푸터 잘림", []string{cut}, []string{"레코드: 3 ", "⚠️", "잘림"}, nil},
+		{"v1 마지막 레코드 잘림", []string{truncatedV1}, []string{"형식: v1", "레코드: 1 ", "⚠️", "2번째 레코드"}, nil},
+		{"본문 뒤 빈 줄 누락", []string{noTrailer}, nil, wrc.ErrTrailer},
+		{"wrc 전용 옵션", []string{"-host", "a.com", filepath.Join(dir, "a.warc.gz")}, nil, errUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := captureStdout(t, func() error {
+				return runInspect(context.Background(), tt.args)
+			})
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("오류 = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("출력에 %q가 없습니다:\n%s", s, out)
+				}
+			}
+		})
+	}
+
+	// -offset은 푸터의 오프셋으로 레코드 하나만 출력
+	f, err := os.Open(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	footer, err := wrc.ReadFooter(f, int64(v2.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureStdout(t, func() error {
+		return runInspect(context.Background(), []string{"-offset", strconv.FormatInt(footer.Offsets[1], 10), full})
+	})
+	if err != nil || !strings.HasPrefix(out, "https://www.example.org/2\n판정: other\n") {
+		t.Errorf("-offset: %q, %v", out, err)
+	}
+}
+
+func TestIndexTargets(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "crowl.yaml")
+	if err := os.WriteFile(config, []byte("workers: 1\ndata_dir: "+filepath.Join(dir, "data")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(crawl crowl.Crawl, name string, entries ...cdxj.Entry) {
+		saveDir := crawl.SaveDir(filepath.Join(dir, "data"))
+		if err := os.MkdirAll(saveDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := cdxj.WriteFile(filepath.Join(saveDir, name), entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 12, 0, 0, 0, time.UTC) }
+	mainCrawl, _ := crowl.MainCrawl("CC-MAIN-2025-13")
+	write(mainCrawl, "a.cdxj", cdxj.NewEntry("https://www.example.com/main", day(3, 20), "a.wrc.gz", 0, 10))
+	write(crowl.NewsCrawl(2025, 4), "b.cdxj",
+		cdxj.NewEntry("https://www.example.com/apr1", day(4, 1), "b.wrc.gz", 0, 10),
+		cdxj.NewEntry("https://www.example.com/apr30", day(4, 30), "b.wrc.gz", 10, 10))
+	write(crowl.NewsCrawl(2025, 5), "c.cdxj", cdxj.NewEntry("https://www.example.com/may2", day(5, 2), "c.wrc.gz", 0, 10))
+
+	tests := []struct {
+		name string
+		args []string
+		want []string // 출력에 들어가야 할 URL
+		skip []string // 출력에 없어야 할 URL
+	}{
+		{"CC-MAIN 병합", []string{"-crawl", "CC-MAIN-2025-13"}, nil, nil},
+		{"CC-MAIN 조회", []string{"-crawl", "CC-MAIN-2025-13", "-domain", "www.example.com"}, []string{"/main"}, []string{"/apr1"}},
+		{"기간 병합", []string{"-from", "2025-04-15", "-to", "2025-05-02"}, nil, nil},
+		{"기간 조회", []string{"-from", "2025-04-15", "-to", "2025-05-02", "-domain", "www.example.com"},
+			[]string{"/apr30", "/may2"}, []string{"/apr1", "/main"}},
+		{"월 URL 조회", []string{"-year", "2025", "-month", "4", "-url", "https://www.example.com/apr1"}, []string{"/apr1"}, []string{"/apr30"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 조회는 앞의 병합 케이스가 만든 index.cdxj를 씀
+			args := append([]string{"-config", config}, tt.args...)
+			out, err := captureStdout(t, func() error { return runIndex(context.Background(), args) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, "https://www.example.com"+s+`"`) {
+					t.Errorf("출력에 %s가 없습니다:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.skip {
+				if strings.Contains(out, "https://www.example.com"+s+`"`) {
+					t.Errorf("출력에 %s가 있습니다:\n%s", s, out)
+				}
+			}
+		})
+	}
+
+	if err := runIndex(context.Background(), []string{"-config", config, "-crawl", "CC-MAIN-2025-14", "-domain", "www.example.com"}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("병합 인덱스 없는 크롤: %v", err)
+	}
+	if err := runIndex(context.Background(), []string{"-config", config}); !errors.Is(err, errUsage) {
+		t.Errorf("대상 없음: %v", err)
+	}
+}
+
+// validate도 다른 명령처럼 CROWL_CONFIG를 -config 기본값으로 씁니다.
+func TestValidateConfigEnv(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "none.yaml")
+	t.Setenv("CROWL_CONFIG", config)
+	err := runValidate(context.Background(), []string{"-in", "in.wrc.gz", "-out", "out.wrc.gz"})
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), config) {
+		t.Errorf("오류 = %v, want %s 없음", err, config)
+	}
+}
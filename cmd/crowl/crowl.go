@@ -1,12 +1,291 @@
 package main
 
-import "parkjunwoo.com/crowl/pkg/crowl"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"parkjunwoo.com/crowl/pkg/crowl"
+)
+
+// 종료 코드
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+	exitAbort = 130 // SIGINT/SIGTERM으로 중단
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"list", "크롤 ID 또는 크롤/기간의 파일 경로 목록 출력", runList},
+	{"download", "크롤/기간의 파일을 파싱 없이 임시 디렉토리에 다운로드", runDownload},
+	{"parse", "크롤/기간의 파일을 다운로드하고 파싱 (또는 로컬 파일 파싱)", runParse},
+	{"validate", "wrc.gz/jsonl 파일을 뉴스 판별 모델로 검증", runValidate},
+	{"status", "저장 디렉토리의 처리 현황 출력", runStatus},
+	{"inspect", "WARC 파일의 레코드 통계와 헤더, wrc.gz 파일의 레코드 통계와 URL·호스트 검색 출력", runInspect},
+	{"index", "CDXJ 인덱스 병합 및 URL 조회", runIndex},
+}
+
+// errUsage는 잘못된 인자 사용을 나타내며 종료 코드 2로 처리됩니다.
+var errUsage = errors.New("잘못된 사용법")
 
 func main() {
-	cc, err := crowl.NewCommonCrawl("../../config/crowl.yaml")
+	// Ctrl-C(SIGINT)나 SIGTERM을 받으면 ctx를 취소하여 진행 중인 작업을 정리하고 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// 첫 신호 뒤에는 기본 동작으로 되돌려 두 번째 신호에 바로 종료
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	code := run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(ctx, args[1:])
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case ctx.Err() != nil:
+			fmt.Fprintf(os.Stderr, "crowl %s: 중단됨\n", cmd.name)
+			return exitAbort
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(os.Stderr, "crowl %s: %v\n", cmd.name, err)
+			return exitUsage
+		default:
+			fmt.Fprintf(os.Stderr, "crowl %s: %v\n", cmd.name, err)
+			return exitError
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "알 수 없는 명령: %s\n\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "사용법: crowl <명령> [옵션]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "명령:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "각 명령의 옵션은 'crowl <명령> -h'로 확인하세요.")
+}
+
+// newFlagSet은 명령별 FlagSet을 만듭니다. 파싱 오류는 errUsage로 처리됩니다.
+func newFlagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "사용법: crowl %s %s\n\n옵션:\n", name, usageLine)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags는 옵션을 파싱하고 위치 인자가 정확히 nargs개인지 확인합니다.
+func parseFlags(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() != nargs {
+		return fmt.Errorf("%w: 위치 인자 %d개가 필요합니다 (입력: %v)", errUsage, nargs, fs.Args())
+	}
+	return nil
+}
+
+// configOptions는 설정 파일 경로와 YAML 값을 덮어쓰는 공통 옵션입니다.
+type configOptions struct {
+	path      string
+	workers   int
+	predowns  int
+	dataDir   string
+	tempDir   string
+	mode      string
+	extractor string
+	baseURL   string
+	keepRaw   bool
+}
+
+// defaultConfigPath는 -config의 기본값입니다. 환경 변수 CROWL_CONFIG가 있으면 그 값을 씁니다.
+func defaultConfigPath() string {
+	if path := os.Getenv("CROWL_CONFIG"); path != "" {
+		return path
+	}
+	return "config/crowl.yaml"
+}
+
+func (o *configOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.path, "config", defaultConfigPath(), "설정 파일 경로 (환경 변수 CROWL_CONFIG)")
+	fs.IntVar(&o.workers, "workers", 0, "파싱 워커 수 (설정 파일 값 덮어쓰기)")
+	fs.IntVar(&o.predowns, "predowns", 0, "동시 다운로드 수 (설정 파일 값 덮어쓰기)")
+	fs.StringVar(&o.dataDir, "data-dir", "", "결과 저장 디렉토리 (설정 파일 값 덮어쓰기)")
+	fs.StringVar(&o.tempDir, "temp-dir", "", "임시 디렉토리 (설정 파일 값 덮어쓰기)")
+	fs.StringVar(&o.mode, "mode", "", "처리 모드 warc|wet|wat (설정 파일 값 덮어쓰기)")
+	fs.StringVar(&o.extractor, "extractor", "", "warc 모드 본문 추출 방식 clean|readability (설정 파일 값 덮어쓰기)")
+	fs.StringVar(&o.baseURL, "base-url", "", "파일 저장소 https://, file:///경로/, s3://버킷/접두어/ (설정 파일 값 덮어쓰기)")
+	fs.BoolVar(&o.keepRaw, "keep-raw", false, "파싱 후에도 다운로드한 원본 파일을 임시 디렉토리에 보존")
+}
+
+// load는 설정 파일을 읽고 명령줄 옵션으로 덮어쓴 뒤 검증합니다.
+// 처리 모드에 따라 달라지는 검증과 기본값(predowns 등)은 덮어쓴 값을 기준으로 합니다.
+func (o *configOptions) load() (*crowl.CommonCrawl, error) {
+	cc, err := crowl.LoadCommonCrawl(o.path)
+	if err != nil {
+		return nil, fmt.Errorf("설정 파일 로드 오류(%s): %w", o.path, err)
+	}
+
+	if o.workers > 0 {
+		cc.Workers = o.workers
+	}
+	if o.predowns > 0 {
+		cc.Predowns = o.predowns
+	}
+	if o.dataDir != "" {
+		cc.DataDir = o.dataDir
+	}
+	if o.tempDir != "" {
+		cc.TempDir = o.tempDir
+	}
+	if o.baseURL != "" {
+		cc.BaseURL = o.baseURL
+	}
+	if o.keepRaw {
+		cc.KeepRaw = true
+	}
+	switch o.mode {
+	case "":
+	case crowl.KindWarc, crowl.KindWet, crowl.KindWat:
+		cc.Mode = o.mode
+	default:
+		return nil, fmt.Errorf("%w: 알 수 없는 처리 모드 %s", errUsage, o.mode)
+	}
+	switch o.extractor {
+	case "":
+	case crowl.ExtractorClean, crowl.ExtractorReadability:
+		cc.Extractor = o.extractor
+	default:
+		return nil, fmt.Errorf("%w: 알 수 없는 본문 추출 방식 %s", errUsage, o.extractor)
+	}
+
+	if err := cc.Validate(); err != nil {
+		return nil, fmt.Errorf("설정 오류(%s): %w", o.path, err)
+	}
+	return cc, nil
+}
+
+// targetOptions는 처리 대상(CC-NEWS 월/기간 또는 CC-MAIN 크롤)을 지정하는 옵션입니다.
+type targetOptions struct {
+	year  int
+	month int
+	from  string
+	to    string
+	crawl string
+}
+
+func (o *targetOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.year, "year", 0, "CC-NEWS 연도")
+	fs.IntVar(&o.month, "month", 0, "CC-NEWS 월")
+	fs.StringVar(&o.from, "from", "", "CC-NEWS 기간 시작일 (YYYY-MM-DD)")
+	fs.StringVar(&o.to, "to", "", "CC-NEWS 기간 종료일 (YYYY-MM-DD, 해당 일 포함, 기본값은 시작일)")
+	fs.StringVar(&o.crawl, "crawl", "", "CC-MAIN 크롤 ID (예: CC-MAIN-2025-13)")
+}
+
+func (o *targetOptions) isSet() bool {
+	return o.crawl != "" || o.from != "" || o.year != 0 || o.month != 0
+}
+
+// dateRange는 -from/-to를 [from, to) 기간으로 변환합니다.
+func (o *targetOptions) dateRange() (time.Time, time.Time, error) {
+	from, err := time.Parse(time.DateOnly, o.from)
 	if err != nil {
-		panic(err)
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: -from %v", errUsage, err)
 	}
+	to := from
+	if o.to != "" {
+		if to, err = time.Parse(time.DateOnly, o.to); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: -to %v", errUsage, err)
+		}
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// crawlTarget은 -crawl 또는 -year/-month를 Crawl로 변환합니다.
+func (o *targetOptions) crawlTarget() (crowl.Crawl, error) {
+	if o.crawl != "" {
+		crawl, err := crowl.MainCrawl(o.crawl)
+		if err != nil {
+			return crowl.Crawl{}, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		return crawl, nil
+	}
+	if o.year < 1 || o.month < 1 || o.month > 12 {
+		return crowl.Crawl{}, fmt.Errorf("%w: -year와 -month(1~12), -from 또는 -crawl 중 하나가 필요합니다", errUsage)
+	}
+	return crowl.NewsCrawl(o.year, o.month), nil
+}
 
-	cc.GetNews(2025, 3)
+// crawls는 대상에 해당하는 크롤 목록을 반환합니다. -from/-to는 기간에 걸친 달마다의 CC-NEWS 크롤입니다.
+func (o *targetOptions) crawls() ([]crowl.Crawl, error) {
+	if o.from != "" {
+		from, to, err := o.dateRange()
+		if err != nil {
+			return nil, err
+		}
+		if !from.Before(to) {
+			return nil, fmt.Errorf("%w: -to가 -from보다 앞섭니다", errUsage)
+		}
+		return crowl.NewsCrawls(from, to), nil
+	}
+	crawl, err := o.crawlTarget()
+	if err != nil {
+		return nil, err
+	}
+	return []crowl.Crawl{crawl}, nil
+}
+
+// paths는 대상에 해당하는 원격 파일 경로 목록을 반환합니다.
+func (o *targetOptions) paths(ctx context.Context, cc *crowl.CommonCrawl) ([]string, error) {
+	if o.from != "" {
+		from, to, err := o.dateRange()
+		if err != nil {
+			return nil, err
+		}
+		return cc.NewsRangePaths(ctx, from, to)
+	}
+	crawl, err := o.crawlTarget()
+	if err != nil {
+		return nil, err
+	}
+	return cc.ListPaths(ctx, crawl, cc.Mode)
 }
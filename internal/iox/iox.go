@@ -0,0 +1,17 @@
+// Package iox는 crowl의 여러 패키지가 함께 쓰는 io 보조 타입입니다.
+package iox
+
+import "io"
+
+// CountWriter는 W에 기록한 바이트 수를 N에 셉니다.
+// 레코드를 gzip 멤버 단위로 기록하면서 멤버의 압축 후 길이를 구할 때 씁니다.
+type CountWriter struct {
+	W io.Writer
+	N int64
+}
+
+func (c *CountWriter) Write(p []byte) (int, error) {
+	n, err := c.W.Write(p)
+	c.N += int64(n)
+	return n, err
+}
@@ -0,0 +1,173 @@
+// Package article는 HTML 문서에서 본문 기사와 메타데이터를 추출합니다.
+package article
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrNoContent는 본문으로 볼 만한 영역을 찾지 못했음을 나타냅니다.
+var ErrNoContent = errors.New("article: 본문 없음")
+
+// Article은 본문 추출 결과입니다.
+type Article struct {
+	Title     string `json:"title,omitempty"`
+	Byline    string `json:"byline,omitempty"`
+	Published string `json:"published,omitempty"` // 해석 가능하면 RFC3339, 아니면 원문 그대로
+	Image     string `json:"image,omitempty"`     // 대표 이미지 절대 URL
+	Text      string `json:"text"`                // 문단을 빈 줄로 구분한 본문 텍스트
+	HTML      string `json:"html,omitempty"`      // 본문 영역의 HTML
+}
+
+// Extract는 rawHTML에서 텍스트 밀도와 링크 밀도로 본문 영역을 골라 기사 본문과
+// 제목, 작성자, 발행일, 대표 이미지를 추출합니다. pageURL은 상대 URL 해석에 사용합니다.
+func Extract(rawHTML []byte, pageURL string) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(rawHTML))
+	if err != nil {
+		return nil, err
+	}
+	return FromDocument(doc, pageURL, nil)
+}
+
+// FromDocument는 이미 파싱한 doc에서 Extract와 같이 기사를 추출합니다.
+// m은 같은 doc에서 미리 추출한 메타데이터이며, nil이면 doc에서 추출합니다.
+// 본문 후보를 고르면서 doc을 수정하므로, 메타데이터가 필요하면 먼저 ExtractMetadata를 호출해야 합니다.
+func FromDocument(doc *goquery.Document, pageURL string, m *Metadata) (*Article, error) {
+	// 메타데이터는 본문 후보를 고르기 전에 전체 문서에서 찾음
+	if m == nil {
+		m = ExtractMetadata(doc, pageURL)
+	}
+	a := &Article{
+		Title:     m.Title,
+		Byline:    strings.Join(m.Authors, ", "),
+		Published: m.Published,
+		Image:     m.Image,
+	}
+
+	content := readability(doc)
+	if content == nil {
+		return nil, ErrNoContent
+	}
+	a.Text = text(content)
+	if a.Text == "" {
+		return nil, ErrNoContent
+	}
+	a.HTML, _ = goquery.OuterHtml(content)
+	return a, nil
+}
+
+// metaContent는 selectors 순서대로 첫 번째로 값이 있는 meta content를 반환합니다.
+func metaContent(doc *goquery.Document, selectors ...string) string {
+	for _, sel := range selectors {
+		if v := strings.TrimSpace(doc.Find(sel).First().AttrOr("content", "")); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func title(doc *goquery.Document) string {
+	if t := metaContent(doc, `meta[property="og:title"]`, `meta[name="twitter:title"]`); t != "" {
+		return t
+	}
+
+	t := normalizeSpace(doc.Find("title").First().Text())
+	// "기사 제목 | 사이트 이름" 형식이면 사이트 이름을 떼어냄
+	for _, sep := range []string{" | ", " - ", " :: ", " — ", " « ", " » "} {
+		if i := strings.LastIndex(t, sep); i > 0 && len(strings.Fields(t[:i])) >= 3 {
+			t = t[:i]
+			break
+		}
+	}
+	if t == "" {
+		t = normalizeSpace(doc.Find("h1").First().Text())
+	}
+	return t
+}
+
+func byline(doc *goquery.Document) string {
+	if b := metaContent(doc, `meta[name="author"]`, `meta[property="article:author"]`); b != "" && !strings.HasPrefix(b, "http") {
+		return b
+	}
+	for _, sel := range []string{`[itemprop="author"] [itemprop="name"]`, `[itemprop="author"]`, `[rel="author"]`, `.byline`, `.author`} {
+		if b := normalizeSpace(doc.Find(sel).First().Text()); b != "" && len(b) < 100 {
+			return b
+		}
+	}
+	return ""
+}
+
+// 발행일 해석에 시도할 형식
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006.01.02 15:04",
+	"2006.01.02",
+}
+
+func published(doc *goquery.Document) string {
+	v := metaContent(doc,
+		`meta[property="article:published_time"]`,
+		`meta[name="article:published_time"]`,
+		`meta[itemprop="datePublished"]`,
+		`meta[name="pubdate"]`,
+		`meta[name="publishdate"]`,
+		`meta[name="date"]`,
+		`meta[name="DC.date.issued"]`,
+	)
+	if v == "" {
+		v = doc.Find(`[itemprop="datePublished"]`).First().AttrOr("datetime", "")
+	}
+	if v == "" {
+		v = doc.Find("time[datetime]").First().AttrOr("datetime", "")
+	}
+	return normalizeDate(v)
+}
+
+// normalizeDate는 해석 가능한 날짜를 RFC3339로 바꾸고, 해석할 수 없으면 원문을 그대로 반환합니다.
+func normalizeDate(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return v
+}
+
+func image(doc *goquery.Document) string {
+	if img := metaContent(doc, `meta[property="og:image"]`, `meta[name="twitter:image"]`, `meta[name="twitter:image:src"]`); img != "" {
+		return img
+	}
+	return doc.Find(`link[rel="image_src"]`).First().AttrOr("href", "")
+}
+
+// resolve는 ref를 base 기준의 절대 URL로 바꿉니다.
+func resolve(base *url.URL, ref string) string {
+	if ref == "" || base == nil {
+		return ref
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
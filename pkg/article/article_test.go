@@ -0,0 +1,137 @@
+package article
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 본문 문단 (각 문단이 readability 점수 기준인 25자를 넘음)
+var paragraphs = []string{
+	"서울시는 내년부터 시내버스와 지하철 기본요금을 각각 300원씩 인상하기로 했다고 1일 밝혔다.",
+	"시는 연료비와 인건비가 오르면서 대중교통 운영 적자가 해마다 커지고 있어, 요금 조정이 불가피하다고 설명했다.",
+	"시민단체는 서민의 교통비 부담이 커진다며 반발하고 있으며, 시의회는 다음 달 공청회를 열어 의견을 듣기로 했다.",
+	"한편 시는 청소년과 노인에 대한 할인 폭을 넓히고, 정기권 가격은 동결하는 방안을 함께 검토하고 있다.",
+}
+
+func newsPage(body string) string {
+	return `<!DOCTYPE html><html lang="ko"><head>
+<title>서울 버스·지하철 요금 300원 인상 | 예시일보</title>
+<meta property="og:image" content="/img/bus.jpg">
+<meta name="author" content="홍길동 기자">
+<meta property="article:published_time" content="2025-04-01T09:30:00+09:00">
+</head><body>
+<header><nav><a href="/">홈</a> <a href="/politics">정치</a> <a href="/economy">경제</a></nav></header>
+<div class="sidebar"><h3>많이 본 뉴스</h3><ul><li><a href="/1">다른 기사 제목이 여기에 길게 들어갑니다 첫 번째</a></li><li><a href="/2">다른 기사 제목이 여기에 길게 들어갑니다 두 번째</a></li></ul></div>
+` + body + `
+<div id="comments"><p>댓글: 요금이 너무 많이 오르는 것 같습니다, 정말 부담됩니다.</p></div>
+<footer><p>Copyright 예시일보. 무단 전재 및 재배포 금지. 주소: 서울시 중구 세종대로 1</p></footer>
+</body></html>`
+}
+
+func articleBody(extra string) string {
+	var b strings.Builder
+	b.WriteString(`<div class="article-body" id="content">`)
+	for _, p := range paragraphs {
+		b.WriteString("<p>" + p + "</p>\n")
+	}
+	b.WriteString(extra)
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+func TestExtract(t *testing.T) {
+	related := `<div class="related"><h4>관련 기사</h4><ul><li><a href="/3">관련 기사 제목 하나, 길게 쓴 제목입니다</a></li><li><a href="/4">관련 기사 제목 둘, 길게 쓴 제목입니다</a></li></ul></div>`
+	share := `<div class="share-buttons"><a href="#">페이스북 공유</a> <a href="#">트위터 공유</a></div>`
+	script := `<script>var tracking = "광고 추적 스크립트 내용, 본문에 들어가면 안 됨";</script>`
+
+	tests := []struct {
+		name    string
+		html    string
+		exclude []string // 본문에 들어가면 안 되는 문자열
+	}{
+		{"기사 div", newsPage(articleBody("")), nil},
+		{"관련 기사·공유·스크립트", newsPage(articleBody(related + share + script)), []string{"관련 기사", "공유", "추적"}},
+		{"article 태그", newsPage(`<article>` + articleBody("") + `</article>`), nil},
+		{"형제 문단으로 나뉜 본문", newsPage(`<div><div class="story">` + "<p>" + strings.Join(paragraphs[:2], "</p><p>") + `</p></div><p>` +
+			strings.Join(paragraphs[2:], "</p><p>") + `</p></div>`), nil},
+		{"br로 나뉜 문단", newsPage(`<div class="post">` + strings.Join(paragraphs, "<br><br>") + `</div>`), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Extract([]byte(tt.html), "https://news.example.com/economy/2025/04/01/bus")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Split(a.Text, "\n\n"); strings.Join(got, "|") != strings.Join(paragraphs, "|") {
+				t.Errorf("Text 문단 %d개:\n%s", len(got), a.Text)
+			}
+			for _, s := range append(tt.exclude, "많이 본 뉴스", "댓글", "Copyright", "홈") {
+				if strings.Contains(a.Text, s) || strings.Contains(a.HTML, s) {
+					t.Errorf("본문에 %q가 있습니다:\n%s", s, a.HTML)
+				}
+			}
+			if a.Title != "서울 버스·지하철 요금 300원 인상" || a.Byline != "홍길동 기자" ||
+				a.Published != "2025-04-01T09:30:00+09:00" || a.Image != "https://news.example.com/img/bus.jpg" {
+				t.Errorf("Article = %+v", a)
+			}
+		})
+	}
+}
+
+func TestExtractNoContent(t *testing.T) {
+	for name, html := range map[string]string{
+		"빈 문서":   "",
+		"짧은 문단만": `<html><body><p>짧은 글</p><p>또 짧은 글</p></body></html>`,
+		"메뉴만": `<html><body><nav><p>정치 경제 사회 문화 국제 스포츠 연예 오피니언 전체 메뉴</p></nav>
+<div class="sidebar"><p>많이 본 뉴스 목록이 여기에 들어가는데 충분히 길게 씁니다.</p></div></body></html>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if a, err := Extract([]byte(html), ""); !errors.Is(err, ErrNoContent) {
+				t.Errorf("Extract = %+v, %v", a, err)
+			}
+		})
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{`<title>긴 기사 제목 세 단어 | 사이트</title>`, "긴 기사 제목 세 단어"},
+		{`<title>짧은 제목 - 사이트</title>`, "짧은 제목 - 사이트"},
+		{`<title>A - B - 세 단어 제목 - 사이트</title>`, "A - B - 세 단어 제목"},
+		{`<meta property="og:title" content="OG 제목"><title>문서 제목 여기 | 사이트</title>`, "OG 제목"},
+		{`<title> </title>`, "본문 제목"},
+	}
+	for _, tt := range tests {
+		a, err := Extract([]byte(`<html><head>`+tt.head+`</head><body><h1>본문 제목</h1><div>`+articleBody("")+`</div></body></html>`), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Title != tt.want {
+			t.Errorf("%s: Title = %q, want %q", tt.head, a.Title, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"2025-04-01T09:30:00+09:00", "2025-04-01T09:30:00+09:00"},
+		{"2025-04-01T09:30:00+0900", "2025-04-01T09:30:00+09:00"},
+		{"2025-04-01T09:30:00", "2025-04-01T09:30:00Z"},
+		{"2025-04-01 09:30:00", "2025-04-01T09:30:00Z"},
+		{"2025-04-01", "2025-04-01T00:00:00Z"},
+		{"Tue, 01 Apr 2025 09:30:00 +0900", "2025-04-01T09:30:00+09:00"},
+		{"2025.04.01 09:30", "2025-04-01T09:30:00Z"},
+		{" 2025.04.01 ", "2025-04-01T00:00:00Z"},
+		{"4월 1일", "4월 1일"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeDate(tt.in); got != tt.want {
+			t.Errorf("normalizeDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package article
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata는 정제 전 HTML 문서에서 모은 구조화된 메타데이터입니다.
+// 정규화된 필드는 JSON-LD, OpenGraph, Twitter 카드, 일반 meta 태그 순으로 먼저 찾은 값을 씁니다.
+type Metadata struct {
+	Lang        string            `json:"lang,omitempty"`      // <html lang>
+	Canonical   string            `json:"canonical,omitempty"` // <link rel=canonical> 절대 URL
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	SiteName    string            `json:"site_name,omitempty"`
+	Type        string            `json:"type,omitempty"` // JSON-LD @type 또는 og:type
+	Image       string            `json:"image,omitempty"`
+	Published   string            `json:"published,omitempty"` // 해석 가능하면 RFC3339, 아니면 원문 그대로
+	Modified    string            `json:"modified,omitempty"`
+	Authors     []string          `json:"authors,omitempty"`
+	Section     string            `json:"section,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	OpenGraph   map[string]string `json:"og,omitempty"`      // og:*, article:* 이름 → content
+	Twitter     map[string]string `json:"twitter,omitempty"` // twitter:* 이름 → content
+	JSONLD      *NewsArticle      `json:"jsonld,omitempty"`
+}
+
+// NewsArticle은 JSON-LD의 NewsArticle(및 Article 계열) 스키마에서 사용하는 필드입니다.
+type NewsArticle struct {
+	Type          string   `json:"type"`
+	Headline      string   `json:"headline,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Authors       []string `json:"authors,omitempty"`
+	Publisher     string   `json:"publisher,omitempty"`
+	Section       string   `json:"section,omitempty"`
+	Keywords      []string `json:"keywords,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	Language      string   `json:"language,omitempty"`
+}
+
+// ExtractMetadata는 doc에서 OpenGraph, Twitter 카드, JSON-LD NewsArticle, <html lang>,
+// canonical 링크, 발행일, 작성자, 섹션을 모읍니다. pageURL은 상대 URL 해석에 사용합니다.
+// 정제나 본문 추출은 meta와 script 태그를 지우므로 그 전에 호출해야 합니다.
+func ExtractMetadata(doc *goquery.Document, pageURL string) *Metadata {
+	base, _ := url.Parse(pageURL)
+	m := &Metadata{
+		Lang:      strings.TrimSpace(doc.Find("html").First().AttrOr("lang", "")),
+		Canonical: resolve(base, strings.TrimSpace(doc.Find(`link[rel="canonical"]`).First().AttrOr("href", ""))),
+		OpenGraph: metaPrefixed(doc, "property", "og:", "article:"),
+		Twitter:   metaPrefixed(doc, "name", "twitter:"),
+		JSONLD:    jsonLD(doc),
+	}
+
+	ld := m.JSONLD
+	if ld == nil {
+		ld = &NewsArticle{}
+	}
+	og, tw := m.OpenGraph, m.Twitter
+
+	m.Title = first(ld.Headline, title(doc))
+	m.Description = first(ld.Description, og["og:description"], tw["twitter:description"],
+		metaContent(doc, `meta[name="description"]`))
+	m.SiteName = first(og["og:site_name"], ld.Publisher, tw["twitter:site"])
+	m.Type = first(ld.Type, og["og:type"])
+	m.Image = resolve(base, first(ld.Image, image(doc)))
+	m.Published = first(normalizeDate(ld.DatePublished), published(doc))
+	m.Modified = normalizeDate(first(ld.DateModified, og["article:modified_time"],
+		metaContent(doc, `meta[itemprop="dateModified"]`)))
+	m.Section = first(ld.Section, og["article:section"], metaContent(doc, `meta[name="section"]`))
+
+	m.Authors = ld.Authors
+	if len(m.Authors) == 0 {
+		if b := byline(doc); b != "" {
+			m.Authors = []string{b}
+		}
+	}
+	m.Keywords = ld.Keywords
+	if len(m.Keywords) == 0 {
+		m.Keywords = splitKeywords(metaContent(doc, `meta[name="news_keywords"]`, `meta[name="keywords"]`))
+	}
+	if m.Lang == "" {
+		m.Lang = first(ld.Language, og["og:locale"], metaContent(doc, `meta[http-equiv="content-language"]`))
+	}
+	return m
+}
+
+// metaPrefixed는 attr 값이 prefixes 중 하나로 시작하는 meta 태그를 이름 → content로 모읍니다.
+// 같은 이름이 여러 번 나오면 첫 번째 값을 씁니다. 없으면 nil입니다.
+func metaPrefixed(doc *goquery.Document, attr string, prefixes ...string) map[string]string {
+	var values map[string]string
+	doc.Find("meta[" + attr + "]").Each(func(_ int, s *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(s.AttrOr(attr, "")))
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if content == "" {
+			return
+		}
+		for _, p := range prefixes {
+			if !strings.HasPrefix(name, p) {
+				continue
+			}
+			if values == nil {
+				values = map[string]string{}
+			}
+			if _, ok := values[name]; !ok {
+				values[name] = content
+			}
+			return
+		}
+	})
+	return values
+}
+
+// 기사로 보는 JSON-LD @type
+var articleTypes = map[string]bool{
+	"NewsArticle": true, "ReportageNewsArticle": true, "AnalysisNewsArticle": true,
+	"OpinionNewsArticle": true, "BackgroundNewsArticle": true, "ReviewNewsArticle": true,
+	"Article": true, "BlogPosting": true, "LiveBlogPosting": true, "Report": true,
+}
+
+// jsonLD는 application/ld+json 스크립트에서 첫 번째 기사 객체를 찾습니다.
+// 최상위 배열과 @graph 안의 객체도 살펴보며, NewsArticle 계열을 Article보다 우선합니다.
+func jsonLD(doc *goquery.Document) *NewsArticle {
+	var found map[string]any
+	var foundNews bool
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		if foundNews {
+			return
+		}
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &v); err != nil {
+			return
+		}
+		for _, obj := range ldObjects(v) {
+			typ := ldType(obj)
+			if !articleTypes[typ] {
+				continue
+			}
+			news := strings.HasSuffix(typ, "NewsArticle")
+			if found == nil || news && !foundNews {
+				found, foundNews = obj, news
+			}
+			if foundNews {
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil
+	}
+
+	return &NewsArticle{
+		Type:          ldType(found),
+		Headline:      normalizeSpace(ldString(found["headline"])),
+		Description:   normalizeSpace(ldString(found["description"])),
+		DatePublished: ldString(found["datePublished"]),
+		DateModified:  ldString(found["dateModified"]),
+		Authors:       ldStrings(found["author"]),
+		Publisher:     ldString(found["publisher"]),
+		Section:       ldString(found["articleSection"]),
+		Keywords:      ldKeywords(found["keywords"]),
+		Image:         ldImage(found["image"]),
+		URL:           ldString(found["url"]),
+		Language:      ldString(found["inLanguage"]),
+	}
+}
+
+// ldObjects는 JSON-LD 값에서 객체들을 펼쳐 반환합니다.
+func ldObjects(v any) []map[string]any {
+	var objs []map[string]any
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			objs = append(objs, ldObjects(item)...)
+		}
+	case map[string]any:
+		objs = append(objs, v)
+		if graph, ok := v["@graph"]; ok {
+			objs = append(objs, ldObjects(graph)...)
+		}
+	}
+	return objs
+}
+
+// ldType은 @type 값(문자열 또는 배열)에서 기사 유형을 우선하여 하나를 고릅니다.
+func ldType(obj map[string]any) string {
+	switch t := obj["@type"].(type) {
+	case string:
+		return t
+	case []any:
+		var typ string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				if articleTypes[s] {
+					return s
+				}
+				if typ == "" {
+					typ = s
+				}
+			}
+		}
+		return typ
+	}
+	return ""
+}
+
+// ldString은 문자열, 숫자, name/url/@id를 가진 객체, 배열(첫 값)을 문자열로 바꿉니다.
+func ldString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		for _, key := range []string{"name", "url", "@id"} {
+			if s := ldString(v[key]); s != "" {
+				return s
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if s := ldString(item); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// ldImage는 image 값(URL 문자열, ImageObject, 배열)에서 첫 번째 이미지 URL을 꺼냅니다.
+func ldImage(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return first(ldString(v["url"]), ldString(v["contentUrl"]), ldString(v["@id"]))
+	case []any:
+		for _, item := range v {
+			if s := ldImage(item); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	return ldString(v)
+}
+
+// ldStrings는 단일 값이나 배열을 중복 없는 문자열 목록으로 바꿉니다.
+func ldStrings(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, item := range items {
+		if s := normalizeSpace(ldString(item)); s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ldKeywords는 쉼표로 구분된 문자열이나 배열 형태의 keywords를 목록으로 바꿉니다.
+func ldKeywords(v any) []string {
+	if s, ok := v.(string); ok {
+		return splitKeywords(s)
+	}
+	return ldStrings(v)
+}
+
+func splitKeywords(s string) []string {
+	var out []string
+	for _, k := range strings.Split(s, ",") {
+		if k = normalizeSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// first는 값이 있는 첫 번째 문자열을 반환합니다.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package article
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func metadata(t *testing.T, html string) *Metadata {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ExtractMetadata(doc, "https://news.example.com/economy/1")
+}
+
+func TestExtractMetadata(t *testing.T) {
+	full := `<html lang="ko-KR"><head>
+<title>문서 제목 여기 | 예시일보</title>
+<link rel="canonical" href="/economy/1?ref=canonical">
+<meta name="description" content="일반 설명">
+<meta property="og:title" content="OG 제목">
+<meta property="og:description" content="OG 설명">
+<meta property="og:site_name" content="예시일보">
+<meta property="og:type" content="article">
+<meta property="og:image" content="https://cdn.example.com/og.jpg">
+<meta property="og:image" content="https://cdn.example.com/og2.jpg">
+<meta property="article:section" content="경제">
+<meta property="article:modified_time" content="2025-04-02 10:00:00">
+<meta property="og:empty" content="">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:site" content="@example">
+<meta name="news_keywords" content="버스, 지하철 ,요금,,">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+  {"@type":"WebSite","name":"예시일보"},
+  {"@type":["NewsArticle","Thing"],"headline":"  JSON-LD   제목 ","datePublished":"2025-04-01T09:30:00+09:00",
+   "author":[{"@type":"Person","name":"홍길동"},{"name":"김철수"},{"name":"홍길동"}],
+   "publisher":{"@type":"Organization","name":"예시일보 주식회사"},
+   "image":[{"@type":"ImageObject","url":"/img/ld.jpg"}],"articleSection":["경제","교통"],
+   "keywords":["버스","요금"],"inLanguage":"ko"}]}</script>
+</head><body><p>본문</p></body></html>`
+
+	got := metadata(t, full)
+	want := &Metadata{
+		Lang:        "ko-KR",
+		Canonical:   "https://news.example.com/economy/1?ref=canonical",
+		Title:       "JSON-LD 제목",
+		Description: "OG 설명",
+		SiteName:    "예시일보",
+		Type:        "NewsArticle",
+		Image:       "https://news.example.com/img/ld.jpg",
+		Published:   "2025-04-01T09:30:00+09:00",
+		Modified:    "2025-04-02T10:00:00Z",
+		Authors:     []string{"홍길동", "김철수"},
+		Section:     "경제",
+		Keywords:    []string{"버스", "요금"},
+		OpenGraph: map[string]string{
+			"og:title": "OG 제목", "og:description": "OG 설명", "og:site_name": "예시일보", "og:type": "article",
+			"og:image": "https://cdn.example.com/og.jpg", "article:section": "경제", "article:modified_time": "2025-04-02 10:00:00",
+		},
+		Twitter: map[string]string{"twitter:card": "summary_large_image", "twitter:site": "@example"},
+		JSONLD: &NewsArticle{
+			Type: "NewsArticle", Headline: "JSON-LD 제목", DatePublished: "2025-04-01T09:30:00+09:00",
+			Authors: []string{"홍길동", "김철수"}, Publisher: "예시일보 주식회사", Section: "경제",
+			Keywords: []string{"버스", "요금"}, Image: "/img/ld.jpg", Language: "ko",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractMetadata =\n%+v\n%+v\nwant\n%+v\n%+v", got, got.JSONLD, want, want.JSONLD)
+	}
+}
+
+// JSON-LD가 없거나 일부 필드만 있으면 OpenGraph, Twitter, 일반 meta 순으로 채웁니다.
+func TestExtractMetadataFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		check func(m *Metadata) bool
+	}{
+		{"OG 제목과 이미지",
+			`<head><meta property="og:title" content="OG 제목"><meta property="og:image" content="/og.jpg"><title>문서 제목</title></head>`,
+			func(m *Metadata) bool {
+				return m.Title == "OG 제목" && m.Image == "https://news.example.com/og.jpg" && m.JSONLD == nil
+			}},
+		{"Twitter 설명과 이미지",
+			`<head><meta name="twitter:description" content="트위터 설명"><meta name="twitter:image" content="https://t.example.com/a.png"></head>`,
+			func(m *Metadata) bool {
+				return m.Description == "트위터 설명" && m.Image == "https://t.example.com/a.png" && m.SiteName == ""
+			}},
+		{"일반 meta",
+			`<head><meta name="description" content="설명"><meta name="keywords" content="가, 나"><meta name="author" content="기자"><meta name="pubdate" content="2025.04.01"></head>`,
+			func(m *Metadata) bool {
+				return m.Description == "설명" && reflect.DeepEqual(m.Keywords, []string{"가", "나"}) &&
+					reflect.DeepEqual(m.Authors, []string{"기자"}) && m.Published == "2025-04-01T00:00:00Z"
+			}},
+		{"URL인 author는 건너뛰고 본문 byline",
+			`<head><meta name="author" content="https://example.com/me"></head><body><span class="byline"> 김 기자 </span></body>`,
+			func(m *Metadata) bool { return reflect.DeepEqual(m.Authors, []string{"김 기자"}) }},
+		{"time 요소 발행일",
+			`<body><time datetime="2025-04-01T09:30:00Z">4월 1일</time></body>`,
+			func(m *Metadata) bool { return m.Published == "2025-04-01T09:30:00Z" }},
+		{"lang 대체",
+			`<head><meta property="og:locale" content="ko_KR"></head>`,
+			func(m *Metadata) bool { return m.Lang == "ko_KR" }},
+		{"Article보다 NewsArticle 우선",
+			`<script type="application/ld+json">{"@type":"Article","headline":"일반 글"}</script>
+<script type="application/ld+json">[{"@type":"BreadcrumbList"},{"@type":"NewsArticle","headline":"뉴스 기사","author":"기자 이름"}]</script>`,
+			func(m *Metadata) bool {
+				return m.Title == "뉴스 기사" && m.Type == "NewsArticle" && reflect.DeepEqual(m.Authors, []string{"기자 이름"})
+			}},
+		{"Article만 있음",
+			`<script type="application/ld+json">{"@type":"BlogPosting","headline":"블로그 글","keywords":"가,나"}</script>`,
+			func(m *Metadata) bool {
+				return m.Title == "블로그 글" && m.Type == "BlogPosting" && reflect.DeepEqual(m.Keywords, []string{"가", "나"})
+			}},
+		{"기사 아닌 JSON-LD와 잘못된 JSON",
+			`<script type="application/ld+json">{"@type":"Organization","name":"회사"}</script><script type="application/ld+json">{잘못된</script><title>문서 제목</title>`,
+			func(m *Metadata) bool { return m.JSONLD == nil && m.Title == "문서 제목" && m.Type == "" }},
+		{"빈 문서",
+			``,
+			func(m *Metadata) bool { return reflect.DeepEqual(m, &Metadata{}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m := metadata(t, tt.html); !tt.check(m) {
+				t.Errorf("ExtractMetadata = %+v (JSONLD %+v)", m, m.JSONLD)
+			}
+		})
+	}
+}
+
+// Extract는 본문 후보를 고르며 meta를 지우므로, 미리 추출한 메타데이터를 넘기면 그 값을 씁니다.
+func TestFromDocumentMetadata(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(newsPage(articleBody(""))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := ExtractMetadata(doc, "https://news.example.com/1")
+	a, err := FromDocument(doc, "https://news.example.com/1", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Byline != "홍길동 기자" || a.Published != m.Published || a.Image != "https://news.example.com/img/bus.jpg" {
+		t.Errorf("Article = %+v", a)
+	}
+	if doc.Find("meta").Length() != 0 {
+		t.Error("FromDocument가 doc의 meta를 지우지 않았습니다")
+	}
+}
@@ -0,0 +1,287 @@
+package article
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Readability(Mozilla)의 후보 판별 규칙을 단순화한 패턴
+var (
+	reUnlikely = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|share|subscribe|newsletter|recommend`)
+	reMaybe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	rePositive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	reNegative = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// 본문과 무관하여 점수 계산 전에 지우는 태그
+const junkTags = "script,style,noscript,iframe,form,nav,header,footer,aside,svg,button,input,select,textarea,link,meta,template,object,embed"
+
+// 본문에 포함되지 않는 ARIA 역할
+var unlikelyRoles = map[string]bool{
+	"menu": true, "menubar": true, "complementary": true, "navigation": true,
+	"alert": true, "alertdialog": true, "dialog": true,
+}
+
+// 문단 취급 여부를 판단할 때 하위에 있으면 문단이 아닌 블록 태그
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tbody": true, "td": true, "th": true, "thead": true,
+	"tr": true, "ul": true,
+}
+
+// readability는 문단 점수를 조상에게 나눠 주고 링크 밀도로 보정하여 본문 영역을 고른 뒤,
+// 점수가 충분한 형제 요소를 합쳐 정리한 본문 컨테이너를 반환합니다. 후보가 없으면 nil입니다.
+func readability(doc *goquery.Document) *goquery.Selection {
+	doc.Find(junkTags).Remove()
+	removeUnlikely(doc)
+
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+
+	doc.Find("p,pre,td,h2,h3,h4,h5,h6,section,div").Each(func(_ int, s *goquery.Selection) {
+		node := s.Nodes[0]
+		if node.Data == "div" || node.Data == "section" {
+			// 블록 하위 요소가 없는 div/section만 문단으로 취급
+			if hasBlockChild(node) {
+				return
+			}
+		}
+
+		t := normalizeSpace(s.Text())
+		if len(t) < 25 {
+			return
+		}
+
+		score := 1 + float64(countCommas(t)) + math.Min(float64(len(t))/100, 3)
+		level := 0
+		for anc := node.Parent; anc != nil && level < 5; anc = anc.Parent {
+			if anc.Type != html.ElementNode || anc.Data == "html" {
+				break
+			}
+			if _, ok := scores[anc]; !ok {
+				scores[anc] = initialScore(anc)
+				candidates = append(candidates, anc)
+			}
+			switch level {
+			case 0:
+				scores[anc] += score
+			case 1:
+				scores[anc] += score / 2
+			default:
+				scores[anc] += score / float64(level*3)
+			}
+			level++
+		}
+	})
+
+	var top *html.Node
+	var topScore float64
+	for _, c := range candidates {
+		scores[c] *= 1 - linkDensity(goquery.NewDocumentFromNode(c).Selection)
+		if top == nil || scores[c] > topScore {
+			top, topScore = c, scores[c]
+		}
+	}
+	if top == nil {
+		return nil
+	}
+
+	// 본문이 여러 형제 요소로 나뉜 경우를 위해 점수가 충분한 형제를 함께 포함
+	threshold := math.Max(10, topScore*0.2)
+	var parts []string
+	for sib := firstSibling(top); sib != nil; sib = sib.NextSibling {
+		if sib.Type != html.ElementNode {
+			continue
+		}
+		s := goquery.NewDocumentFromNode(sib).Selection
+		include := sib == top
+		if score, ok := scores[sib]; ok && score >= threshold {
+			include = true
+		} else if sib.Data == "p" {
+			t := normalizeSpace(s.Text())
+			ld := linkDensity(s)
+			include = len(t) > 80 && ld < 0.25 || len(t) > 0 && len(t) <= 80 && ld == 0 && strings.ContainsAny(t, ".。!?")
+		}
+		if include {
+			h, _ := goquery.OuterHtml(s)
+			parts = append(parts, h)
+		}
+	}
+
+	content, err := goquery.NewDocumentFromReader(strings.NewReader("<div>" + strings.Join(parts, "") + "</div>"))
+	if err != nil {
+		return nil
+	}
+	root := content.Find("body > div").First()
+	cleanConditionally(root)
+	return root
+}
+
+// removeUnlikely는 클래스, id, role로 보아 본문이 아닐 가능성이 큰 요소를 지웁니다.
+func removeUnlikely(doc *goquery.Document) {
+	doc.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		tag := goquery.NodeName(s)
+		if tag == "article" || tag == "main" || tag == "a" {
+			return
+		}
+		if unlikelyRoles[strings.ToLower(s.AttrOr("role", ""))] {
+			s.Remove()
+			return
+		}
+		match := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if reUnlikely.MatchString(match) && !reMaybe.MatchString(match) {
+			s.Remove()
+		}
+	})
+}
+
+// cleanConditionally는 본문 안에서 링크, 이미지, 목록 비중이 높은 블록(관련 기사, 태그 목록 등)을 지웁니다.
+func cleanConditionally(root *goquery.Selection) {
+	root.Find("div,section,ul,ol,table").Each(func(_ int, s *goquery.Selection) {
+		node := s.Nodes[0]
+		weight := classWeight(node)
+		if weight < 0 {
+			s.Remove()
+			return
+		}
+
+		t := normalizeSpace(s.Text())
+		if countCommas(t) >= 10 {
+			return
+		}
+
+		p := s.Find("p").Length()
+		img := s.Find("img").Length()
+		li := s.Find("li").Length() - 100
+		ld := linkDensity(s)
+		isList := node.Data == "ul" || node.Data == "ol"
+
+		switch {
+		case img > 1 && float64(p)/float64(img) < 0.5:
+		case !isList && li > p:
+		case weight < 25 && ld > 0.2 && !isList:
+		case ld > 0.5:
+		case isList && ld > 0.3 && len(t) < 200:
+		case len(t) < 25 && img == 0 && !isList:
+		default:
+			return
+		}
+		s.Remove()
+	})
+}
+
+// initialScore는 태그 종류와 클래스/id로 후보의 초기 점수를 정합니다.
+func initialScore(n *html.Node) float64 {
+	score := float64(classWeight(n))
+	switch n.Data {
+	case "div", "article", "main":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	}
+	return score
+}
+
+// classWeight는 클래스와 id가 본문다운지(+25) 부수적인지(-25) 평가합니다.
+func classWeight(n *html.Node) int {
+	var weight int
+	for _, attr := range n.Attr {
+		if attr.Key != "class" && attr.Key != "id" || attr.Val == "" {
+			continue
+		}
+		if reNegative.MatchString(attr.Val) {
+			weight -= 25
+		}
+		if rePositive.MatchString(attr.Val) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity는 s의 텍스트 중 링크 텍스트가 차지하는 비율입니다.
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(normalizeSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	var links int
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += len(normalizeSpace(a.Text()))
+	})
+	return float64(links) / float64(total)
+}
+
+func hasBlockChild(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[c.Data] {
+			return true
+		}
+	}
+	return false
+}
+
+func firstSibling(n *html.Node) *html.Node {
+	if n.Parent == nil {
+		return n
+	}
+	return n.Parent.FirstChild
+}
+
+func countCommas(s string) int {
+	return strings.Count(s, ",") + strings.Count(s, "，") + strings.Count(s, "、") + strings.Count(s, "،")
+}
+
+// text는 본문 컨테이너의 텍스트를 블록 요소 단위 문단으로 나눠 빈 줄로 이어 붙입니다.
+func text(s *goquery.Selection) string {
+	var paragraphs []string
+	var cur strings.Builder
+
+	flush := func() {
+		if t := normalizeSpace(cur.String()); t != "" {
+			paragraphs = append(paragraphs, t)
+		}
+		cur.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			cur.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.Data == "br" {
+				flush()
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockTags[n.Data]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			flush()
+		}
+	}
+	for _, n := range s.Nodes {
+		walk(n)
+	}
+	flush()
+	return strings.Join(paragraphs, "\n\n")
+}
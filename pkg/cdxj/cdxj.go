@@ -0,0 +1,275 @@
+// Package cdxj는 SURT 정렬된 CDXJ 인덱스를 생성, 병합, 검색하는 기능을 제공합니다.
+package cdxj
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimestampFormat은 CDXJ 타임스탬프 형식(14자리)입니다.
+const TimestampFormat = "20060102150405"
+
+// 한 줄 최대 길이 (긴 URL 대비)
+const maxLineSize = 1 << 20
+
+// Entry는 CDXJ 인덱스의 한 줄입니다.
+type Entry struct {
+	SURT      string          `json:"-"`
+	Timestamp string          `json:"-"`
+	URL       string          `json:"url"`
+	Offset    int64           `json:"offset,string"`
+	Length    int64           `json:"length,string"`
+	Filename  string          `json:"filename"`
+	Meta      json.RawMessage `json:"meta,omitempty"` // 레코드 메타데이터(언어, 발행일 등) JSON 객체
+}
+
+// NewEntry는 URL과 기록 시각으로 SURT 키와 타임스탬프를 채운 Entry를 생성합니다.
+func NewEntry(rawURL string, date time.Time, filename string, offset, length int64) Entry {
+	return Entry{
+		SURT:      SURT(rawURL),
+		Timestamp: date.UTC().Format(TimestampFormat),
+		URL:       rawURL,
+		Offset:    offset,
+		Length:    length,
+		Filename:  filename,
+	}
+}
+
+// String은 Entry를 CDXJ 한 줄(개행 제외)로 변환합니다.
+func (e Entry) String() string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(e)
+	return e.SURT + " " + e.Timestamp + " " + strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Parse는 CDXJ 한 줄을 Entry로 변환합니다.
+func Parse(line string) (Entry, error) {
+	parts := strings.SplitN(strings.TrimRight(line, "\r\n"), " ", 3)
+	if len(parts) != 3 {
+		return Entry{}, fmt.Errorf("cdxj: 잘못된 인덱스 라인: %q", line)
+	}
+
+	var e Entry
+	if err := json.Unmarshal([]byte(parts[2]), &e); err != nil {
+		return Entry{}, fmt.Errorf("cdxj: JSON 파싱 오류: %w", err)
+	}
+	e.SURT = parts[0]
+	e.Timestamp = parts[1]
+	return e, nil
+}
+
+// WriteFile은 entries를 정렬하여 path에 원자적으로(임시 파일 후 rename) 기록합니다.
+func WriteFile(path string, entries []Entry) error {
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.String()
+	}
+	sort.Strings(lines)
+
+	return writeAtomic(path, func(w *bufio.Writer) error {
+		for _, line := range lines {
+			if _, err := w.WriteString(line + "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Merge는 정렬된 여러 CDXJ 파일을 병합하여 하나의 정렬된 파일 dst를 만듭니다.
+// 전체를 메모리에 올리지 않고 k-way 병합으로 처리합니다.
+func Merge(dst string, srcs []string) error {
+	h := &lineHeap{}
+	for _, src := range srcs {
+		f, err := os.Open(src)
+		if err != nil {
+			h.close()
+			return err
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64<<10), maxLineSize)
+		it := &lineIter{f: f, sc: sc}
+		if it.next() {
+			*h = append(*h, it)
+		} else {
+			f.Close()
+			if err := sc.Err(); err != nil {
+				h.close()
+				return fmt.Errorf("cdxj: 읽기 오류(%s): %w", src, err)
+			}
+		}
+	}
+	heap.Init(h)
+	defer h.close()
+
+	return writeAtomic(dst, func(w *bufio.Writer) error {
+		for h.Len() > 0 {
+			it := (*h)[0]
+			if _, err := w.WriteString(it.line + "\n"); err != nil {
+				return err
+			}
+			if it.next() {
+				heap.Fix(h, 0)
+				continue
+			}
+			if err := it.sc.Err(); err != nil {
+				return fmt.Errorf("cdxj: 읽기 오류(%s): %w", it.f.Name(), err)
+			}
+			heap.Pop(h)
+			it.f.Close()
+		}
+		return nil
+	})
+}
+
+// Lookup은 정렬된 CDXJ 파일에서 SURT 키가 prefix로 시작하는 항목을 이진 탐색으로 찾습니다.
+// 정확한 URL 조회는 SURT(url)을, 도메인 조회는 "com,example)" 같은 접두어를 사용합니다.
+func Lookup(path, prefix string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+
+	// prefix보다 작지 않은 첫 줄의 위치를 이진 탐색
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := lineStart(f, size, mid)
+		if err != nil {
+			return nil, err
+		}
+		if start < size {
+			line, err := readLine(f, size, start)
+			if err != nil {
+				return nil, err
+			}
+			if line < prefix {
+				lo = mid + 1
+				continue
+			}
+		}
+		hi = mid
+	}
+
+	start, err := lineStart(f, size, lo)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	sc := bufio.NewScanner(io.NewSectionReader(f, start, size-start))
+	sc.Buffer(make([]byte, 64<<10), maxLineSize)
+	for sc.Scan() {
+		if !strings.HasPrefix(sc.Text(), prefix) {
+			break
+		}
+		e, err := Parse(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// lineStart는 pos 이후(포함) 처음 시작하는 줄의 위치를 반환합니다.
+func lineStart(f *os.File, size, pos int64) (int64, error) {
+	if pos == 0 {
+		return 0, nil
+	}
+	line, err := readLine(f, size, pos-1)
+	if err != nil {
+		return 0, err
+	}
+	return min(pos-1+int64(len(line))+1, size), nil
+}
+
+// readLine은 pos부터 개행 전까지의 문자열을 읽습니다.
+func readLine(f *os.File, size, pos int64) (string, error) {
+	br := bufio.NewReader(io.NewSectionReader(f, pos, size-pos))
+	line, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// writeAtomic은 임시 파일에 기록한 뒤 rename으로 교체합니다.
+func writeAtomic(path string, fn func(w *bufio.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	if err := fn(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type lineIter struct {
+	f    *os.File
+	sc   *bufio.Scanner
+	line string
+}
+
+func (it *lineIter) next() bool {
+	if !it.sc.Scan() {
+		return false
+	}
+	it.line = it.sc.Text()
+	return true
+}
+
+type lineHeap []*lineIter
+
+func (h lineHeap) Len() int           { return len(h) }
+func (h lineHeap) Less(i, j int) bool { return h[i].line < h[j].line }
+func (h lineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *lineHeap) Push(x any)        { *h = append(*h, x.(*lineIter)) }
+func (h *lineHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+func (h *lineHeap) close() {
+	for _, it := range *h {
+		it.f.Close()
+	}
+	*h = nil
+}
@@ -0,0 +1,179 @@
+package cdxj
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSURT(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.Example.com/a/b?z=1&a=2", "com,example)/a/b?a=2&z=1"},
+		{"http://example.com", "com,example)/"},
+		{"https://news.example.co.kr/Article/1", "kr,co,example,news)/article/1"},
+		{"https://example.com:443/", "com,example)/"},
+		{"http://example.com:80/", "com,example)/"},
+		{"https://example.com:8443/x", "com,example:8443)/x"},
+		{"http://example.com:443/", "com,example:443)/"},
+		{"https://example.com./", "com,example)/"},
+		{"https://wwwexample.com/", "com,wwwexample)/"},
+		{"https://example.com/a#frag", "com,example)/a"},
+		{"https://example.com/%EA%B8%B0%EC%82%AC", "com,example)/%ea%b8%b0%ec%82%ac"},
+		{"https://example.com/기사", "com,example)/%ea%b8%b0%ec%82%ac"},
+		{"  https://example.com/a  ", "com,example)/a"},
+		{"not a url", "not a url"},
+		{"/relative/Path", "/relative/path"},
+	}
+	for _, tt := range tests {
+		if got := SURT(tt.url); got != tt.want {
+			t.Errorf("SURT(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestEntryParse(t *testing.T) {
+	e := NewEntry("https://example.com/a?b=<c>", time.Date(2025, 4, 1, 9, 30, 0, 0, time.FixedZone("KST", 9*3600)), "x.wrc.gz", 12, 34)
+	e.Meta = []byte(`{"lang":"ko"}`)
+	line := e.String()
+	if want := `com,example)/a?b=<c> 20250401003000 {"url":"https://example.com/a?b=<c>","offset":"12","length":"34","filename":"x.wrc.gz","meta":{"lang":"ko"}}`; line != want {
+		t.Errorf("String = %s\nwant %s", line, want)
+	}
+	got, err := Parse(line + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != line {
+		t.Errorf("Parse(String()) = %s", got.String())
+	}
+	for _, bad := range []string{"", "com,example)/ 2025", `com,example)/ 2025 {"url":`} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q): 오류가 없습니다", bad)
+		}
+	}
+}
+
+// testIndex는 urls를 서로 다른 시각의 항목으로 기록한 정렬된 인덱스 파일을 만듭니다.
+func testIndex(t *testing.T, urls ...string) string {
+	t.Helper()
+	var entries []Entry
+	for i, u := range urls {
+		entries = append(entries, NewEntry(u, time.Date(2025, 4, 1, 0, 0, i, 0, time.UTC), "a.wrc.gz", int64(i*100), 100))
+	}
+	path := filepath.Join(t.TempDir(), "index.cdxj")
+	if err := WriteFile(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	// 길이가 제각각인 줄로 이진 탐색이 줄 중간에 떨어지게 함
+	path := testIndex(t,
+		"https://example.com/a",
+		"https://example.com/a",
+		"https://example.com/ab",
+		"https://example.com/a/b",
+		"https://example.com/a?x=1",
+		"https://example.com/"+strings.Repeat("long", 300),
+		"https://news.example.com/1",
+		"https://example.co/",
+		"https://example.community/",
+		"https://aaa.com/",
+		"https://zzz.org/",
+	)
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   int
+	}{
+		{"URL 여러 캡처", SURT("https://example.com/a") + " ", 2},
+		{"URL 접두어가 다른 URL", SURT("https://example.com/ab") + " ", 1},
+		{"쿼리 있는 URL", SURT("https://example.com/a?x=1") + " ", 1},
+		{"경로 접두어", "com,example)/a", 5},
+		{"호스트", "com,example)", 6},
+		{"하위 도메인 제외", "com,example,news)", 1},
+		{"닫는 괄호 없는 접두어는 하위 도메인 포함", "com,example", 7},
+		{"첫 항목", "com,aaa)", 1},
+		{"마지막 항목", "org,zzz)", 1},
+		{"모든 항목", "", 11},
+		{"첫 항목보다 앞", "aaa", 0},
+		{"마지막 항목보다 뒤", "zzz", 0},
+		{"사이에 없음", "com,example)/b", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Lookup(path, tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("항목 %d개, want %d: %v", len(entries), tt.want, entries)
+			}
+			for _, e := range entries {
+				if !strings.HasPrefix(e.SURT+" ", tt.prefix) {
+					t.Errorf("%s가 %q로 시작하지 않습니다", e.SURT, tt.prefix)
+				}
+			}
+		})
+	}
+
+	if _, err := Lookup(filepath.Join(t.TempDir(), "none.cdxj"), ""); !os.IsNotExist(err) {
+		t.Errorf("없는 파일: %v", err)
+	}
+}
+
+// 항목 수와 관계없이 이진 탐색이 같은 결과를 내야 합니다.
+func TestLookupSizes(t *testing.T) {
+	for n := 0; n <= 9; n++ {
+		var urls []string
+		for i := range n {
+			urls = append(urls, fmt.Sprintf("https://example.com/%d", i))
+		}
+		path := testIndex(t, urls...)
+		for i := range n {
+			entries, err := Lookup(path, SURT(urls[i])+" ")
+			if err != nil || len(entries) != 1 || entries[0].URL != urls[i] {
+				t.Errorf("%d개 중 %d번째: %v, %v", n, i, entries, err)
+			}
+		}
+		if entries, err := Lookup(path, "com,example)/"); err != nil || len(entries) != n {
+			t.Errorf("%d개 전체: %d개, %v", n, len(entries), err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	a := testIndex(t, "https://b.com/", "https://d.com/", "https://d.com/x")
+	b := testIndex(t, "https://a.com/", "https://c.com/", "https://e.com/")
+	empty := filepath.Join(dir, "empty.cdxj")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "index.cdxj")
+	if err := Merge(dst, []string{a, empty, b}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 6 || !sort.StringsAreSorted(lines) {
+		t.Errorf("병합 결과:\n%s", data)
+	}
+	if entries, err := Lookup(dst, "com,d)"); err != nil || len(entries) != 2 {
+		t.Errorf("병합 후 조회: %v, %v", entries, err)
+	}
+	if err := Merge(dst, []string{filepath.Join(dir, "none.cdxj")}); err == nil {
+		t.Error("없는 입력 파일에서 오류가 없습니다")
+	}
+}
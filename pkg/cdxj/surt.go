@@ -0,0 +1,45 @@
+package cdxj
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"parkjunwoo.com/crowl/pkg/urlnorm"
+)
+
+// SURT는 URL을 Sort-friendly URI Reordering Transform 형식으로 변환합니다.
+// 예: https://www.Example.com/a/b?z=1&a=2 → com,example)/a/b?a=2&z=1
+func SURT(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(rawURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	labels := strings.Split(host, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(labels, ","))
+	if port := u.Port(); port != "" && !urlnorm.IsDefaultPort(u.Scheme, port) {
+		b.WriteString(":" + port)
+	}
+	b.WriteString(")")
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(strings.ToLower(path))
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		sort.Strings(params)
+		b.WriteString("?" + strings.ToLower(strings.Join(params, "&")))
+	}
+	return b.String()
+}
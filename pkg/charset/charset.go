@@ -0,0 +1,202 @@
+// Package charset는 HTML 응답 본문의 문자 인코딩을 결정하고 UTF-8로 변환합니다.
+//
+// 인코딩은 BOM, HTTP Content-Type의 charset, 문서 앞부분의 <meta charset> 순으로 정하며,
+// 선언이 없거나 본문과 맞지 않으면 UTF-8 유효성과 바이트 분포로 추정합니다.
+// 추정은 CC-NEWS에 많은 EUC-KR, Shift_JIS, EUC-JP, GBK(GB2312), Big5와 windows-1252를 구분합니다.
+package charset
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	htmlcharset "golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// 인코딩을 정한 근거
+const (
+	SourceBOM     = "bom"
+	SourceHeader  = "header"  // HTTP Content-Type의 charset
+	SourceMeta    = "meta"    // <meta charset> 또는 <meta http-equiv="Content-Type">
+	SourceDetect  = "detect"  // 바이트 분포로 추정
+	SourceDefault = "default" // 추정 실패, windows-1252
+)
+
+// Result는 결정한 인코딩입니다. Charset은 WHATWG 인코딩 이름(utf-8, euc-kr, shift_jis 등)입니다.
+type Result struct {
+	Charset string
+	Source  string
+}
+
+// metaScanSize는 <meta> 선언을 찾을 문서 앞부분의 크기입니다.
+const metaScanSize = 4096
+
+var reMetaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_.:-]+)`)
+
+var boms = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// Detect는 body의 인코딩을 결정합니다. contentType은 HTTP Content-Type 헤더 값입니다.
+// 선언된 인코딩이 windows-1252(ISO-8859-1)인데 본문이 유효한 UTF-8이거나,
+// UTF-8로 선언되었는데 유효하지 않으면 선언을 무시하고 추정합니다.
+func Detect(body []byte, contentType string) Result {
+	for _, b := range boms {
+		if bytes.HasPrefix(body, b.bom) {
+			return Result{Charset: b.charset, Source: SourceBOM}
+		}
+	}
+
+	if name := declared(body, contentType, SourceHeader); name != "" {
+		return Result{Charset: name, Source: SourceHeader}
+	}
+	if name := declared(body, contentType, SourceMeta); name != "" {
+		return Result{Charset: name, Source: SourceMeta}
+	}
+	if utf8.Valid(body) {
+		return Result{Charset: "utf-8", Source: SourceDetect}
+	}
+	if name := guess(body); name != "" {
+		return Result{Charset: name, Source: SourceDetect}
+	}
+	return Result{Charset: "windows-1252", Source: SourceDefault}
+}
+
+// declared는 source(header 또는 meta)에 선언된 인코딩 이름을 본문과 맞는 경우에만 반환합니다.
+func declared(body []byte, contentType, source string) string {
+	var label string
+	if source == SourceHeader {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			label = params["charset"]
+		}
+	} else {
+		head := body[:min(len(body), metaScanSize)]
+		if m := reMetaCharset.FindSubmatch(head); m != nil {
+			label = string(m[1])
+		}
+	}
+	if label == "" {
+		return ""
+	}
+
+	e, name := htmlcharset.Lookup(strings.TrimSpace(label))
+	if e == nil {
+		return ""
+	}
+	switch name {
+	case "windows-1252":
+		if utf8.Valid(body) && !isASCII(body) {
+			return ""
+		}
+	case "utf-8":
+		if !utf8.Valid(body) {
+			return ""
+		}
+	}
+	return name
+}
+
+// ToUTF8은 body를 Detect로 정한 인코딩에서 UTF-8로 변환합니다. BOM은 제거합니다.
+// 변환할 수 없는 바이트는 U+FFFD로 바뀝니다.
+func ToUTF8(body []byte, contentType string) ([]byte, Result, error) {
+	res := Detect(body, contentType)
+	if res.Source == SourceBOM {
+		for _, b := range boms {
+			if b.charset == res.Charset {
+				body = body[len(b.bom):]
+				break
+			}
+		}
+	}
+	if res.Charset == "utf-8" {
+		return body, res, nil
+	}
+
+	e, _ := htmlcharset.Lookup(res.Charset)
+	out, err := decode(e, body)
+	return out, res, err
+}
+
+func decode(e encoding.Encoding, body []byte) ([]byte, error) {
+	return e.NewDecoder().Bytes(body)
+}
+
+func isASCII(p []byte) bool {
+	for _, b := range p {
+		if b >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// guess는 2바이트 문자의 첫 바이트(lead)와 둘째 바이트(trail) 분포로 CJK 인코딩을 추정합니다.
+// 단서가 부족하거나 추정한 인코딩으로 변환했을 때 오류가 많으면 빈 문자열을 반환합니다.
+//
+//   - Shift_JIS: 히라가나·가타카나 lead 0x82, 0x83
+//   - Big5: trail 0x40~0x7E가 흔함 (EUC 계열은 0xA1 이상)
+//   - EUC-JP: 히라가나·가타카나 lead 0xA4, 0xA5
+//   - EUC-KR: 현대 한국어는 한글 lead 0xB0~0xC8에 몰리고 한자 영역(0xCA 이상)은 드묾
+//   - GBK: 한자 lead가 0xB0~0xF7에 고루 퍼짐
+func guess(body []byte) string {
+	var pairs, adjacent, sjisKana, eucjpKana, lowTrail, highLead int
+	for i := 0; i < len(body)-1; i++ {
+		lead, trail := body[i], body[i+1]
+		if lead < 0x81 || trail < 0x40 {
+			continue
+		}
+		pairs++
+		if i+2 < len(body) && body[i+2] >= 0x81 {
+			adjacent++
+		}
+		switch {
+		case lead == 0x82 && trail >= 0x9F && trail <= 0xF1, lead == 0x83 && trail >= 0x40 && trail <= 0x96:
+			sjisKana++
+		case (lead == 0xA4 || lead == 0xA5) && trail >= 0xA1:
+			eucjpKana++
+		}
+		if trail < 0x7F {
+			lowTrail++
+		}
+		if lead >= 0xCA && trail >= 0xA1 {
+			highLead++
+		}
+		i++
+	}
+	if pairs < 4 {
+		return ""
+	}
+
+	ratio := func(n int) float64 { return float64(n) / float64(pairs) }
+	var name string
+	switch {
+	case ratio(sjisKana) > 0.15:
+		name = "shift_jis"
+	case ratio(adjacent) < 0.3:
+		// 2바이트 문자가 이어지지 않고 높은 바이트가 띄엄띄엄 쓰임: 서유럽 단일 바이트 문자
+		return ""
+	case ratio(lowTrail) > 0.2:
+		name = "big5"
+	case ratio(eucjpKana) > 0.15:
+		name = "euc-jp"
+	case ratio(highLead) < 0.1:
+		name = "euc-kr"
+	default:
+		name = "gbk"
+	}
+
+	e, _ := htmlcharset.Lookup(name)
+	out, err := decode(e, body)
+	if err != nil || bytes.Count(out, []byte("\uFFFD")) > pairs/50 {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package charset
+
+import (
+	"bytes"
+	"testing"
+
+	htmlcharset "golang.org/x/net/html/charset"
+)
+
+// 인코딩별 본문 표본 (UTF-8 원문을 테스트에서 각 인코딩으로 변환)
+var samples = []struct {
+	charset string
+	text    string
+}{
+	{"euc-kr", `<html><head><title>뉴스</title></head><body><p>서울시는 내년부터 시내버스와 지하철 요금을 인상하기로 했다고 밝혔다.
+시민단체는 서민의 교통비 부담이 커진다며 반발하고 있으며, 시의회는 다음 달 공청회를 열 예정이다.</p></body></html>`},
+	{"shift_jis", `<html><head><title>ニュース</title></head><body><p>東京都は来年度から都営バスと地下鉄の運賃を値上げすると発表した。
+利用者からは反対の声が上がっており、都議会は来月に公聴会を開く予定だ。</p></body></html>`},
+	{"euc-jp", `<html><head><title>ニュース</title></head><body><p>東京都は来年度から都営バスと地下鉄の運賃を値上げすると発表した。
+利用者からは反対の声が上がっており、都議会は来月に公聴会を開く予定だ。</p></body></html>`},
+	{"gbk", `<html><head><title>新闻</title></head><body><p>北京市政府宣布，明年起将提高公交车和地铁票价。
+许多市民对此表示不满，认为会增加生活负担，市人大将于下月召开听证会。</p></body></html>`},
+	{"big5", `<html><head><title>新聞</title></head><body><p>臺北市政府宣布，明年起將調漲公車與捷運票價。
+許多市民對此表示不滿，認為會增加生活負擔，市議會將於下個月舉行公聽會。</p></body></html>`},
+	{"windows-1252", `<html><head><title>Actualité</title></head><body><p>Le conseil municipal a voté mardi l'extension du réseau de pistes cyclables, malgré l'opposition des commerçants.
+Die Stadtverwaltung hat beschlossen, das Radwegenetz auszubauen, obwohl Geschäftsleute dagegen protestiert haben.</p></body></html>`},
+}
+
+func encode(t *testing.T, charset, text string) []byte {
+	t.Helper()
+	e, name := htmlcharset.Lookup(charset)
+	if e == nil || name != charset {
+		t.Fatalf("인코딩 %s 없음 (%s)", charset, name)
+	}
+	b, err := e.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("%s 변환 오류: %v", charset, err)
+	}
+	return b
+}
+
+// 선언이 없으면 바이트 분포로 추정하고, 변환 결과가 원문과 같아야 합니다.
+func TestDetectUndeclared(t *testing.T) {
+	for _, s := range samples {
+		t.Run(s.charset, func(t *testing.T) {
+			body := encode(t, s.charset, s.text)
+			want := Result{Charset: s.charset, Source: SourceDetect}
+			if s.charset == "windows-1252" {
+				want.Source = SourceDefault
+			}
+			if got := Detect(body, "text/html"); got != want {
+				t.Errorf("Detect = %+v, want %+v", got, want)
+			}
+
+			out, _, err := ToUTF8(body, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != s.text {
+				t.Errorf("ToUTF8 = %q", out)
+			}
+		})
+	}
+
+	if got := Detect([]byte(samples[0].text), ""); got != (Result{"utf-8", SourceDetect}) {
+		t.Errorf("UTF-8: Detect = %+v", got)
+	}
+}
+
+// 올바른 선언은 header, meta 순으로 그대로 씁니다.
+func TestDetectDeclared(t *testing.T) {
+	for _, s := range samples {
+		t.Run(s.charset, func(t *testing.T) {
+			body := encode(t, s.charset, s.text)
+			if got := Detect(body, "text/html; charset="+s.charset); got != (Result{s.charset, SourceHeader}) {
+				t.Errorf("header: Detect = %+v", got)
+			}
+			meta := append(encode(t, s.charset, `<meta charset="`+s.charset+`">`), body...)
+			if got := Detect(meta, "text/html"); got != (Result{s.charset, SourceMeta}) {
+				t.Errorf("meta: Detect = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDetectOverride(t *testing.T) {
+	eucKR := encode(t, "euc-kr", samples[0].text)
+	sjis := encode(t, "shift_jis", samples[1].text)
+	latin := encode(t, "windows-1252", samples[5].text)
+	utf := []byte(samples[0].text)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        Result
+	}{
+		// 문서에 적힌 두 경우에만 선언을 무시
+		{"latin1 선언, UTF-8 본문", utf, "text/html; charset=iso-8859-1", Result{"utf-8", SourceDetect}},
+		{"windows-1252 선언, UTF-8 본문", utf, "text/html; charset=windows-1252", Result{"utf-8", SourceDetect}},
+		{"utf-8 선언, EUC-KR 본문", eucKR, "text/html; charset=utf-8", Result{"euc-kr", SourceDetect}},
+		{"utf-8 선언, Shift_JIS 본문", sjis, "text/html; charset=UTF-8", Result{"shift_jis", SourceDetect}},
+		{"utf-8 meta, EUC-KR 본문", append([]byte(`<meta charset="utf-8">`), eucKR...), "", Result{"euc-kr", SourceDetect}},
+		{"utf-8 선언, latin1 본문", latin, "text/html; charset=utf-8", Result{"windows-1252", SourceDefault}},
+
+		// 그 밖의 불일치는 선언을 따름
+		{"euc-kr 선언, Shift_JIS 본문", sjis, "text/html; charset=euc-kr", Result{"euc-kr", SourceHeader}},
+		{"latin1 선언, EUC-KR 본문", eucKR, "text/html; charset=iso-8859-1", Result{"windows-1252", SourceHeader}},
+		{"latin1 선언, ASCII 본문", []byte("<p>plain ascii</p>"), "text/html; charset=iso-8859-1", Result{"windows-1252", SourceHeader}},
+		{"header가 meta보다 우선", append([]byte(`<meta charset="shift_jis">`), eucKR...), "text/html; charset=euc-kr", Result{"euc-kr", SourceHeader}},
+
+		// 알 수 없는 이름은 선언이 없는 것으로 봄
+		{"알 수 없는 header", eucKR, "text/html; charset=x-unknown", Result{"euc-kr", SourceDetect}},
+		{"알 수 없는 meta", append([]byte(`<meta charset="bogus">`), sjis...), "", Result{"shift_jis", SourceDetect}},
+		{"http-equiv meta", append([]byte(`<meta http-equiv="Content-Type" content="text/html; charset=EUC-KR">`), eucKR...), "", Result{"euc-kr", SourceMeta}},
+
+		// BOM은 모든 선언보다 우선
+		{"BOM", append([]byte{0xEF, 0xBB, 0xBF}, utf...), "text/html; charset=euc-kr", Result{"utf-8", SourceBOM}},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, '<', 0}, "text/html; charset=utf-8", Result{"utf-16le", SourceBOM}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.body, tt.contentType); got != tt.want {
+				t.Errorf("Detect = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMetaScanSize(t *testing.T) {
+	body := encode(t, "euc-kr", samples[0].text)
+	late := append(bytes.Repeat([]byte(" "), metaScanSize), `<meta charset="shift_jis">`...)
+	if got := Detect(append(body, late...), ""); got != (Result{"euc-kr", SourceDetect}) {
+		t.Errorf("앞부분 밖의 meta: Detect = %+v", got)
+	}
+}
+
+func TestToUTF8BOM(t *testing.T) {
+	out, res, err := ToUTF8([]byte("\xEF\xBB\xBF<p>본문</p>"), "")
+	if err != nil || string(out) != "<p>본문</p>" || res != (Result{"utf-8", SourceBOM}) {
+		t.Errorf("ToUTF8 = %q, %+v, %v", out, res, err)
+	}
+	out, res, err = ToUTF8([]byte{0xFF, 0xFE, '<', 0, 'p', 0, '>', 0}, "")
+	if err != nil || string(out) != "<p>" || res.Charset != "utf-16le" {
+		t.Errorf("ToUTF8(utf-16le) = %q, %+v, %v", out, res, err)
+	}
+}
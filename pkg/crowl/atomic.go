@@ -0,0 +1,143 @@
+package crowl
+
+import (
+	"os"
+)
+
+// atomicFile은 path.part 임시 파일에 기록한 뒤 commit 시 rename으로 path에 반영합니다.
+// 중단되거나 실패하면 abort로 임시 파일을 지워 불완전한 출력이 남지 않게 합니다.
+type atomicFile struct {
+	*os.File
+	path string
+	done bool
+}
+
+func createAtomic(path string) (*atomicFile, error) {
+	f, err := os.Create(path + ".part")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// resumeAtomic은 기존 path.part 임시 파일을 이어 쓰기 위해 열고 현재 크기를 반환합니다.
+// 임시 파일이 없으면 새로 만듭니다.
+func resumeAtomic(path string) (*atomicFile, int64, error) {
+	f, err := os.OpenFile(path+".part", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return &atomicFile{File: f, path: path}, fi.Size(), nil
+}
+
+// commit은 임시 파일을 디스크에 반영하고 최종 경로로 옮깁니다.
+func (a *atomicFile) commit() error {
+	if a.done {
+		return nil
+	}
+	a.done = true
+	if err := a.Sync(); err != nil {
+		a.Close()
+		os.Remove(a.Name())
+		return err
+	}
+	if err := a.Close(); err != nil {
+		os.Remove(a.Name())
+		return err
+	}
+	return os.Rename(a.Name(), a.path)
+}
+
+// suspend는 임시 파일을 지우지 않고 닫아 다음 실행에서 이어 쓸 수 있게 합니다.
+func (a *atomicFile) suspend() {
+	if a.done {
+		return
+	}
+	a.done = true
+	a.Close()
+}
+
+// abort는 commit되지 않은 임시 파일을 삭제합니다. commit 이후 호출하면 아무 일도 하지 않습니다.
+func (a *atomicFile) abort() {
+	if a.done {
+		return
+	}
+	a.done = true
+	a.Close()
+	os.Remove(a.Name())
+}
+
+// reopenFile은 기록할 때만 path.part 임시 파일을 열고 release로 닫는 atomicFile입니다.
+// 오래 열어 둘 필요 없는 파일이 많을 때(parquet 파티션) 열린 파일 수를 제한합니다.
+type reopenFile struct {
+	f       *os.File
+	path    string
+	created bool
+	done    bool
+}
+
+// Write는 임시 파일이 닫혀 있으면 이어 쓰기로 다시 열어 기록합니다. 처음 열 때는 기존 임시 파일을 비웁니다.
+func (r *reopenFile) Write(p []byte) (int, error) {
+	if r.f == nil {
+		flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		if !r.created {
+			flag |= os.O_TRUNC
+		}
+		f, err := os.OpenFile(r.path+".part", flag, 0644)
+		if err != nil {
+			return 0, err
+		}
+		r.f, r.created = f, true
+	}
+	return r.f.Write(p)
+}
+
+// release는 열려 있는 임시 파일을 닫습니다.
+func (r *reopenFile) release() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// commit은 임시 파일을 디스크에 반영하고 최종 경로로 옮깁니다.
+func (r *reopenFile) commit() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	if err := r.release(); err != nil {
+		os.Remove(r.path + ".part")
+		return err
+	}
+	f, err := os.OpenFile(r.path+".part", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), r.path)
+}
+
+// abort는 commit되지 않은 임시 파일을 삭제합니다. commit 이후 호출하면 아무 일도 하지 않습니다.
+func (r *reopenFile) abort() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.release()
+	os.Remove(r.path + ".part")
+}
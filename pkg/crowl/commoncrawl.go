@@ -1,29 +1,41 @@
 package crowl
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"gopkg.in/yaml.v3"
+	"parkjunwoo.com/crowl/pkg/article"
+	"parkjunwoo.com/crowl/pkg/cdxj"
+	"parkjunwoo.com/crowl/pkg/charset"
+	"parkjunwoo.com/crowl/pkg/dedup"
+	"parkjunwoo.com/crowl/pkg/urlnorm"
+	"parkjunwoo.com/crowl/pkg/warc"
 )
 
 type CommonCrawl struct {
 	Workers         int    `yaml:"workers"`
 	Predowns        int    `yaml:"predowns"`
 	BaseURL         string `yaml:"base_url"`
+	IndexURL        string `yaml:"index_url"`
+	Mode            string `yaml:"mode"`      // warc(HTML 응답), wet(추출 텍스트), wat(JSON 메타데이터)
+	Extractor       string `yaml:"extractor"` // warc 모드 본문 추출 방식: clean(CleanHTML), readability(기사 본문 JSON)
+	Metadata        bool   `yaml:"metadata"`  // warc 모드에서 정제 전에 OpenGraph·JSON-LD 등 메타데이터를 추출하여 인덱스와 WARC 출력에 기록
 	TempDir         string `yaml:"temp_dir"`
 	DataDir         string `yaml:"data_dir"`
 	RemoveSelectors struct {
@@ -32,26 +44,73 @@ type CommonCrawl struct {
 		ClassKeywords []string `yaml:"class_keywords"`
 		Attributes    []string `yaml:"attributes"`
 	} `yaml:"remove_selectors"`
+	WarcOutput struct {
+		Enabled bool `yaml:"enabled"` // 정제에 성공한 응답 레코드를 .warc.gz로 함께 저장
+		Cleaned bool `yaml:"cleaned"` // 정제된 HTML을 conversion 레코드로 함께 기록
+	} `yaml:"warc_output"`
+	Filter   FilterConfig   `yaml:"filter"`   // warc 모드에서 처리할 HTTP 응답 조건 (상태 코드, 미디어 타입)
+	Language LanguageConfig `yaml:"language"` // 본문 텍스트의 언어 판별, 언어 필터와 언어별 출력
+	Dedup    DedupConfig    `yaml:"dedup"`    // 본문 텍스트의 완전·유사 중복 판정
+	URL      URLConfig      `yaml:"url"`      // URL 정규화(canonical 링크, SURT)와 같은 URL의 반복 캡처 거르기
+	Output   OutputConfig   `yaml:"output"`
+	HTTP     HTTPConfig     `yaml:"http"`
+	S3       S3Config       `yaml:"s3"`       // base_url이 s3://bucket/prefix/ 인 경우 사용
+	KeepRaw  bool           `yaml:"keep_raw"` // 파싱 후에도 다운로드한 원본 파일을 temp_dir에 보존
+	Stale    bool           `yaml:"-"`        // 설정이나 버전이 바뀐 완료 파일만 다시 처리
+
+	client   *retryClient
+	source   Source
+	states   map[string]*stateStore     // saveDir별 상태 저장소
+	dedups   map[string]*dedup.Store    // saveDir별 중복 판정 서명 저장소 (statesMu로 보호)
+	urls     map[string]*dedup.URLStore // saveDir별 URL 캡처 저장소 (statesMu로 보호)
+	statesMu sync.Mutex
+}
+
+// fileJob은 처리할 원격 파일 경로와 결과를 저장할 디렉토리입니다.
+type fileJob struct {
+	path    string
+	saveDir string
 }
 
 type warcTask struct {
 	warcLocalPath string
 	savePath      string
+	state         *stateStore
 }
 
+// warc 모드 본문 추출 방식
+const (
+	ExtractorClean       = "clean"       // remove_selectors 규칙으로 정제한 HTML
+	ExtractorReadability = "readability" // 텍스트/링크 밀도로 고른 기사 본문과 메타데이터(JSON)
+)
+
 // 작업 단위 구조체
 type parseJob struct {
 	URL     string
+	Header  warc.Header
 	Content []byte
 }
 
 var (
 	reComments = regexp.MustCompile(`<!--[\s\S]*?-->`)
 	reSpaces   = regexp.MustCompile(`\s+`)
-	reWarc     = regexp.MustCompile(`CC-NEWS-(\d{4})(\d{2})\d{8}-\d{5}\.warc\.gz`)
+	reWarc     = regexp.MustCompile(`CC-(?:NEWS|MAIN)-(\d{4})(\d{2})\d{8}-[\d-]+\.warc(?:\.wat|\.wet)?\.gz$`)
 )
 
+// NewCommonCrawl은 설정 파일을 읽고 Validate로 기본값을 채워 검증합니다.
 func NewCommonCrawl(path string) (*CommonCrawl, error) {
+	cc, err := LoadCommonCrawl(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.Validate(); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// LoadCommonCrawl은 설정 파일을 읽기만 합니다. 명령줄 옵션 등으로 값을 바꾼 뒤 Validate를 호출해야 쓸 수 있습니다.
+func LoadCommonCrawl(path string) (*CommonCrawl, error) {
 	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -61,90 +120,371 @@ func NewCommonCrawl(path string) (*CommonCrawl, error) {
 	if err := yaml.Unmarshal(file, &cfg); err != nil {
 		return nil, err
 	}
+	return &cfg, nil
+}
 
-	if cfg.Workers == 0 {
-		var workerNums int
-		workerNums, err = cpu.Counts(false) // 물리적 코어 수 반환 (logical=false)
+// Validate는 비어 있는 설정에 기본값을 채우고, 처리 모드에 따라 달라지는 설정까지 함께 검증합니다.
+// 여러 번 호출해도 결과가 같으므로 값을 바꿀 때마다 다시 호출할 수 있습니다.
+func (cc *CommonCrawl) Validate() error {
+	if cc.Workers == 0 {
+		workerNums, err := cpu.Counts(false) // 물리적 코어 수 반환 (logical=false)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("CPU 코어 수 확인 오류: %w", err)
 		}
-		cfg.Workers = workerNums
+		cc.Workers = workerNums
 	}
 
-	if cfg.Predowns == 0 {
-		cfg.Predowns = cfg.Workers / 4
+	if cc.Predowns == 0 {
+		cc.Predowns = max(cc.Workers/4, 1)
 	}
 
-	return &cfg, nil
+	if cc.BaseURL == "" {
+		cc.BaseURL = "https://data.commoncrawl.org/"
+	}
+
+	if cc.IndexURL == "" {
+		cc.IndexURL = "https://index.commoncrawl.org/"
+	}
+
+	cc.HTTP.setDefaults()
+	cc.client = newRetryClient(cc.HTTP)
+	if err := cc.SetBaseURL(cc.BaseURL); err != nil {
+		return err
+	}
+
+	switch cc.Mode {
+	case "":
+		cc.Mode = KindWarc
+	case KindWarc, KindWet, KindWat:
+	default:
+		return fmt.Errorf("알 수 없는 처리 모드: %s", cc.Mode)
+	}
+
+	switch cc.Extractor {
+	case "":
+		cc.Extractor = ExtractorClean
+	case ExtractorClean, ExtractorReadability:
+	default:
+		return fmt.Errorf("알 수 없는 본문 추출 방식: %s", cc.Extractor)
+	}
+
+	cc.Filter.setDefaults()
+	if err := cc.Language.setDefaults(cc.Mode); err != nil {
+		return err
+	}
+	if err := cc.Dedup.setDefaults(cc.Mode); err != nil {
+		return err
+	}
+	if err := cc.URL.setDefaults(cc.Mode); err != nil {
+		return err
+	}
+	return cc.Output.setDefaults()
+}
+
+// SetBaseURL은 파일을 가져올 저장소를 바꿉니다.
+// https://는 HTTP(S) 서버, file:///경로/는 로컬 미러 디렉토리, s3://버킷/접두어/는 S3 호환 저장소입니다.
+func (cc *CommonCrawl) SetBaseURL(baseURL string) error {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	source, err := newSource(baseURL, cc.client, cc.S3)
+	if err != nil {
+		return err
+	}
+	cc.BaseURL = baseURL
+	cc.source = source
+	return nil
 }
 
 // GetNews는 지정한 연도(year)와 월(month)의 뉴스 데이터를 다운로드하고 파싱합니다.
-func (cc *CommonCrawl) GetNews(year int, month int) error {
-	paths, err := cc.getNewsWarcPaths(year, month)
+func (cc *CommonCrawl) GetNews(ctx context.Context, year int, month int) error {
+	return cc.Process(ctx, NewsCrawl(year, month))
+}
+
+// GetMain은 지정한 CC-MAIN 크롤(예: CC-MAIN-2025-13)의 데이터를 Mode에 따라 다운로드하고 파싱합니다.
+func (cc *CommonCrawl) GetMain(ctx context.Context, crawlID string) error {
+	crawl, err := MainCrawl(crawlID)
+	if err != nil {
+		return err
+	}
+	return cc.Process(ctx, crawl)
+}
+
+// Process는 크롤의 WARC/WET/WAT 파일(Mode)을 다운로드하고 파싱하여 crawl.SaveDir 아래에 저장합니다.
+// 이미 완료된 파일은 건너뛰므로 중단 후 다시 실행하면 이어서 처리합니다.
+func (cc *CommonCrawl) Process(ctx context.Context, crawl Crawl) error {
+	paths, err := cc.ListPaths(ctx, crawl, cc.Mode)
+	if err != nil {
+		return err
+	}
+
+	saveDir := crawl.SaveDir(cc.DataDir)
+	jobs := make([]fileJob, len(paths))
+	for i, path := range paths {
+		jobs[i] = fileJob{path: path, saveDir: saveDir}
+	}
+
+	if err := cc.run(ctx, jobs); err != nil && ctx.Err() != nil {
+		return err
+	} else if err != nil {
+		return errors.Join(err, cc.mergeIndex(saveDir))
+	}
+
+	// ✅ 인덱스 병합
+	return cc.mergeIndex(saveDir)
+}
+
+// GetNewsRange는 from 이상 to 미만 기간의 뉴스 데이터를 다운로드하고 파싱합니다.
+// 파일 이름의 타임스탬프(CC-NEWS-YYYYMMDDhhmmss-NNNNN)로 기간을 판단하므로 일/시 단위 지정도 가능하며,
+// 여러 달의 파일을 하나의 워커 풀에서 처리하고 결과는 각 달의 DataDir/YYYY/MM 아래에 저장합니다.
+func (cc *CommonCrawl) GetNewsRange(ctx context.Context, from, to time.Time) error {
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) {
+		return fmt.Errorf("잘못된 기간: %s ~ %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+
+	paths, err := cc.NewsRangePaths(ctx, from, to)
 	if err != nil {
 		return err
 	}
 
-	saveDir := filepath.Join(cc.DataDir, fmt.Sprintf("%04d", year), fmt.Sprintf("%02d", month))
-	if err := os.MkdirAll(saveDir, os.ModePerm); err != nil {
+	var jobs []fileJob
+	var saveDirs []string
+	for _, path := range paths {
+		t, _ := newsFileTime(path)
+		saveDir := NewsCrawl(t.Year(), int(t.Month())).SaveDir(cc.DataDir)
+		if len(saveDirs) == 0 || saveDirs[len(saveDirs)-1] != saveDir {
+			saveDirs = append(saveDirs, saveDir)
+		}
+		jobs = append(jobs, fileJob{path: path, saveDir: saveDir})
+	}
+
+	fmt.Printf("[기간] %s ~ %s: %d개 파일\n", from.Format(time.DateTime), to.Format(time.DateTime), len(jobs))
+
+	runErr := cc.run(ctx, jobs)
+	if ctx.Err() != nil {
+		return runErr
+	}
+
+	// ✅ 달마다 인덱스 병합
+	for _, saveDir := range saveDirs {
+		if err := cc.mergeIndex(saveDir); err != nil {
+			return errors.Join(runErr, err)
+		}
+	}
+	return runErr
+}
+
+// NewsRangePaths는 from 이상 to 미만 기간에 해당하는 CC-NEWS 파일 경로 목록을 반환합니다.
+func (cc *CommonCrawl) NewsRangePaths(ctx context.Context, from, to time.Time) ([]string, error) {
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) {
+		return nil, fmt.Errorf("잘못된 기간: %s ~ %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+
+	var paths []string
+	for _, crawl := range NewsCrawls(from, to) {
+		monthPaths, err := cc.ListPaths(ctx, crawl, cc.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%s 경로 목록 오류: %w", crawl.ID, err)
+		}
+
+		for _, path := range monthPaths {
+			t, ok := newsFileTime(path)
+			if !ok || t.Before(from) || !t.Before(to) {
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// Download는 paths의 파일을 파싱하지 않고 TempDir에 내려받기만 합니다.
+// 이미 정상적으로 받아둔 파일은 건너뜁니다.
+func (cc *CommonCrawl) Download(ctx context.Context, paths []string) error {
+	sem := make(chan struct{}, cc.Predowns)
+	errs := make([]error, len(paths))
+
+	var wg sync.WaitGroup
+schedule:
+	for i, path := range paths {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break schedule
+		}
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fmt.Printf("[다운로드 시작] %s\n", p)
+			localPath, err := cc.downloadedWarc(ctx, p)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p, err)
+				fmt.Printf("[다운로드 실패] %s: %v\n", p, err)
+				return
+			}
+			fmt.Printf("[다운로드 완료] %s\n", localPath)
+		}(i, path)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	return errors.Join(errs...)
+}
 
-	logFilePath := filepath.Join(saveDir, "completed")
+// ParseFile은 로컬 WARC/WET/WAT 파일 하나를 파싱하여 savePath(.wrc.gz, .jsonl.gz 등)에 저장합니다.
+// 처리 상태는 savePath와 같은 디렉토리의 상태 저장소에 기록됩니다.
+func (cc *CommonCrawl) ParseFile(ctx context.Context, warcPath, savePath string) error {
+	st, err := cc.state(filepath.Dir(savePath))
+	if err != nil {
+		return err
+	}
+	cc.mark(st, filepath.Base(savePath), func(fs *FileState) {
+		fs.Source = warcPath
+	})
+	if err := cc.parseWarc(ctx, warcPath, savePath, st); err != nil {
+		return err
+	}
+	cc.reportStale(nil, []*stateStore{st})
+	return nil
+}
 
+// run은 jobs의 파일을 다운로드 세마포어와 하나의 파싱 워커 풀로 처리합니다.
+// 파일별 처리 상태(대기, 다운로드, 파싱, 완료, 실패)는 각 saveDir의 상태 저장소에 기록되며,
+// 완료된 파일은 건너뜁니다.
+// 실패한 파일이 있으면 나머지를 모두 처리한 뒤 오류를 반환합니다.
+// ctx가 취소되면 새 다운로드와 파싱을 시작하지 않고, 진행 중인 작업을 정리한 뒤 ctx.Err()를 반환합니다.
+func (cc *CommonCrawl) run(ctx context.Context, jobs []fileJob) error {
 	downloadSem := make(chan struct{}, cc.Predowns) // 병렬 다운로드 제한 세마포어
 	taskChan := make(chan warcTask, cc.Predowns)    // 파싱 작업 채널
 
 	var downloadWg sync.WaitGroup
 	var parseWg sync.WaitGroup
 
+	prog := &progress{total: int64(len(jobs))}
+
+	// 이번에 처리할 파일을 대기 상태로 기록
+	selected, err := cc.markPending(jobs)
+	if err != nil {
+		return err
+	}
+	if cc.Stale {
+		fmt.Printf("[재처리] 설정이나 버전이 바뀐 완료 파일 %d개를 다시 처리합니다.\n", len(selected))
+	}
+
 	// ✅ 파싱 워커를 미리 시작 (문제 2 해결)
 	for i := 0; i < cc.Workers; i++ {
 		parseWg.Add(1)
 		go func(workerID int) {
 			defer parseWg.Done()
 			for task := range taskChan {
+				// 취소된 경우 다운로드가 끝난 파일은 다음 실행을 위해 남겨둠
+				if ctx.Err() != nil {
+					continue
+				}
 				fmt.Printf("[워커 %d] 파싱 시작: %s\n", workerID, task.warcLocalPath)
-				err := cc.parseWarc(task.warcLocalPath, task.savePath, logFilePath)
-				if err != nil {
+				err := cc.parseWarc(ctx, task.warcLocalPath, task.savePath, task.state)
+				if ctx.Err() != nil {
+					fmt.Printf("[워커 %d] 파싱 중단: %s\n", workerID, task.warcLocalPath)
+					continue
+				}
+				if errors.Is(err, errCorrupted) {
+					fmt.Printf("[워커 %d] 손상된 파일(%s): %v\n", workerID, task.warcLocalPath, err)
+					prog.corrupt()
+				} else if err != nil {
 					fmt.Printf("[워커 %d] 파싱 실패(%s): %v\n", workerID, task.warcLocalPath, err)
+					prog.fail()
 				} else {
 					fmt.Printf("[워커 %d] 파싱 완료: %s\n", workerID, task.warcLocalPath)
+					prog.complete()
 				}
-				os.Remove(task.warcLocalPath)
+				cc.removeRaw(task.warcLocalPath, errors.Is(err, errCorrupted))
 			}
 		}(i)
 	}
 
 	// ✅ 다운로드 워커 시작
-	for _, path := range paths {
-		saveFileName := strings.Replace(filepath.Base(path), ".warc.gz", ".wrc.gz", 1)
-		savePath := filepath.Join(saveDir, saveFileName)
+	var scheduleErr error
+schedule:
+	for _, job := range jobs {
+		if err := os.MkdirAll(job.saveDir, os.ModePerm); err != nil {
+			scheduleErr = err
+			break
+		}
+
+		saveFileName := cc.OutputFileName(job.path)
+		savePath := filepath.Join(job.saveDir, saveFileName)
 
-		completed, err := isCompletedWarc(logFilePath, saveFileName)
+		st, err := cc.state(job.saveDir)
 		if err != nil {
-			return fmt.Errorf("로그 확인 오류: %w", err)
+			scheduleErr = err
+			break
 		}
-		if completed {
-			fmt.Printf("[스킵] 이미 완료된 파일: %s\n", saveFileName)
+		if !selected[savePath] {
+			if cc.Stale {
+				fmt.Printf("[스킵] 재처리 대상 아님: %s\n", saveFileName)
+			} else {
+				fmt.Printf("[스킵] 이미 완료된 파일: %s\n", saveFileName)
+			}
+			prog.skip()
 			continue
 		}
 
+		// 병렬 다운로드 제한
+		select {
+		case downloadSem <- struct{}{}:
+		case <-ctx.Done():
+			break schedule
+		}
 		downloadWg.Add(1)
-		downloadSem <- struct{}{} // 병렬 다운로드 제한
 
-		go func(p, sp string) {
+		go func(p, sp string, st *stateStore) {
 			defer downloadWg.Done()
 			defer func() { <-downloadSem }()
 
+			name := filepath.Base(sp)
+			cc.mark(st, name, func(fs *FileState) {
+				fs.Status = StatusDownloading
+				fs.Source = p
+			})
+
 			fmt.Printf("[다운로드 시작] %s\n", p)
-			warcLocalPath, err := cc.downloadedWarc(p)
+			start := time.Now()
+			warcLocalPath, err := cc.downloadedWarc(ctx, p)
+			if ctx.Err() != nil {
+				cc.mark(st, name, func(fs *FileState) { fs.Status = StatusPending })
+				return
+			}
 			if err != nil {
-				fmt.Printf("[다운로드 실패] %s: %v\n", p, err)
+				cc.mark(st, name, func(fs *FileState) {
+					fs.Status = StatusFailed
+					fs.Error = err.Error()
+					fs.Corrupted = errors.Is(err, errCorrupted)
+				})
+				if errors.Is(err, errCorrupted) {
+					fmt.Printf("[다운로드 손상] %s: %v\n", p, err)
+					prog.corrupt()
+				} else {
+					fmt.Printf("[다운로드 실패] %s: %v\n", p, err)
+					prog.fail()
+				}
 				return
 			}
-			taskChan <- warcTask{warcLocalPath, sp}
-		}(path, savePath)
+
+			cc.mark(st, name, func(fs *FileState) {
+				fs.Status = StatusDownloaded
+				fs.Download = time.Since(start)
+				if fi, err := os.Stat(warcLocalPath); err == nil {
+					fs.Bytes = fi.Size()
+				}
+			})
+			taskChan <- warcTask{warcLocalPath, sp, st}
+		}(job.path, savePath, st)
 	}
 
 	// ✅ 다운로드가 끝나면 taskChan 닫기
@@ -156,51 +496,154 @@ func (cc *CommonCrawl) GetNews(year int, month int) error {
 	// ✅ 파싱 워커 작업이 모두 끝날 때까지 기다림
 	parseWg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		fmt.Println("[중단] 진행 중인 작업을 정리했습니다. 다시 실행하면 이어서 처리합니다.")
+		return err
+	}
+	if scheduleErr != nil {
+		return scheduleErr
+	}
+	cc.reportStale(jobs, nil)
+	if prog.corrupted > 0 {
+		fmt.Printf("[손상] %d개 파일이 무결성 검증에 실패했습니다. 'crowl status'로 확인하세요.\n", prog.corrupted)
+	}
+	if prog.failed > 0 {
+		return fmt.Errorf("%d개 파일 처리 실패", prog.failed)
+	}
 	return nil
 }
 
-// GetWarcPaths는 지정한 연도(y), 월(m)의 warc.paths.gz 파일을 다운로드하여 압축 해제 후,
-// 그 내용을 파싱하여 WARC 파일 경로 목록을 반환합니다.
-func (cc *CommonCrawl) getNewsWarcPaths(year int, month int) ([]string, error) {
-	// URL 생성 (월을 항상 두 자리로 맞춤)
-	url := fmt.Sprintf("%scrawl-data/CC-NEWS/%d/%02d/warc.paths.gz", cc.BaseURL, year, month)
+// markPending은 jobs 중 이번에 처리할 파일을 골라 saveDir별로 한 번에 대기 상태로 기록하고,
+// 처리할 파일의 출력 경로 집합을 반환합니다.
+func (cc *CommonCrawl) markPending(jobs []fileJob) (map[string]bool, error) {
+	sources := map[string]map[string]string{} // saveDir → 출력 파일 이름 → 원격 경로
+	for _, job := range jobs {
+		if sources[job.saveDir] == nil {
+			sources[job.saveDir] = map[string]string{}
+		}
+		sources[job.saveDir][cc.OutputFileName(job.path)] = job.path
+	}
 
-	// 다운로드할 파일 경로 설정
-	if err := os.MkdirAll(cc.TempDir, os.ModePerm); err != nil {
-		return nil, err
+	selected := map[string]bool{}
+	for saveDir, paths := range sources {
+		st, err := cc.state(saveDir)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for name := range paths {
+			if cc.needsRun(st, name) {
+				names = append(names, name)
+				selected[filepath.Join(saveDir, name)] = true
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		err = st.updateMany(names, func(fs *FileState) {
+			fs.Status = StatusPending
+			fs.Source = paths[fs.Name]
+			fs.Error = ""
+			fs.Corrupted = false
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
+	return selected, nil
+}
 
-	gzipFilePath := filepath.Join(cc.TempDir, "warc.paths.gz")
-	outFile, err := os.Create(gzipFilePath)
-	if err != nil {
-		return nil, err
+// reportStale은 실행을 마친 뒤 다시 처리해야 하는 완료 파일 수를 출력합니다.
+// 이전 설정이나 버전으로 만든 파일은 jobs 중에서 세고, URL 캡처가 대체된 파일은 이번 실행이나 이전 실행에서
+// 다른 파일이 더 나은 캡처를 남기면서 표시되므로 jobs와 stores의 저장 디렉토리 전체에서 셉니다.
+// url.keep을 쓰면 병렬 처리 중 먼저 출력한 캡처가 나중에 대체될 수 있어, 같은 URL의 캡처를 하나만 남기려면
+// -stale로 한 번 더 처리해야 합니다. 남길 캡처는 처리 순서와 관계없이 정해지므로 두 번째 처리에서 더 대체되는 파일은 없습니다.
+func (cc *CommonCrawl) reportStale(jobs []fileJob, stores []*stateStore) {
+	var stale, superseded int
+	for _, job := range jobs {
+		st, err := cc.state(job.saveDir)
+		if err != nil {
+			continue
+		}
+		if fs, ok := st.get(cc.OutputFileName(job.path)); ok && !fs.Superseded && cc.IsStale(fs) {
+			stale++
+		}
+		if !slices.Contains(stores, st) {
+			stores = append(stores, st)
+		}
+	}
+	for _, st := range stores {
+		for _, fs := range st.list() {
+			if fs.Status == StatusDone && fs.Superseded {
+				superseded++
+			}
+		}
 	}
-	defer outFile.Close()
 
-	// 파일 다운로드
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+	if stale > 0 && !cc.Stale {
+		fmt.Printf("[재처리 대상] 완료 파일 %d개가 이전 설정이나 버전으로 만들어졌습니다. -stale 옵션으로 다시 처리할 수 있습니다.\n", stale)
+	}
+	if superseded > 0 {
+		fmt.Printf("[URL 대체] 완료 파일 %d개의 출력에 다른 파일의 더 나은 캡처로 대체된 레코드가 남아 있습니다. "+
+			"같은 URL의 캡처를 하나만 남기려면 같은 대상을 -stale 옵션으로 한 번 더 처리해야 합니다 ('crowl status'에서 (URL 대체)로 표시).\n", superseded)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+// mark는 파일 상태를 갱신하고, 기록에 실패하면 경고만 출력합니다.
+func (cc *CommonCrawl) mark(st *stateStore, name string, fn func(fs *FileState)) {
+	if err := st.update(name, fn); err != nil {
+		fmt.Printf("상태 기록 오류(%s): %v\n", name, err)
 	}
+}
 
-	// 응답을 파일에 쓰기
-	if _, err = io.Copy(outFile, resp.Body); err != nil {
-		return nil, err
+// removeRaw는 파싱이 끝난 원본 파일을 지웁니다. keep_raw 설정이나 로컬 미러에서는 남겨 두되,
+// 손상된 다운로드 파일은 다음 실행에서 다시 받도록 항상 지웁니다.
+func (cc *CommonCrawl) removeRaw(path string, corrupted bool) {
+	if _, ok := cc.source.(localSource); ok {
+		return
 	}
+	if cc.KeepRaw && !corrupted {
+		return
+	}
+	os.Remove(path)
+}
 
-	// 압축 해제하고 내용 읽기
-	gzFile, err := os.Open(gzipFilePath)
+// getPaths는 pathsFile(*.paths.gz) 파일을 다운로드하여 압축 해제 후,
+// 그 내용을 파싱하여 파일 경로 목록을 반환합니다.
+// 같은 temp_dir을 쓰는 여러 실행이 동시에 받아도 겹치지 않도록 고유한 이름의 임시 파일에 받고, 끝나면 지웁니다.
+func (cc *CommonCrawl) getPaths(ctx context.Context, pathsFile string) ([]string, error) {
+	// 다운로드할 임시 파일 생성
+	if err := os.MkdirAll(cc.TempDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	outFile, err := os.CreateTemp(cc.TempDir, filepath.Base(pathsFile)+".*.part")
 	if err != nil {
 		return nil, err
 	}
-	defer gzFile.Close()
+	defer func() {
+		outFile.Close()
+		if err := os.Remove(outFile.Name()); err != nil {
+			// 삭제 실패 시 경고만 표시하고 계속 진행
+			fmt.Printf("임시 파일 삭제 실패: %v\n", err)
+		}
+	}()
+
+	// 파일 다운로드 (Source.Open은 재시도하지 않으므로 여기서 재시도)
+	for attempt := 0; ; attempt++ {
+		err := cc.fetchAll(ctx, pathsFile, outFile)
+		if err == nil {
+			break
+		}
+		if err := cc.client.backoff(ctx, attempt, err); err != nil {
+			return nil, err
+		}
+	}
 
-	gzReader, err := gzip.NewReader(gzFile)
+	// 압축 해제하고 내용 읽기
+	if _, err := outFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	gzReader, err := gzip.NewReader(outFile)
 	if err != nil {
 		return nil, err
 	}
@@ -213,26 +656,46 @@ func (cc *CommonCrawl) getNewsWarcPaths(year int, month int) ([]string, error) {
 	}
 
 	// 파일 내용을 줄 단위로 파싱하여 slice로 반환
-	paths := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return strings.Split(strings.TrimSpace(string(data)), "\n"), nil
+}
 
-	// 압축 파일 삭제 (임시 파일 정리)
-	if err := os.Remove(gzipFilePath); err != nil {
-		// 삭제 실패 시 경고만 표시하고 계속 진행
-		fmt.Printf("임시 파일 삭제 실패: %v\n", err)
+// fetchAll은 path 파일 전체를 받아 f를 처음부터 다시 씁니다.
+func (cc *CommonCrawl) fetchAll(ctx context.Context, path string, f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
 	}
-
-	return paths, nil
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	body, _, err := cc.source.Open(ctx, path, 0)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(f, body)
+	return err
 }
 
-// DownloadedWarc는 warcPath 파일을 다운로드합니다.
-func (cc *CommonCrawl) downloadedWarc(warcPath string) (string, error) {
-	// 정규 표현식으로 WARC 파일 경로에서 연도와 월 추출
+// DownloadedWarc는 warcPath 파일을 Source에서 temp_dir로 다운로드하고 로컬 경로를 반환합니다.
+// 다운로드 중에는 .part 파일에 기록하고 완료된 경우에만 최종 이름으로 옮깁니다.
+// 로컬 미러(file://)는 복사하지 않고 미러 안의 경로를 그대로 반환합니다.
+func (cc *CommonCrawl) downloadedWarc(ctx context.Context, warcPath string) (string, error) {
+	// 정규 표현식으로 WARC 파일 경로 형식 확인
 	match := reWarc.FindStringSubmatch(warcPath)
 
 	if len(match) != 3 {
 		return "", fmt.Errorf("invalid warcPath format: %s", warcPath)
 	}
 
+	// 로컬 미러는 복사하지 않고 제자리에서 파싱
+	if local, ok := cc.source.(localSource); ok {
+		localPath := local.LocalPath(warcPath)
+		if _, err := os.Stat(localPath); err != nil {
+			return "", err
+		}
+		return localPath, nil
+	}
+
 	// 다운로드할 임시 폴더 생성
 	if err := os.MkdirAll(cc.TempDir, os.ModePerm); err != nil {
 		return "", err
@@ -240,74 +703,128 @@ func (cc *CommonCrawl) downloadedWarc(warcPath string) (string, error) {
 
 	destPath := filepath.Join(cc.TempDir, filepath.Base(warcPath))
 
-	// HEAD 요청으로 Content-Length 확인
-	headResp, err := http.Head(cc.BaseURL + warcPath)
+	// 원본 파일 크기와 MD5(ETag/Content-MD5로 알 수 있는 경우) 확인
+	info, err := cc.source.Stat(ctx, warcPath)
 	if err != nil {
 		return "", err
 	}
-	if headResp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HEAD 요청 실패: %s", headResp.Status)
-	}
+	expectedSize, wantMD5 := info.Size, info.MD5
 
-	expectedSize, err := strconv.ParseInt(headResp.Header.Get("Content-Length"), 10, 64)
-	if err != nil || expectedSize <= 0 {
-		return "", fmt.Errorf("유효하지 않은 Content-Length: %v", err)
-	}
-
-	// 이미 임시 파일이 있다면 크기 검사 후 스킵 여부 결정
+	// 이미 임시 파일이 있다면 크기와 MD5 검사 후 스킵 여부 결정
 	if fi, err := os.Stat(destPath); err == nil {
-		if fi.Size() == expectedSize {
+		if fi.Size() != expectedSize {
+			fmt.Printf("[재다운로드] 임시 파일 크기 불일치: %s\n", destPath)
+		} else if err := verifyMD5(destPath, wantMD5); err != nil {
+			fmt.Printf("[재다운로드] 임시 파일 검증 실패(%s): %v\n", destPath, err)
+		} else {
 			fmt.Printf("[스킵] 임시 파일 이미 정상 다운로드됨: %s\n", destPath)
 			return destPath, nil
 		}
-		fmt.Printf("[재다운로드] 임시 파일 크기 불일치: %s\n", destPath)
 		// 불완전한 파일 삭제
 		os.Remove(destPath)
 	}
 
-	// 파일 다운로드
-	resp, err := http.Get(cc.BaseURL + warcPath)
+	// 이전 실행에서 남은 .part 파일이 있으면 이어서 받음
+	outFile, size, err := resumeAtomic(destPath)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	// 실패하거나 중단되어도 받은 부분은 다음 시도를 위해 남겨 둠
+	defer outFile.suspend()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("파일 다운로드 실패: %s", resp.Status)
+	if size > expectedSize {
+		fmt.Printf("[재다운로드] 임시 파일이 원본보다 큼: %s\n", destPath)
+		if err := outFile.Truncate(0); err != nil {
+			return "", err
+		}
+		size = 0
+	} else if size > 0 {
+		fmt.Printf("[이어받기] %s (%d/%d 바이트)\n", destPath, size, expectedSize)
 	}
 
-	outFile, err := os.Create(destPath)
-	if err != nil {
+	for attempt := 0; size < expectedSize; attempt++ {
+		before := size
+		size, err = cc.fetch(ctx, warcPath, outFile, size)
+		if err == nil && size != expectedSize {
+			err = fmt.Errorf("다운로드 크기 불일치: %d/%d 바이트", size, expectedSize)
+		}
+		if err == nil {
+			break
+		}
+		// 재시도는 이 루프에서만 셈 (Source.Open은 한 번만 요청). 진행이 있었으면 횟수를 처음부터 다시 셈
+		if size > before {
+			attempt = 0
+		}
+		if err := cc.client.backoff(ctx, attempt, err); err != nil {
+			return "", err
+		}
+	}
+
+	// 손상된 내용은 이어받아도 복구되지 않으므로 임시 파일을 지움
+	if err := verifyMD5(outFile.Name(), wantMD5); err != nil {
+		outFile.abort()
 		return "", err
 	}
-	defer outFile.Close()
 
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
+	if err := outFile.commit(); err != nil {
 		return "", err
 	}
 
 	return destPath, nil
 }
 
-func (cc *CommonCrawl) parseWarc(filePath, savePath, logPath string) error {
+// fetch는 warcPath의 offset 이후 부분을 받아 f에 이어 쓰고, 기록 후 파일 크기를 반환합니다.
+// Source가 부분 읽기를 지원하지 않아 처음부터 보내면 파일을 비우고 처음부터 다시 씁니다.
+func (cc *CommonCrawl) fetch(ctx context.Context, warcPath string, f *atomicFile, offset int64) (int64, error) {
+	body, start, err := cc.source.Open(ctx, warcPath, offset)
+	if err != nil {
+		return offset, err
+	}
+	defer body.Close()
+
+	if start != offset {
+		if err := f.Truncate(0); err != nil {
+			return offset, err
+		}
+		offset = 0
+	}
+
+	n, err := io.Copy(f, body)
+	return offset + n, err
+}
+
+// parseWarc는 filePath를 파싱하여 savePath에 저장하고 처리 결과를 상태 저장소 st에 기록합니다.
+// 출력 파일은 모두 .part 임시 파일로 쓴 뒤 성공한 경우에만 최종 이름으로 옮기므로,
+// ctx 취소나 오류로 중단되어도 불완전한 출력이나 잘못된 완료 기록이 남지 않습니다.
+func (cc *CommonCrawl) parseWarc(ctx context.Context, filePath, savePath string, st *stateStore) (err error) {
 	saveFileName := filepath.Base(savePath)
 
 	// 완료 여부 확인
-	completed, err := isCompletedWarc(logPath, saveFileName)
-	if err != nil {
-		return fmt.Errorf("로그 확인 오류: %w", err)
-	}
-	if completed {
+	if cc.upToDate(st, saveFileName) {
 		fmt.Printf("[파싱 스킵] 완료된 파일: %s\n", saveFileName)
 		return nil
 	}
 
-	// 중단된 파일 있으면 삭제 후 재생성
-	if _, err := os.Stat(savePath); err == nil {
-		if err := os.Remove(savePath); err != nil {
-			return fmt.Errorf("중단된 파일 삭제 실패: %w", err)
+	cc.mark(st, saveFileName, func(fs *FileState) {
+		fs.Status = StatusParsing
+		fs.Superseded = false
+	})
+	start := time.Now()
+	defer func() {
+		if err == nil {
+			return
 		}
-	}
+		cc.mark(st, saveFileName, func(fs *FileState) {
+			// 중단된 파일은 다음 실행에서 다시 처리
+			if ctx.Err() != nil {
+				fs.Status = StatusPending
+				return
+			}
+			fs.Status = StatusFailed
+			fs.Error = err.Error()
+			fs.Corrupted = errors.Is(err, errCorrupted)
+		})
+	}()
 
 	// WARC 파일 열기
 	file, err := os.Open(filePath)
@@ -316,112 +833,411 @@ func (cc *CommonCrawl) parseWarc(filePath, savePath, logPath string) error {
 	}
 	defer file.Close()
 
-	gzReader, err := gzip.NewReader(file)
+	fileInfo, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("[워커] gzReader 오류: %w", err)
+		return err
 	}
-	defer gzReader.Close()
 
-	reader := bufio.NewReader(gzReader)
+	// 레코드 단위 gzip 멤버 경계로 파일을 나눠 병렬로 압축 해제
+	offsets, err := warc.SplitMembers(file, fileInfo.Size(), cc.Workers)
+	if err != nil {
+		fmt.Printf("[분할 실패] 순차 처리로 전환(%s): %v\n", filePath, err)
+		offsets = []int64{0}
+	}
 
 	// 저장할 디렉토리 정확히 생성
 	if err := os.MkdirAll(filepath.Dir(savePath), os.ModePerm); err != nil {
 		return err
 	}
 
-	// 저장할 파일 생성
-	f, err := os.OpenFile(savePath, os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
+	// 저장할 파일 생성 (parquet은 파티션 디렉토리별로, 언어별 출력은 언어마다 처음 기록할 때 생성)
+	info := OutputInfo{Source: filepath.Base(filePath), Fingerprint: cc.Fingerprint()}
+	var out recordOutput
+	if cc.Language.Split {
+		out = newLanguageOutput(savePath, cc.Output, info)
+	} else if out, err = createOutput(savePath, cc.Output, info); err != nil {
 		return err
 	}
-	defer f.Close()
+	defer out.abort()
 
-	gw := gzip.NewWriter(f)
-	defer func() {
-		if err := gw.Close(); err != nil {
-			fmt.Printf("gzip.Writer 닫기 오류: %v\n", err)
+	// 중복 판정 서명은 출력 파일과 함께 확정
+	var ds *dedup.Session
+	if cc.Dedup.Enabled {
+		if ds, err = cc.dedupSession(filepath.Dir(savePath), saveFileName); err != nil {
+			return err
 		}
-	}()
+		defer ds.Abort()
+	}
+
+	// 같은 URL의 캡처 판정도 출력 파일과 함께 확정
+	var us *dedup.URLSession
+	if cc.URL.Keep != "" {
+		if us, err = cc.urlSession(filepath.Dir(savePath), saveFileName); err != nil {
+			return err
+		}
+		defer us.Abort()
+	}
+
+	// 원본 WARC 레코드 재출력
+	var ww *warc.Writer
+	var wf *atomicFile
+	warcSavePath := trimOutputExt(savePath) + ".warc.gz"
+	if cc.WarcOutput.Enabled {
+		wf, err = createAtomic(warcSavePath)
+		if err != nil {
+			return err
+		}
+		defer wf.abort()
+
+		ww = warc.NewWriter(wf)
+		info := warc.Header{
+			{Name: "description", Value: "crowl filtered subset of " + filepath.Base(filePath)},
+			{Name: "crowl-version", Value: Version},
+			{Name: "crowl-fingerprint", Value: cc.Fingerprint()},
+		}
+		if _, err := ww.WriteWarcinfo(filepath.Base(warcSavePath), info); err != nil {
+			return err
+		}
+	}
+
+	// 출력 기록이 한 번이라도 실패하면 workCtx를 취소하여 읽기와 워커를 멈추고 그 오류를 반환
+	workCtx, stopWork := context.WithCancel(ctx)
+	defer stopWork()
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
+	var entries []cdxj.Entry
+	var writeErr error
 
 	jobChan := make(chan parseJob, cc.Workers*2)
 	var processedCount int64
 
-	for w := 0; w < cc.Workers; w++ {
+	for range cc.Workers {
 		wg.Add(1)
-		go func(workerID int) {
+		go func() {
 			defer wg.Done()
 			for job := range jobChan {
-				headerEnd := bytes.Index(job.Content, []byte("\r\n\r\n"))
-				if headerEnd == -1 {
-					headerEnd = bytes.Index(job.Content, []byte("\n\n"))
-					if headerEnd == -1 {
+				if workCtx.Err() != nil {
+					continue
+				}
+				rec, err := cc.extract(job)
+				if err != nil {
+					continue
+				}
+				if err := cc.Language.identify(rec); err != nil {
+					continue
+				}
+				cc.URL.normalizeURL(rec)
+				var replaced bool
+				if us != nil {
+					if replaced, err = cc.URL.checkSeen(us, rec); err != nil {
+						continue
+					}
+				}
+				if ds != nil {
+					// 같은 URL의 이전 캡처를 대체한 레코드는 그 캡처와 중복이기 쉬우므로 표시만 하고 남김
+					if err := cc.Dedup.checkDuplicate(ds, rec, replaced); err != nil {
 						continue
 					}
 				}
-				htmlContent := job.Content[headerEnd+4:]
 
-				cleaned, err := cc.CleanHTML(htmlContent)
-				if err != nil {
-					continue
+				date, _ := time.Parse(time.RFC3339, rec.Date)
+				var metaJSON json.RawMessage
+				if cc.Metadata && rec.Metadata != nil {
+					metaJSON, _ = json.Marshal(rec.Metadata)
 				}
 
 				mu.Lock()
-				if err := writeWRC(gw, job.URL, cleaned); err != nil {
-					fmt.Printf("[워커 %d] writeWRC 오류: %v\n", workerID, err)
+				if writeErr != nil {
+					mu.Unlock()
+					continue
+				}
+				offset, length, err := out.Write(rec)
+				if err != nil {
+					writeErr = fmt.Errorf("출력 기록 오류(%s): %w", job.URL, err)
+					stopWork()
+					mu.Unlock()
+					continue
+				}
+				if offset >= 0 {
+					fileName := saveFileName
+					if cc.Language.Split {
+						fileName = filepath.Base(languagePath(savePath, rec.Lang))
+					}
+					entry := cdxj.NewEntry(job.URL, date, fileName, offset, length)
+					entry.Meta = metaJSON
+					entries = append(entries, entry)
+				}
+				if ww != nil {
+					offset, length, err := cc.writeWarcRecord(ww, job, rec.body(), metaJSON)
+					if err != nil {
+						writeErr = fmt.Errorf("WARC 기록 오류(%s): %w", job.URL, err)
+						stopWork()
+						mu.Unlock()
+						continue
+					}
+					entries = append(entries, cdxj.NewEntry(job.URL, date, filepath.Base(warcSavePath), offset, length))
 				}
 				atomic.AddInt64(&processedCount, 1)
 				if processedCount%1000 == 0 {
 					fmt.Printf("[진행 상황] %d개 처리 완료\n", processedCount)
-					gw.Flush()
 				}
 				mu.Unlock()
 			}
-		}(w)
+		}()
 	}
 
-	for {
-		headerLines := []string{}
-		for {
-			line, err := reader.ReadString('\n')
-			if err == io.EOF {
-				goto FINISH
-			}
-			if err != nil {
-				goto FINISH
-			}
-			line = strings.TrimRight(line, "\r\n")
-			if line == "" {
-				break
+	ends := append(offsets[1:len(offsets):len(offsets)], fileInfo.Size())
+	readErrs := make([]error, len(offsets))
+
+	var readWg sync.WaitGroup
+	for i := range offsets {
+		readWg.Add(1)
+		go func(i int) {
+			defer readWg.Done()
+			section := io.NewSectionReader(file, offsets[i], ends[i]-offsets[i])
+			readErrs[i] = cc.readWarcSection(workCtx, section, jobChan)
+		}(i)
+	}
+	readWg.Wait()
+
+	close(jobChan)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// 기록 오류로 멈춘 경우 출력과 상태를 확정하지 않음 (읽기 쪽 오류는 그로 인한 취소)
+	if writeErr != nil {
+		return writeErr
+	}
+	// gzip 해제, 레코드 파싱, 다이제스트 검증 중 하나라도 실패하면 손상된 파일로 처리
+	if err := errors.Join(readErrs...); err != nil {
+		return fmt.Errorf("%w: %w", errCorrupted, err)
+	}
+
+	// 출력 파일 확정
+	if err := out.commit(); err != nil {
+		return err
+	}
+	if wf != nil {
+		if err := wf.commit(); err != nil {
+			return err
+		}
+	}
+	if ds != nil {
+		if err := ds.Commit(); err != nil {
+			return err
+		}
+	}
+	var superseded bool
+	if us != nil {
+		if err := us.Commit(); err != nil {
+			return err
+		}
+		// 더 나은 캡처로 대체된 레코드가 출력에 남은 파일은 재처리 대상으로 표시
+		for _, name := range us.Superseded() {
+			if name == saveFileName {
+				superseded = true
+				continue
 			}
-			headerLines = append(headerLines, line)
+			cc.mark(st, name, func(fs *FileState) { fs.Superseded = true })
 		}
+	}
 
-		header := cc.ParseHeader(headerLines)
+	// 출력 파일 CDXJ 인덱스 기록
+	indexPath := trimOutputExt(savePath) + ".cdxj"
+	if err := cdxj.WriteFile(indexPath, entries); err != nil {
+		return fmt.Errorf("인덱스 기록 오류: %w", err)
+	}
 
-		if header["WARC-Type"] != "response" {
-			skipBytes(reader, header["Content-Length"])
-			continue
+	// 완료 상태 기록
+	var outputBytes int64
+	for _, p := range append(out.paths(), warcSavePath, indexPath) {
+		if fi, err := os.Stat(p); err == nil {
+			outputBytes += fi.Size()
 		}
+	}
+	return st.update(saveFileName, func(fs *FileState) {
+		fs.Status = StatusDone
+		fs.Records = processedCount
+		fs.OutputBytes = outputBytes
+		fs.Parse = time.Since(start)
+		fs.Error = ""
+		fs.Corrupted = false
+		fs.Superseded = fs.Superseded || superseded
+		fs.Fingerprint = cc.Fingerprint()
+		fs.Version = Version
+	})
+}
 
-		url := header["WARC-Target-URI"]
-		contentLength, _ := strconv.Atoi(header["Content-Length"])
-		content := make([]byte, contentLength)
-		if _, err = io.ReadFull(reader, content); err != nil {
+// readWarcSection은 gzip 멤버 경계에서 시작하는 구간을 읽어 Mode에 맞는 레코드를 jobChan으로 보냅니다.
+// 보내는 레코드마다 WARC-Block-Digest/WARC-Payload-Digest를 검증하고, 불일치하면 오류를 반환합니다.
+// ctx가 취소되면 즉시 ctx.Err()를 반환합니다.
+func (cc *CommonCrawl) readWarcSection(ctx context.Context, r io.Reader, jobChan chan<- parseJob) error {
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("[워커] gzReader 오류: %w", err)
+	}
+	defer gzReader.Close()
+
+	wr := warc.NewReader(gzReader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rec, err := wr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("WARC 레코드 읽기 오류: %w", err)
+		}
+
+		if rec.Type() != cc.recordType() {
 			continue
 		}
 
-		jobChan <- parseJob{URL: url, Content: content}
+		content, err := io.ReadAll(rec.Body)
+		if err != nil {
+			return fmt.Errorf("WARC 본문 읽기 오류(%s): %w", rec.TargetURI(), err)
+		}
+		if err := warc.Verify(rec.Header, content); err != nil {
+			return fmt.Errorf("%s: %w", rec.TargetURI(), err)
+		}
+
+		select {
+		case jobChan <- parseJob{URL: rec.TargetURI(), Header: rec.Header, Content: content}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// recordType은 Mode에서 처리할 WARC-Type을 반환합니다.
+func (cc *CommonCrawl) recordType() string {
+	switch cc.Mode {
+	case KindWet:
+		return warc.TypeConversion
+	case KindWat:
+		return warc.TypeMetadata
 	}
+	return warc.TypeResponse
+}
 
-FINISH:
-	close(jobChan)
-	wg.Wait()
+// extract는 Mode에 따라 레코드 본문을 출력할 Record로 변환합니다.
+// warc는 HTTP 응답을 해석하여 Filter 조건(상태 코드, 미디어 타입)에 맞는 응답만 남기고,
+// 전송·콘텐츠 인코딩을 해제한 HTML을 Extractor에 따라 정제하거나 기사 본문을 추출합니다. wet은 텍스트를 그대로,
+// wat은 JSON 봉투에서 제목·링크·HTTP 헤더를 추립니다.
+// Metadata 설정이나 readability 추출 방식에서는 정제 전 문서의 메타데이터도 함께 채웁니다.
+func (cc *CommonCrawl) extract(job parseJob) (*Record, error) {
+	rec := &Record{
+		URL:         job.URL,
+		Date:        job.Header.Get("WARC-Date"),
+		RecordID:    job.Header.Get("WARC-Record-ID"),
+		ContentType: job.Header.Get("Content-Type"),
+	}
 
-	// 완료 기록 로그 작성
-	return logCompletedWarc(logPath, saveFileName)
+	switch cc.Mode {
+	case KindWet:
+		text := bytes.TrimSpace(job.Content)
+		if len(text) == 0 {
+			return nil, fmt.Errorf("빈 텍스트: %s", job.URL)
+		}
+		rec.Text = string(text)
+		rec.content = text
+		rec.plain = rec.Text
+		return rec, nil
+	case KindWat:
+		w, err := parseWat(job.Content)
+		if err != nil {
+			return nil, err
+		}
+		rec.Status = w.Status
+		rec.WAT = w
+		rec.content, err = json.Marshal(w)
+		return rec, err
+	}
+
+	resp, err := warc.ParseResponse(job.Content)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 응답 해석 오류(%s): %w", job.URL, err)
+	}
+	rec.Status = resp.StatusCode
+	rec.ContentType = resp.Header.Get("Content-Type")
+	if err := cc.Filter.allow(resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", job.URL, err)
+	}
+
+	// goquery는 UTF-8만 해석하므로 선언 또는 추정한 인코딩에서 먼저 변환
+	body, cs, err := charset.ToUTF8(resp.Body, rec.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("문자 인코딩 변환 오류(%s, %s): %w", job.URL, cs.Charset, err)
+	}
+	rec.Charset = cs.Charset
+
+	doc, err := parseHTML(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 정제와 본문 추출은 meta, script, link 태그를 지우므로 메타데이터와 canonical 링크를 먼저 추출
+	if cc.Metadata || cc.Extractor == ExtractorReadability {
+		rec.Metadata = article.ExtractMetadata(doc, job.URL)
+	}
+	if cc.URL.Canonical {
+		rec.canonical = urlnorm.Canonical(job.URL, doc.Find(`link[rel="canonical"]`).First().AttrOr("href", ""))
+	}
+
+	if cc.Extractor == ExtractorReadability {
+		a, err := article.FromDocument(doc, job.URL, rec.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("본문 추출 실패(%s): %w", job.URL, err)
+		}
+		rec.Text, rec.HTML = a.Text, a.HTML
+		rec.plain = a.Text
+		rec.content, err = json.Marshal(a)
+		return rec, err
+	}
+
+	cleaned, err := cc.cleanDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	// 언어 판별과 중복 판정에는 정제된 문서의 텍스트를 씀 (cleanDocument는 doc에서 요소를 직접 지움)
+	if cc.Language.Enabled || cc.Dedup.Enabled {
+		rec.plain = doc.Find("body").Text()
+	}
+	rec.HTML = string(cleaned)
+	rec.content = cleaned
+	return rec, nil
+}
+
+// outputType은 warc 모드 출력 본문의 Content-Type입니다.
+func (cc *CommonCrawl) outputType() string {
+	if cc.Extractor == ExtractorReadability {
+		return "application/json"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// OutputFileName은 WARC/WET/WAT 파일 경로에서 Output 형식에 맞는 출력 파일 이름을 만듭니다.
+// 예: jsonl/zstd 형식에서 x.warc.gz → x.jsonl.zst
+func (cc *CommonCrawl) OutputFileName(path string) string {
+	return strings.TrimSuffix(WRCFileName(path), ".wrc.gz") + cc.Output.Ext()
+}
+
+// WRCFileName은 WARC/WET/WAT 파일 경로에서 저장할 wrc.gz 파일 이름을 만듭니다.
+// 예: x.warc.gz → x.wrc.gz, x.warc.wet.gz → x.wet.wrc.gz
+func WRCFileName(path string) string {
+	name := filepath.Base(path)
+	for _, kind := range []string{KindWet, KindWat} {
+		if base, ok := strings.CutSuffix(name, ".warc."+kind+".gz"); ok {
+			return base + "." + kind + ".wrc.gz"
+		}
+	}
+	return strings.TrimSuffix(name, ".warc.gz") + ".wrc.gz"
 }
 
 // 헤더 파싱
@@ -438,15 +1254,21 @@ func (cc *CommonCrawl) ParseHeader(headerLines []string) map[string]string {
 
 // CleanHTML은 불필요한 태그들을 제거한 HTML 본문을 반환합니다.
 func (cc *CommonCrawl) CleanHTML(rawHTML []byte) ([]byte, error) {
-	// HTML 주석 제거
-	htmlWithoutComments := reComments.ReplaceAll(rawHTML, []byte(""))
-
-	// HTML 파싱
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(htmlWithoutComments))
+	doc, err := parseHTML(rawHTML)
 	if err != nil {
 		return nil, err
 	}
+	return cc.cleanDocument(doc)
+}
+
+// parseHTML은 HTML 주석을 제거한 뒤 문서를 파싱합니다.
+func parseHTML(rawHTML []byte) (*goquery.Document, error) {
+	htmlWithoutComments := reComments.ReplaceAll(rawHTML, []byte(""))
+	return goquery.NewDocumentFromReader(bytes.NewReader(htmlWithoutComments))
+}
 
+// cleanDocument는 remove_selectors 규칙에 따라 doc에서 태그, 클래스, 속성을 제거한 HTML을 반환합니다.
+func (cc *CommonCrawl) cleanDocument(doc *goquery.Document) ([]byte, error) {
 	// 클래스 제거를 위한 집합(set) 구성
 	removeClassSet := make(map[string]struct{})
 	for _, class := range cc.RemoveSelectors.Classes {
@@ -531,12 +1353,6 @@ func cleanSpaces(html string) string {
 	return strings.TrimSpace(cleaned)
 }
 
-// skipBytes는 지정된 길이만큼 바이트를 건너뜁니다.
-func skipBytes(reader *bufio.Reader, lenStr string) {
-	n, _ := strconv.Atoi(lenStr)
-	io.CopyN(io.Discard, reader, int64(n))
-}
-
 // 클래스 확인 함수
 func containsAnyKeyword(className string, keywords []string) bool {
 	for _, keyword := range keywords {
@@ -560,73 +1376,39 @@ func containsAnyKeyword(className string, keywords []string) bool {
 	return false
 }
 
-// 파일 핸들을 전달받아 wrc.gz 형식으로 데이터를 추가하는 함수
-func writeWRC(gw *gzip.Writer, url string, content []byte) error {
-	entry := fmt.Sprintf("%s\n%d\n%s\n\n", url, len(content), content)
-
-	if _, err := gw.Write([]byte(entry)); err != nil {
-		return fmt.Errorf("writeWRC 오류(URL: %s): %w", url, err)
-	}
-
-	return nil
-}
-
-var logMu sync.Mutex
-var completedSet sync.Map // 메모리 기반 세트 추가
-
-// logCompletedWarc는 완료된 WARC 파일 이름을 로그 파일에 기록합니다.
-func logCompletedWarc(logFilePath string, warcFileName string) error {
-	logMu.Lock()
-	defer logMu.Unlock()
-
-	// 로그 파일에 완료된 WARC 파일 이름 기록
-	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// writeWarcRecord는 원본 응답 레코드와 (설정 시) 메타데이터 metadata 레코드, 정제 결과 conversion 레코드를
+// 기록하고 응답 레코드의 오프셋과 길이를 반환합니다.
+func (cc *CommonCrawl) writeWarcRecord(ww *warc.Writer, job parseJob, cleaned []byte, meta []byte) (int64, int64, error) {
+	offset := ww.Offset()
+	id, err := ww.WriteRecord(job.Header, job.Content)
 	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	// 파일에 WARC 파일 이름 추가
-	if _, err := f.WriteString(warcFileName + "\n"); err != nil {
-		fmt.Printf("로그 파일 기록 오류: %v\n", err)
-		return err
+		return 0, 0, err
 	}
-
-	return nil
-}
-
-// isCompletedWarc는 로그 파일에서 WARC 파일 이름을 검색하여 완료 여부를 확인합니다.
-
-func isCompletedWarc(logFilePath, warcFileName string) (bool, error) {
-	if _, exists := completedSet.Load(warcFileName); exists {
-		return true, nil
-	}
-	// 기존 파일 기반 검사 로직 이후, 발견하면 메모리에 저장
-	found, err := isCompletedWarcFile(logFilePath, warcFileName)
-	if err != nil {
-		return false, err
+	length := ww.Offset() - offset
+
+	if meta != nil {
+		metadata := warc.Header{
+			{Name: "WARC-Type", Value: warc.TypeMetadata},
+			{Name: "WARC-Target-URI", Value: job.URL},
+			{Name: "WARC-Refers-To", Value: id},
+			{Name: "Content-Type", Value: "application/json"},
+		}
+		if _, err := ww.WriteRecord(metadata, meta); err != nil {
+			return 0, 0, err
+		}
 	}
-	if found {
-		completedSet.Store(warcFileName, struct{}{})
+	if !cc.WarcOutput.Cleaned {
+		return offset, length, nil
 	}
-	return found, nil
-}
 
-func isCompletedWarcFile(logFilePath string, warcFileName string) (bool, error) {
-	file, err := os.Open(logFilePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+	conversion := warc.Header{
+		{Name: "WARC-Type", Value: warc.TypeConversion},
+		{Name: "WARC-Target-URI", Value: job.URL},
+		{Name: "WARC-Refers-To", Value: id},
+		{Name: "Content-Type", Value: cc.outputType()},
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if strings.TrimSpace(scanner.Text()) == warcFileName {
-			return true, nil
-		}
+	if _, err := ww.WriteRecord(conversion, cleaned); err != nil {
+		return 0, 0, err
 	}
-	return false, scanner.Err()
+	return offset, length, nil
 }
@@ -0,0 +1,235 @@
+package crowl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"parkjunwoo.com/crowl/pkg/warc"
+	"parkjunwoo.com/crowl/pkg/wrc"
+)
+
+// 같은 temp_dir을 쓰는 여러 실행이 동시에 paths.gz를 받아도 서로의 임시 파일을 덮어쓰지 않아야 합니다.
+func TestGetPathsConcurrent(t *testing.T) {
+	const runs = 8
+	lists := make([][]string, runs)
+	bodies := make([][]byte, runs)
+	for i := range lists {
+		for j := 0; j < 200; j++ {
+			lists[i] = append(lists[i], fmt.Sprintf("crawl-data/CC-NEWS/2025/%02d/CC-NEWS-2025%02d01000000-%05d.warc.gz", i+1, i+1, j))
+		}
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		gw.Write([]byte(strings.Join(lists[i], "\n") + "\n"))
+		gw.Close()
+		bodies[i] = buf.Bytes()
+	}
+
+	// 모든 실행이 본문을 받기 시작한 뒤에 끝나도록 본문을 나누어 보냄
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var i int
+		fmt.Sscanf(r.URL.Path, "/crawl-data/CC-NEWS/%d/warc.paths.gz", &i)
+		body := bodies[i]
+		half := len(body) / 2
+		w.Write(body[:half])
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write(body[half:])
+	}))
+	defer srv.Close()
+
+	cc := newTestCommonCrawl(t, srv.URL+"/", HTTPConfig{Retries: -1})
+	var wg sync.WaitGroup
+	errs := make([]error, runs)
+	got := make([][]string, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 다른 달이라도 임시 파일 이름(warc.paths.gz)은 같음
+			got[i], errs[i] = cc.getPaths(context.Background(), fmt.Sprintf("crawl-data/CC-NEWS/%d/warc.paths.gz", i))
+		}()
+	}
+	wg.Wait()
+
+	for i := range got {
+		if errs[i] != nil {
+			t.Errorf("실행 %d: %v", i, errs[i])
+			continue
+		}
+		if strings.Join(got[i], "\n") != strings.Join(lists[i], "\n") {
+			t.Errorf("실행 %d: 경로 %d개, 첫 경로 %q", i, len(got[i]), got[i][0])
+		}
+	}
+	entries, err := os.ReadDir(cc.TempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("임시 파일이 남음: %s", e.Name())
+	}
+}
+
+// WARC 출력의 metadata·conversion 레코드는 WARC-Refers-To로 원본 응답 레코드를 가리켜야 합니다.
+func TestWriteWarcRecordRefersTo(t *testing.T) {
+	cc := &CommonCrawl{}
+	cc.WarcOutput.Cleaned = true
+	job := parseJob{
+		URL: "https://news.example.com/1",
+		Header: warc.Header{
+			{Name: "WARC-Type", Value: warc.TypeResponse},
+			{Name: "WARC-Target-URI", Value: "https://news.example.com/1"},
+			{Name: "WARC-Record-ID", Value: "<urn:uuid:response>"},
+			{Name: "Content-Type", Value: "application/http; msgtype=response"},
+		},
+		Content: []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<p>기사</p>"),
+	}
+
+	var buf bytes.Buffer
+	ww := warc.NewWriter(&buf)
+	if _, err := ww.WriteWarcinfo("out.warc.gz", nil); err != nil {
+		t.Fatal(err)
+	}
+	offset, length, err := cc.writeWarcRecord(ww, job, []byte("<p>기사</p>"), []byte(`{"lang":"ko"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 반환한 오프셋·길이는 응답 레코드 멤버 하나
+	members, err := warc.Members(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 4 || members[1] != (warc.Member{Offset: offset, Length: length}) {
+		t.Fatalf("멤버 %+v, 오프셋 %d, 길이 %d", members, offset, length)
+	}
+
+	want := []struct{ typ, refersTo, contentType string }{
+		{warc.TypeResponse, "", "application/http; msgtype=response"},
+		{warc.TypeMetadata, "<urn:uuid:response>", "application/json"},
+		{warc.TypeConversion, "<urn:uuid:response>", "text/html; charset=utf-8"},
+	}
+	for i, m := range members[1:] {
+		rec, err := warc.OpenRecord(bytes.NewReader(buf.Bytes()), m.Offset, m.Length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Type() != want[i].typ || rec.Header.Get("WARC-Refers-To") != want[i].refersTo ||
+			rec.Header.Get("Content-Type") != want[i].contentType || rec.TargetURI() != job.URL {
+			t.Errorf("레코드 %d 헤더 = %v", i, rec.Header)
+		}
+	}
+}
+
+// writeTestWarc는 captures(URL, WARC-Date) 순서로 HTML 응답 레코드를 담은 WARC 파일을 만듭니다.
+func writeTestWarc(t *testing.T, path string, captures ...[2]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	ww := warc.NewWriter(&buf)
+	for i, c := range captures {
+		body := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html><body><p>%s 캡처 %d, %s</p></body></html>", c[0], i, c[1])
+		header := warc.Header{
+			{Name: "WARC-Type", Value: warc.TypeResponse},
+			{Name: "WARC-Target-URI", Value: c[0]},
+			{Name: "WARC-Date", Value: c[1]},
+			{Name: "WARC-Record-ID", Value: fmt.Sprintf("<urn:uuid:%s-%d>", filepath.Base(path), i)},
+			{Name: "Content-Type", Value: "application/http; msgtype=response"},
+		}
+		if _, err := ww.WriteRecord(header, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readWRCURLs(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := wrc.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for rec, err := range r.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, rec.URL)
+	}
+	return urls
+}
+
+// url.keep에서 먼저 처리한 파일의 캡처가 나중 파일의 더 나은 캡처로 대체되면, 먼저 처리한 파일은
+// 재처리 대상으로 표시되고 -stale로 한 번 더 처리하면 같은 URL의 캡처가 하나만 남습니다.
+func TestURLKeepSecondPass(t *testing.T) {
+	dir := t.TempDir()
+	cc := &CommonCrawl{Workers: 1, TempDir: dir, DataDir: dir}
+	cc.URL.Keep = "first"
+	if err := cc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	late, early := filepath.Join(dir, "late.warc.gz"), filepath.Join(dir, "early.warc.gz")
+	writeTestWarc(t, late, [2]string{"https://example.com/a", "2025-04-02T00:00:00Z"}, [2]string{"https://example.com/b", "2025-04-02T00:00:00Z"})
+	writeTestWarc(t, early, [2]string{"https://m.example.com/a/?utm_source=x", "2025-04-01T00:00:00Z"})
+	lateOut, earlyOut := filepath.Join(dir, "late.wrc.gz"), filepath.Join(dir, "early.wrc.gz")
+
+	superseded := func() map[string]bool {
+		st, err := cc.state(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]bool{}
+		for _, fs := range st.list() {
+			m[fs.Name] = fs.Superseded
+		}
+		return m
+	}
+
+	ctx := context.Background()
+	for _, p := range [][2]string{{late, lateOut}, {early, earlyOut}} {
+		if err := cc.ParseFile(ctx, p[0], p[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readWRCURLs(t, lateOut); len(got) != 2 {
+		t.Errorf("첫 처리 late 출력 %v: 대체되기 전에 출력한 캡처가 있어야 함", got)
+	}
+	if s := superseded(); !s["late.wrc.gz"] || s["early.wrc.gz"] {
+		t.Errorf("첫 처리 후 URL 대체 표시 %v", s)
+	}
+
+	// 두 번째 처리: 대체 표시된 파일만 다시 처리되고 더 대체되는 파일은 없음
+	cc.Stale = true
+	for _, p := range [][2]string{{late, lateOut}, {early, earlyOut}} {
+		if err := cc.ParseFile(ctx, p[0], p[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readWRCURLs(t, lateOut); !slices.Equal(got, []string{"https://example.com/b"}) {
+		t.Errorf("두 번째 처리 late 출력 %v", got)
+	}
+	if got := readWRCURLs(t, earlyOut); len(got) != 1 {
+		t.Errorf("두 번째 처리 early 출력 %v", got)
+	}
+	if s := superseded(); s["late.wrc.gz"] || s["early.wrc.gz"] {
+		t.Errorf("두 번째 처리 후 URL 대체 표시 %v", s)
+	}
+}
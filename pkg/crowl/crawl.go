@@ -0,0 +1,146 @@
+package crowl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Common Crawl 데이터셋 종류
+const (
+	DatasetNews = "CC-NEWS"
+	DatasetMain = "CC-MAIN"
+)
+
+// Common Crawl이 제공하는 경로 목록 종류
+const (
+	KindWarc = "warc"
+	KindWat  = "wat"
+	KindWet  = "wet"
+)
+
+var (
+	reMainCrawlID = regexp.MustCompile(`^CC-MAIN-\d{4}-\d{2}$`)
+	reNewsTime    = regexp.MustCompile(`CC-NEWS-(\d{14})-\d{5}\.warc\.gz$`)
+)
+
+// Crawl은 처리 대상 크롤 하나를 나타냅니다.
+// CC-NEWS는 연도/월 단위, CC-MAIN은 CC-MAIN-YYYY-WW 크롤 ID 단위입니다.
+type Crawl struct {
+	Dataset string
+	ID      string
+	Year    int
+	Month   int
+}
+
+// CrawlInfo는 collinfo.json의 크롤 항목입니다.
+type CrawlInfo struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	CdxAPI string `json:"cdx-api"`
+}
+
+// NewsCrawl은 지정한 연도와 월의 CC-NEWS 크롤을 반환합니다.
+func NewsCrawl(year int, month int) Crawl {
+	return Crawl{
+		Dataset: DatasetNews,
+		ID:      fmt.Sprintf("%s-%04d-%02d", DatasetNews, year, month),
+		Year:    year,
+		Month:   month,
+	}
+}
+
+// NewsCrawls는 from 이상 to 미만 기간에 걸친 달마다의 CC-NEWS 크롤을 순서대로 반환합니다.
+func NewsCrawls(from, to time.Time) []Crawl {
+	from, to = from.UTC(), to.UTC()
+	var crawls []Crawl
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); m.Before(to); m = m.AddDate(0, 1, 0) {
+		crawls = append(crawls, NewsCrawl(m.Year(), int(m.Month())))
+	}
+	return crawls
+}
+
+// MainCrawl은 CC-MAIN-YYYY-WW 형식의 크롤 ID로 CC-MAIN 크롤을 반환합니다.
+func MainCrawl(id string) (Crawl, error) {
+	if !reMainCrawlID.MatchString(id) {
+		return Crawl{}, fmt.Errorf("잘못된 CC-MAIN 크롤 ID: %s", id)
+	}
+	return Crawl{Dataset: DatasetMain, ID: id}, nil
+}
+
+// PathsFile은 kind(warc, wat, wet)에 해당하는 경로 목록 파일의 상대 경로를 반환합니다.
+func (c Crawl) PathsFile(kind string) (string, error) {
+	switch c.Dataset {
+	case DatasetNews:
+		if kind != KindWarc {
+			return "", fmt.Errorf("CC-NEWS는 %s 경로 목록을 제공하지 않습니다", kind)
+		}
+		return fmt.Sprintf("crawl-data/CC-NEWS/%d/%02d/warc.paths.gz", c.Year, c.Month), nil
+	case DatasetMain:
+		switch kind {
+		case KindWarc, KindWat, KindWet:
+			return fmt.Sprintf("crawl-data/%s/%s.paths.gz", c.ID, kind), nil
+		}
+		return "", fmt.Errorf("알 수 없는 경로 목록 종류: %s", kind)
+	}
+	return "", fmt.Errorf("알 수 없는 데이터셋: %s", c.Dataset)
+}
+
+// SaveDir은 크롤 결과를 저장할 디렉토리를 반환합니다.
+// CC-NEWS는 DataDir/YYYY/MM, CC-MAIN은 DataDir/CC-MAIN-YYYY-WW 입니다.
+func (c Crawl) SaveDir(dataDir string) string {
+	if c.Dataset == DatasetNews {
+		return filepath.Join(dataDir, fmt.Sprintf("%04d", c.Year), fmt.Sprintf("%02d", c.Month))
+	}
+	return filepath.Join(dataDir, c.ID)
+}
+
+// ListCrawls는 collinfo.json에서 사용 가능한 CC-MAIN 크롤 목록을 가져옵니다.
+func (cc *CommonCrawl) ListCrawls(ctx context.Context) ([]CrawlInfo, error) {
+	resp, err := cc.client.get(ctx, cc.IndexURL+"collinfo.json")
+	if err != nil {
+		return nil, fmt.Errorf("collinfo.json 다운로드 실패: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var infos []CrawlInfo
+	if err := json.NewDecoder(resp.Body).Decode(&infos); err != nil {
+		return nil, fmt.Errorf("collinfo.json 파싱 오류: %w", err)
+	}
+
+	crawls := infos[:0]
+	for _, info := range infos {
+		if strings.HasPrefix(info.ID, DatasetMain+"-") {
+			crawls = append(crawls, info)
+		}
+	}
+	return crawls, nil
+}
+
+// ListPaths는 크롤의 kind(warc, wat, wet) 파일 경로 목록을 반환합니다.
+func (cc *CommonCrawl) ListPaths(ctx context.Context, crawl Crawl, kind string) ([]string, error) {
+	pathsFile, err := crawl.PathsFile(kind)
+	if err != nil {
+		return nil, err
+	}
+	return cc.getPaths(ctx, pathsFile)
+}
+
+// newsFileTime은 CC-NEWS 파일 이름(CC-NEWS-YYYYMMDDhhmmss-NNNNN.warc.gz)의 타임스탬프를 반환합니다.
+func newsFileTime(path string) (time.Time, bool) {
+	match := reNewsTime.FindStringSubmatch(path)
+	if len(match) != 2 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("20060102150405", match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
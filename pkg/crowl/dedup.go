@@ -0,0 +1,91 @@
+package crowl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"parkjunwoo.com/crowl/pkg/dedup"
+)
+
+// dedupFileName은 saveDir마다 두는 중복 판정 서명 로그 파일 이름입니다.
+const dedupFileName = "dedup.sig"
+
+// 중복 레코드 처리 방식
+const (
+	DedupDrop     = "drop"     // 처음 본 레코드만 남김
+	DedupAnnotate = "annotate" // 모두 남기고 dup_cluster, duplicate 필드로 표시
+)
+
+// errDuplicate는 레코드가 이미 본 본문의 중복이라 건너뛰었음을 나타냅니다.
+var errDuplicate = errors.New("중복 레코드")
+
+// DedupConfig는 추출한 본문 텍스트의 중복 판정 설정입니다.
+// 서명은 saveDir(한 달 또는 크롤 하나)마다 dedup.sig에 저장하여 모든 파싱 워커와 이후 실행이 함께 씁니다.
+type DedupConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Action      string `yaml:"action"`       // drop(기본) 또는 annotate
+	MaxDistance *int   `yaml:"max_distance"` // 유사 중복으로 볼 SimHash 해밍 거리 (기본 3, 0이면 완전 중복만)
+}
+
+func (d *DedupConfig) setDefaults(mode string) error {
+	if !d.Enabled {
+		return nil
+	}
+	if mode == KindWat {
+		return errors.New("wat 모드는 본문 텍스트가 없어 중복을 판정할 수 없습니다")
+	}
+	switch d.Action {
+	case "":
+		d.Action = DedupDrop
+	case DedupDrop, DedupAnnotate:
+	default:
+		return fmt.Errorf("알 수 없는 중복 처리 방식: %s", d.Action)
+	}
+	if d.MaxDistance == nil {
+		d.MaxDistance = new(int)
+		*d.MaxDistance = 3
+	}
+	if *d.MaxDistance < 0 || *d.MaxDistance > dedup.MaxDistanceLimit {
+		return fmt.Errorf("max_distance는 0~%d 사이여야 합니다: %d", dedup.MaxDistanceLimit, *d.MaxDistance)
+	}
+	return nil
+}
+
+// dedupSession은 saveDir의 서명 저장소에서 source 파일의 Session을 시작합니다.
+// 같은 CommonCrawl 안에서는 디렉토리마다 저장소를 하나만 엽니다.
+func (cc *CommonCrawl) dedupSession(saveDir, source string) (*dedup.Session, error) {
+	cc.statesMu.Lock()
+	defer cc.statesMu.Unlock()
+
+	key := filepath.Clean(saveDir)
+	s, ok := cc.dedups[key]
+	if !ok {
+		var err error
+		s, err = dedup.Open(filepath.Join(key, dedupFileName), *cc.Dedup.MaxDistance)
+		if err != nil {
+			return nil, err
+		}
+		if cc.dedups == nil {
+			cc.dedups = map[string]*dedup.Store{}
+		}
+		cc.dedups[key] = s
+	}
+	return s.Begin(source), nil
+}
+
+// checkDuplicate는 rec의 본문 텍스트를 이미 본 텍스트와 비교하여 클러스터 id를 기록합니다.
+// drop 방식에서 중복이면 errDuplicate를 반환하되, keep이면 중복이어도 표시만 합니다.
+// 본문 텍스트가 없으면 판정하지 않습니다.
+func (d DedupConfig) checkDuplicate(ds *dedup.Session, rec *Record, keep bool) error {
+	sig, ok := dedup.Sign(rec.plain)
+	if !ok {
+		return nil
+	}
+	m := ds.Add(sig)
+	if m.Duplicate != "" && d.Action == DedupDrop && !keep {
+		return fmt.Errorf("%w(%s): %s", errDuplicate, m.Duplicate, m.Cluster)
+	}
+	rec.Cluster, rec.Duplicate = m.Cluster, m.Duplicate
+	return nil
+}
@@ -0,0 +1,53 @@
+package crowl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"parkjunwoo.com/crowl/pkg/warc"
+)
+
+// errFiltered는 응답이 Filter 조건에 맞지 않아 건너뛰었음을 나타냅니다.
+var errFiltered = errors.New("필터 조건에 맞지 않는 응답")
+
+// FilterConfig는 warc 모드에서 처리할 HTTP 응답의 조건입니다.
+// 설정에 없으면(nil) 기본값을, 빈 목록([])이면 조건 없이 전부 처리합니다.
+type FilterConfig struct {
+	Status       []int    `yaml:"status"`        // 처리할 상태 코드 (기본 200)
+	ContentTypes []string `yaml:"content_types"` // 처리할 미디어 타입 (기본 text/html, application/xhtml+xml)
+}
+
+func (f *FilterConfig) setDefaults() {
+	if f.Status == nil {
+		f.Status = []int{200}
+	}
+	if f.ContentTypes == nil {
+		f.ContentTypes = []string{"text/html", "application/xhtml+xml"}
+	}
+	for i, ct := range f.ContentTypes {
+		f.ContentTypes[i] = strings.ToLower(strings.TrimSpace(ct))
+	}
+}
+
+// allow는 resp가 조건에 맞지 않으면 이유를 담은 errFiltered를 반환합니다.
+// Content-Type 헤더가 없으면 본문 앞부분으로 미디어 타입을 추정합니다.
+func (f FilterConfig) allow(resp *warc.Response) error {
+	if len(f.Status) > 0 && !slices.Contains(f.Status, resp.StatusCode) {
+		return fmt.Errorf("%w: 상태 %d", errFiltered, resp.StatusCode)
+	}
+	if len(f.ContentTypes) == 0 {
+		return nil
+	}
+
+	mt := resp.MediaType()
+	if mt == "" {
+		mt, _, _ = strings.Cut(http.DetectContentType(resp.Body), ";")
+	}
+	if !slices.Contains(f.ContentTypes, mt) {
+		return fmt.Errorf("%w: %s", errFiltered, mt)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package crowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// HTTP 설정 기본값
+const (
+	defaultRetries        = 5
+	defaultBackoffBase    = time.Second
+	defaultBackoffMax     = time.Minute
+	defaultRequestTimeout = 30 * time.Second
+	defaultIdleTimeout    = 60 * time.Second
+)
+
+// HTTPConfig는 Common Crawl 서버 요청의 재시도와 시간 제한 설정입니다.
+type HTTPConfig struct {
+	Retries        int           `yaml:"retries"`         // 일시적 오류 시 최대 재시도 횟수 (음수면 재시도하지 않음)
+	BackoffBase    time.Duration `yaml:"backoff_base"`    // 첫 재시도 대기 시간 (이후 2배씩 증가)
+	BackoffMax     time.Duration `yaml:"backoff_max"`     // 재시도 대기 시간 상한
+	RequestTimeout time.Duration `yaml:"request_timeout"` // 연결부터 응답 헤더 수신까지 제한 시간
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`    // 본문 수신이 멈춘 채로 기다릴 최대 시간
+}
+
+// errIdleTimeout은 본문 수신이 IdleTimeout 동안 멈춰 요청을 중단했음을 나타냅니다.
+var errIdleTimeout = errors.New("본문 수신 정체")
+
+// statusError는 예상하지 못한 HTTP 응답 상태입니다.
+type statusError struct {
+	Code       int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP 응답 오류: %s", e.Status)
+}
+
+// setDefaults는 비어 있는 HTTP 설정에 기본값을 채웁니다.
+func (c *HTTPConfig) setDefaults() {
+	if c.Retries == 0 {
+		c.Retries = defaultRetries
+	}
+	if c.BackoffBase == 0 {
+		c.BackoffBase = defaultBackoffBase
+	}
+	if c.BackoffMax == 0 {
+		c.BackoffMax = defaultBackoffMax
+	}
+	if c.RequestTimeout == 0 {
+		c.RequestTimeout = defaultRequestTimeout
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = defaultIdleTimeout
+	}
+}
+
+// retryClient는 모든 요청이 공유하는 http.Client와 재시도 설정입니다.
+type retryClient struct {
+	*http.Client
+	cfg HTTPConfig
+}
+
+// newRetryClient는 모든 요청이 공유하는 retryClient를 만듭니다.
+// 수 GB 파일을 받는 요청도 있으므로 전체 시간 제한(Client.Timeout) 대신
+// 연결과 응답 헤더 단계에만 제한을 두고, 본문은 idleReader로 정체를 감지합니다.
+func newRetryClient(c HTTPConfig) *retryClient {
+	dialer := &net.Dialer{
+		Timeout:   c.RequestTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &retryClient{cfg: c, Client: &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   32,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   c.RequestTimeout,
+			ResponseHeaderTimeout: c.RequestTimeout,
+			ExpectContinueTimeout: time.Second,
+		},
+	}}
+}
+
+// do는 newReq로 만든 요청을 보내고, 네트워크 오류나 5xx/429 응답이면 백오프 후 재시도합니다.
+// 그 밖의 응답은 상태 코드와 관계없이 그대로 반환하므로 호출자가 상태를 확인해야 합니다.
+func (c *retryClient) do(ctx context.Context, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(req)
+		if err == nil {
+			return resp, nil
+		}
+		if err := c.backoff(ctx, attempt, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send는 req를 재시도 없이 한 번 보냅니다. 5xx/429 응답은 본문을 닫고 statusError로 반환하므로
+// 호출자가 backoff로 재시도할 수 있습니다.
+func (c *retryClient) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if retryableStatus(resp.StatusCode) {
+		resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp, nil
+}
+
+// get은 url에 GET 요청을 보내고 200 응답만 반환합니다.
+func (c *retryClient) get(ctx context.Context, url string) (*http.Response, error) {
+	resp, err := c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp, nil
+}
+
+// backoff는 attempt번째 실패(err) 뒤 재시도할 수 있으면 대기 후 nil을 반환하고,
+// 재시도할 수 없거나 횟수를 모두 쓴 경우 err를 반환합니다.
+// 대기 시간은 지수 백오프에 full jitter를 적용하며, Retry-After 응답 헤더가 더 길면 그 값을 따릅니다.
+func (c *retryClient) backoff(ctx context.Context, attempt int, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !retryable(err) {
+		return err
+	}
+	if attempt >= c.cfg.Retries {
+		return fmt.Errorf("%d회 재시도 후 실패: %w", attempt, err)
+	}
+
+	wait := c.cfg.BackoffBase << attempt
+	if wait <= 0 || wait > c.cfg.BackoffMax {
+		wait = c.cfg.BackoffMax
+	}
+	wait = rand.N(wait) + 1
+	var se *statusError
+	if errors.As(err, &se) && se.RetryAfter > wait {
+		wait = min(se.RetryAfter, c.cfg.BackoffMax)
+	}
+
+	fmt.Printf("[재시도] %v (%d/%d, %s 후)\n", err, attempt+1, c.cfg.Retries, wait.Round(time.Millisecond))
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryable은 err가 재시도로 회복될 수 있는 일시적 오류인지 판단합니다.
+// 네트워크 오류와 시간 초과, 본문 수신 중 끊김이나 정체, 5xx/429 응답만 재시도합니다.
+// 로컬 파일 오류(디스크 부족, 파일 없음 등), 크기·다이제스트 불일치, 그 밖의 응답 상태는
+// 다시 요청해도 같으므로 바로 반환합니다.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return retryableStatus(se.Code)
+	}
+	if errors.Is(err, errIdleTimeout) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	// syscall.Errno도 net.Error를 구현하므로 파일 연산 오류는 먼저 제외
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return false
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// retryableStatus는 재시도할 HTTP 상태 코드(5xx, 429)인지 판단합니다.
+// data.commoncrawl.org는 부하가 높을 때 503 SlowDown을 반환합니다.
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+func newStatusError(resp *http.Response) *statusError {
+	e := &statusError{Code: resp.StatusCode, Status: resp.Status}
+	if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+		e.RetryAfter = time.Duration(sec) * time.Second
+	}
+	return e
+}
+
+// idleBody는 응답 본문 읽기가 timeout 동안 진행되지 않으면 cancel을 호출하여 요청을 중단하고,
+// 이후 읽기 오류를 errIdleTimeout으로 바꿔 반환합니다.
+type idleBody struct {
+	body    io.ReadCloser
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	timeout time.Duration
+	expired atomic.Bool
+}
+
+func newIdleBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleBody {
+	b := &idleBody{body: body, cancel: cancel, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.expired.Store(true)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	if err != nil && err != io.EOF && b.expired.Load() {
+		err = fmt.Errorf("%s 동안 수신 없음: %w", b.timeout, errIdleTimeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.body.Close()
+}
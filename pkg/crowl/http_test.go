@@ -0,0 +1,84 @@
+package crowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503", &statusError{Code: 503, Status: "503 Service Unavailable"}, true},
+		{"429", fmt.Errorf("요청: %w", &statusError{Code: 429}), true},
+		{"404", &statusError{Code: 404, Status: "404 Not Found"}, false},
+		{"연결 끊김", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"요청 오류", &url.Error{Op: "Get", URL: "https://data.commoncrawl.org/", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, true},
+		{"시간 초과", fmt.Errorf("본문: %w", context.DeadlineExceeded), true},
+		{"본문 정체", fmt.Errorf("60s 동안 수신 없음: %w", errIdleTimeout), true},
+		{"본문 잘림", fmt.Errorf("복사: %w", io.ErrUnexpectedEOF), true},
+		{"취소", fmt.Errorf("요청: %w", context.Canceled), false},
+		{"파일 없음", &fs.PathError{Op: "open", Path: "/data/a.warc.gz", Err: syscall.ENOENT}, false},
+		{"디스크 부족", &fs.PathError{Op: "write", Path: "/tmp/a.warc.gz.part", Err: syscall.ENOSPC}, false},
+		{"크기 불일치", errors.New("다운로드 크기 불일치: 10/20 바이트"), false},
+		{"다이제스트 불일치", fmt.Errorf("%w: MD5 불일치", errCorrupted), false},
+		{"비 HTTP 소스의 4xx", fmt.Errorf("S3: %w", &statusError{Code: 403, Status: "403 Forbidden"}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// 재시도할 수 없는 오류는 한 번 시도한 뒤 그대로 반환하고, 일시적 오류는 Retries번 더 시도합니다.
+func TestBackoffAttempts(t *testing.T) {
+	c := newRetryClient(HTTPConfig{Retries: 3, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond})
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"로컬 쓰기 오류", &fs.PathError{Op: "write", Path: "a.part", Err: syscall.ENOSPC}, 1},
+		{"크기 불일치", errors.New("다운로드 크기 불일치"), 1},
+		{"본문 잘림", io.ErrUnexpectedEOF, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+			var err error
+			for attempt := 0; ; attempt++ {
+				attempts++
+				if err = c.backoff(context.Background(), attempt, tt.err); err != nil {
+					break
+				}
+			}
+			if attempts != tt.attempts || !errors.Is(err, tt.err) {
+				t.Errorf("시도 %d회, 오류 %v, want %d회", attempts, err, tt.attempts)
+			}
+		})
+	}
+}
+
+// file:// 소스에 없는 목록 파일은 재시도 대기 없이 바로 실패해야 합니다.
+func TestGetPathsMissingNoRetry(t *testing.T) {
+	cc := newTestCommonCrawl(t, "file://"+t.TempDir()+"/", HTTPConfig{Retries: 3, BackoffBase: time.Hour, BackoffMax: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := cc.getPaths(ctx, "crawl-data/CC-NEWS/2025/04/warc.paths.gz"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("오류 = %v, want os.ErrNotExist", err)
+	}
+}
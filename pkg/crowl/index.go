@@ -0,0 +1,61 @@
+package crowl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"parkjunwoo.com/crowl/pkg/cdxj"
+)
+
+// monthIndexName은 크롤 저장 디렉토리(CC-NEWS는 월 단위) 하나로 병합된 인덱스 파일 이름입니다.
+const monthIndexName = "index.cdxj"
+
+// MergeIndex는 crawl의 저장 디렉토리 아래 파일별 CDXJ 인덱스를 하나의 정렬된 index.cdxj로 병합합니다.
+func (cc *CommonCrawl) MergeIndex(crawl Crawl) error {
+	return cc.mergeIndex(crawl.SaveDir(cc.DataDir))
+}
+
+// mergeIndex는 saveDir 아래 파일별 CDXJ 인덱스를 index.cdxj로 병합합니다.
+func (cc *CommonCrawl) mergeIndex(saveDir string) error {
+	matches, err := filepath.Glob(filepath.Join(saveDir, "*.cdxj"))
+	if err != nil {
+		return err
+	}
+
+	var srcs []string
+	for _, m := range matches {
+		if filepath.Base(m) != monthIndexName {
+			srcs = append(srcs, m)
+		}
+	}
+	if len(srcs) == 0 {
+		return nil
+	}
+
+	if err := cdxj.Merge(filepath.Join(saveDir, monthIndexName), srcs); err != nil {
+		return fmt.Errorf("인덱스 병합 오류: %w", err)
+	}
+	fmt.Printf("[인덱스] %d개 파일 병합 완료: %s\n", len(srcs), filepath.Join(saveDir, monthIndexName))
+	return nil
+}
+
+// LookupURL은 crawl의 병합 인덱스에서 URL과 일치하는 항목을 찾습니다.
+func (cc *CommonCrawl) LookupURL(crawl Crawl, rawURL string) ([]cdxj.Entry, error) {
+	return cc.lookupIndex(crawl, cdxj.SURT(rawURL)+" ")
+}
+
+// LookupDomain은 crawl의 병합 인덱스에서 해당 호스트의 모든 항목을 찾습니다.
+func (cc *CommonCrawl) LookupDomain(crawl Crawl, host string) ([]cdxj.Entry, error) {
+	key := cdxj.SURT("http://" + host + "/")
+	return cc.lookupIndex(crawl, strings.TrimSuffix(key, "/"))
+}
+
+func (cc *CommonCrawl) lookupIndex(crawl Crawl, prefix string) ([]cdxj.Entry, error) {
+	indexPath := filepath.Join(crawl.SaveDir(cc.DataDir), monthIndexName)
+	if _, err := os.Stat(indexPath); err != nil {
+		return nil, fmt.Errorf("%s 병합 인덱스 없음(%s): %w", crawl.ID, indexPath, err)
+	}
+	return cdxj.Lookup(indexPath, prefix)
+}
@@ -0,0 +1,56 @@
+package crowl
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// errCorrupted는 다운로드하거나 파싱한 파일의 무결성 검증에 실패했음을 나타냅니다.
+// 손상된 파일은 상태 저장소에 Corrupted로 표시된 실패 상태로 남습니다.
+var errCorrupted = errors.New("파일 손상")
+
+// expectedMD5는 HEAD 응답의 Content-MD5 또는 ETag에서 파일 전체의 MD5(hex)를 구합니다.
+// S3 멀티파트 업로드의 ETag("<hex>-N")는 파일 MD5가 아니므로 빈 문자열을 반환합니다.
+func expectedMD5(h http.Header) string {
+	if v := h.Get("Content-MD5"); v != "" {
+		if sum, err := base64.StdEncoding.DecodeString(v); err == nil && len(sum) == md5.Size {
+			return hex.EncodeToString(sum)
+		}
+	}
+	etag := strings.Trim(strings.TrimPrefix(h.Get("ETag"), "W/"), `"`)
+	if len(etag) != hex.EncodedLen(md5.Size) {
+		return ""
+	}
+	if _, err := hex.DecodeString(etag); err != nil {
+		return ""
+	}
+	return strings.ToLower(etag)
+}
+
+// verifyMD5는 path 파일의 MD5가 want(hex)와 같은지 확인합니다. want가 비어 있으면 검증하지 않습니다.
+func verifyMD5(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("%w: MD5 불일치 (기대값 %s, 계산값 %s)", errCorrupted, want, got)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package crowl
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"parkjunwoo.com/crowl/pkg/langid"
+)
+
+// languageUnknown은 언어를 판별하지 못한 레코드의 언어별 출력 파일 이름에 쓰는 코드입니다 (ISO 639-2 und).
+const languageUnknown = "und"
+
+// LanguageConfig는 추출한 본문 텍스트의 언어 판별 설정입니다.
+// Keep이나 Split을 설정하면 Enabled가 없어도 언어를 판별합니다.
+type LanguageConfig struct {
+	Enabled bool     `yaml:"enabled"` // 레코드에 언어(lang)와 신뢰도(lang_confidence) 기록
+	Keep    []string `yaml:"keep"`    // 남길 언어 코드 (ISO 639-1), 비어 있으면 전부
+	Split   bool     `yaml:"split"`   // 언어별로 출력 파일을 나눠 기록 (x.ko.jsonl.gz, 판별 실패는 x.und.jsonl.gz)
+}
+
+func (l *LanguageConfig) setDefaults(mode string) error {
+	if len(l.Keep) > 0 || l.Split {
+		l.Enabled = true
+	}
+	if !l.Enabled {
+		return nil
+	}
+	if mode == KindWat {
+		return errors.New("wat 모드는 본문 텍스트가 없어 언어를 판별할 수 없습니다")
+	}
+	for i, lang := range l.Keep {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if !langid.Supported(lang) {
+			return fmt.Errorf("지원하지 않는 언어 코드: %s (지원: %s)", lang, strings.Join(langid.Languages(), ", "))
+		}
+		l.Keep[i] = lang
+	}
+	return nil
+}
+
+// identify는 rec의 본문 텍스트 언어를 판별하여 기록합니다. Keep에 없는 언어면 errFiltered를 반환합니다.
+func (l LanguageConfig) identify(rec *Record) error {
+	if !l.Enabled {
+		return nil
+	}
+	res := langid.Detect(rec.plain)
+	rec.Lang, rec.LangConfidence = res.Lang, res.Confidence
+	if len(l.Keep) > 0 && !slices.Contains(l.Keep, res.Lang) {
+		return fmt.Errorf("%w: 언어 %s", errFiltered, cmp.Or(res.Lang, languageUnknown))
+	}
+	return nil
+}
+
+// languagePath는 path의 출력 확장자 앞에 언어 코드를 붙인 언어별 출력 파일 경로입니다.
+// 예: x.jsonl.gz, ko → x.ko.jsonl.gz
+func languagePath(path, lang string) string {
+	base := trimOutputExt(path)
+	return base + "." + cmp.Or(lang, languageUnknown) + path[len(base):]
+}
+
+// languageOutput은 레코드를 언어별 출력 파일로 나눠 기록하는 recordOutput입니다.
+// 언어별 출력은 처음 레코드가 들어올 때 createOutput으로 만듭니다.
+type languageOutput struct {
+	path  string
+	cfg   OutputConfig
+	info  OutputInfo
+	outs  map[string]recordOutput
+	order []string // 언어별 출력 생성 순서 (paths 출력 순서)
+}
+
+func newLanguageOutput(path string, cfg OutputConfig, info OutputInfo) *languageOutput {
+	return &languageOutput{path: path, cfg: cfg, info: info, outs: map[string]recordOutput{}}
+}
+
+func (o *languageOutput) Write(rec *Record) (int64, int64, error) {
+	lang := cmp.Or(rec.Lang, languageUnknown)
+	out, ok := o.outs[lang]
+	if !ok {
+		var err error
+		out, err = createOutput(languagePath(o.path, rec.Lang), o.cfg, o.info)
+		if err != nil {
+			return 0, 0, err
+		}
+		o.outs[lang] = out
+		o.order = append(o.order, lang)
+	}
+	return out.Write(rec)
+}
+
+func (o *languageOutput) Close() error {
+	var firstErr error
+	for _, lang := range o.order {
+		if err := o.outs[lang].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (o *languageOutput) commit() error {
+	for _, lang := range o.order {
+		if err := o.outs[lang].commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *languageOutput) abort() {
+	for _, out := range o.outs {
+		out.abort()
+	}
+}
+
+func (o *languageOutput) paths() []string {
+	var paths []string
+	for _, lang := range o.order {
+		paths = append(paths, o.outs[lang].paths()...)
+	}
+	return paths
+}
@@ -0,0 +1,375 @@
+package crowl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"parkjunwoo.com/crowl/internal/iox"
+	"parkjunwoo.com/crowl/pkg/article"
+	"parkjunwoo.com/crowl/pkg/parquet"
+	"parkjunwoo.com/crowl/pkg/wrc"
+)
+
+// 출력 형식
+const (
+	FormatWRC     = "wrc"     // url, 길이, 본문을 줄 단위로 적은 텍스트 프레임 (.wrc.gz)
+	FormatJSONL   = "jsonl"   // Record 스키마의 JSON 한 줄 (.jsonl.gz, .jsonl.zst)
+	FormatParquet = "parquet" // Record 스키마의 열 기반 파일 (.parquet, 파티션 디렉토리별)
+)
+
+// 출력 압축 방식
+const (
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy" // parquet 전용
+	CompressionNone   = "none"   // parquet 전용
+)
+
+// 형식별로 사용할 수 있는 압축 방식 (첫 번째가 기본값)
+var formatCompressions = map[string][]string{
+	FormatWRC:     {CompressionGzip},
+	FormatJSONL:   {CompressionGzip, CompressionZstd},
+	FormatParquet: {CompressionSnappy, CompressionZstd, CompressionGzip, CompressionNone},
+}
+
+// parquet 파티션 키
+const (
+	PartitionYear  = "year"
+	PartitionMonth = "month"
+	PartitionHost  = "host"
+)
+
+// OutputConfig는 처리 결과 레코드의 출력 형식 설정입니다.
+type OutputConfig struct {
+	Format       string   `yaml:"format"`         // wrc, jsonl, parquet
+	Compression  string   `yaml:"compression"`    // wrc: gzip, jsonl: gzip|zstd, parquet: snappy|zstd|gzip|none
+	RowGroupSize int64    `yaml:"row_group_size"` // parquet 행 그룹 크기 (압축 전 바이트, 기본 64 MiB)
+	PartitionBy  []string `yaml:"partition_by"`   // parquet 파티션 디렉토리 키 순서: year, month, host
+}
+
+// setDefaults는 빈 값을 기본값(wrc, 형식별 기본 압축)으로 채우고 알 수 없는 값이면 오류를 반환합니다.
+func (o *OutputConfig) setDefaults() error {
+	if o.Format == "" {
+		o.Format = FormatWRC
+	}
+	compressions, ok := formatCompressions[o.Format]
+	if !ok {
+		return fmt.Errorf("알 수 없는 출력 형식: %s", o.Format)
+	}
+
+	if o.Compression == "" {
+		o.Compression = compressions[0]
+	}
+	if !slices.Contains(compressions, o.Compression) {
+		return fmt.Errorf("%s 형식에서 지원하지 않는 압축 방식: %s", o.Format, o.Compression)
+	}
+
+	if o.RowGroupSize <= 0 {
+		o.RowGroupSize = parquet.DefaultRowGroupSize
+	}
+	for _, key := range o.PartitionBy {
+		switch key {
+		case PartitionYear, PartitionMonth, PartitionHost:
+		default:
+			return fmt.Errorf("알 수 없는 파티션 키: %s", key)
+		}
+	}
+	return nil
+}
+
+// Ext는 출력 파일 확장자입니다.
+func (o OutputConfig) Ext() string {
+	switch o.Format {
+	case FormatJSONL:
+		if o.Compression == CompressionZstd {
+			return ".jsonl.zst"
+		}
+		return ".jsonl.gz"
+	case FormatParquet:
+		return ".parquet"
+	}
+	return ".wrc.gz"
+}
+
+// outputExts는 출력 파일 경로에서 떼어낼 수 있는 확장자 목록입니다.
+var outputExts = []string{".wrc.gz", ".jsonl.gz", ".jsonl.zst", ".parquet"}
+
+// trimOutputExt는 출력 파일 경로에서 출력 확장자를 떼어 .cdxj, .warc.gz 등 부속 파일의 기준 경로를 만듭니다.
+func trimOutputExt(path string) string {
+	for _, ext := range outputExts {
+		if base, ok := strings.CutSuffix(path, ext); ok {
+			return base
+		}
+	}
+	return path
+}
+
+// Record는 처리된 레코드 하나의 출력 스키마입니다 (schema/record.schema.json).
+// 처리 모드에 따라 HTML(warc), Text(wet, readability), WAT(wat) 중 해당하는 필드가 채워집니다.
+type Record struct {
+	URL            string            `json:"url"`
+	CanonicalURL   string            `json:"canonical_url,omitempty"` // 정규 URL (url 설정, canonical이면 <link rel=canonical>)
+	SURT           string            `json:"surt,omitempty"`          // 정규 URL의 SURT 키
+	Date           string            `json:"warc_date,omitempty"`     // 원본 WARC-Date (RFC3339)
+	RecordID       string            `json:"record_id,omitempty"`     // 원본 WARC-Record-ID
+	Status         int               `json:"status,omitempty"`        // HTTP 응답 상태 코드
+	ContentType    string            `json:"content_type,omitempty"`
+	Charset        string            `json:"charset,omitempty"`         // 판별한 원본 문자 인코딩 (warc), 본문은 UTF-8로 변환됨
+	HTML           string            `json:"html,omitempty"`            // 정제된 HTML 또는 기사 본문 영역 HTML
+	Text           string            `json:"text,omitempty"`            // WET 텍스트 또는 기사 본문 텍스트
+	Lang           string            `json:"lang,omitempty"`            // 본문 텍스트의 언어 (ISO 639-1, language 설정)
+	LangConfidence float64           `json:"lang_confidence,omitempty"` // 언어 판별 신뢰도 (0~1)
+	Cluster        string            `json:"dup_cluster,omitempty"`     // 중복 판정 클러스터 id (dedup 설정)
+	Duplicate      string            `json:"duplicate,omitempty"`       // 중복이면 exact 또는 near (dedup annotate)
+	Metadata       *article.Metadata `json:"metadata,omitempty"`
+	WAT            *WatRecord        `json:"wat,omitempty"`
+	Label          string            `json:"label,omitempty"` // 뉴스 판별 모델의 판정 (validate)
+
+	content   []byte // wrc 형식과 WARC conversion 레코드에 기록하는 본문 (비어 있으면 HTML, Text 순)
+	plain     string // 언어 판별과 중복 판정에 쓰는 본문 텍스트
+	canonical string // 문서의 <link rel=canonical>을 정규화한 URL (url.canonical 설정)
+}
+
+// body는 wrc 형식으로 기록할 본문입니다.
+func (r *Record) body() []byte {
+	switch {
+	case r.content != nil:
+		return r.content
+	case r.HTML != "":
+		return []byte(r.HTML)
+	}
+	return []byte(r.Text)
+}
+
+// RecordWriter는 처리 결과 레코드를 출력 형식에 맞게 기록합니다.
+// wrc와 jsonl은 레코드마다 독립된 압축 단위(gzip 멤버, zstd 프레임)로 기록하여
+// CDXJ 인덱스의 오프셋과 길이로 레코드 하나만 읽을 수 있게 합니다.
+type RecordWriter interface {
+	// Write는 레코드 하나를 기록하고 압축된 단위의 오프셋과 길이를 반환합니다.
+	// 레코드 단위로 읽을 수 없는 형식(parquet)은 오프셋과 길이로 -1을 반환합니다.
+	Write(rec *Record) (offset, length int64, err error)
+	// Close는 남은 내용을 기록하고 압축기를 정리합니다. 기반 io.Writer는 닫지 않습니다.
+	Close() error
+}
+
+// OutputInfo는 출력 파일에 함께 기록하는 출처 정보입니다 (wrc 헤더, parquet 파일 메타데이터).
+type OutputInfo struct {
+	Source      string // 원본 WARC/WET/WAT 파일 이름
+	Fingerprint string // 처리 설정의 지문
+}
+
+// NewRecordWriter는 cfg의 형식과 압축 방식으로 w에 기록하는 RecordWriter를 만듭니다.
+// wrc는 info를 담은 헤더부터 기록하고, parquet은 파티션 없이 파일 하나로 기록합니다.
+func NewRecordWriter(w io.Writer, cfg OutputConfig, info OutputInfo) (RecordWriter, error) {
+	switch cfg.Format {
+	case FormatParquet:
+		return newParquetWriter(w, cfg, info)
+	case FormatJSONL:
+		mw, err := newMemberWriter(w, cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonlWriter{mw: mw}, nil
+	}
+
+	ww, err := wrc.NewWriter(w, wrc.Header{Source: info.Source, Crowl: Version, Fingerprint: info.Fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	return &wrcWriter{w: ww}, nil
+}
+
+// recordOutput은 출력 파일(들)에 대한 RecordWriter입니다.
+// commit 전까지는 .part 임시 파일에 기록하므로 중단되어도 불완전한 출력이 남지 않습니다.
+type recordOutput interface {
+	RecordWriter
+	// commit은 남은 내용을 기록하고 임시 파일을 최종 경로로 옮깁니다.
+	commit() error
+	// abort는 commit되지 않은 임시 파일을 지웁니다. commit 이후 호출하면 아무 일도 하지 않습니다.
+	abort()
+	// paths는 기록한 출력 파일 경로 목록입니다.
+	paths() []string
+}
+
+// createOutput은 cfg 형식으로 path에 기록하는 recordOutput을 만듭니다. info는 출력 파일에 출처 정보로 기록합니다.
+// parquet 형식은 PartitionBy에 따라 path의 디렉토리 아래 파티션 디렉토리마다 같은 이름의 파일을 만듭니다.
+func createOutput(path string, cfg OutputConfig, info OutputInfo) (recordOutput, error) {
+	if cfg.Format == FormatParquet {
+		return newPartitionedOutput(path, cfg, info), nil
+	}
+
+	f, err := createAtomic(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewRecordWriter(f, cfg, info)
+	if err != nil {
+		f.abort()
+		return nil, err
+	}
+	return &fileOutput{RecordWriter: w, f: f}, nil
+}
+
+// fileOutput은 파일 하나에 기록하는 recordOutput입니다.
+type fileOutput struct {
+	RecordWriter
+	f *atomicFile
+}
+
+func (o *fileOutput) commit() error {
+	if err := o.Close(); err != nil {
+		return err
+	}
+	return o.f.commit()
+}
+
+func (o *fileOutput) abort() {
+	o.Close()
+	o.f.abort()
+}
+
+func (o *fileOutput) paths() []string {
+	return []string{o.f.path}
+}
+
+// memberWriter는 바이트 묶음 하나를 독립된 gzip 멤버나 zstd 프레임으로 압축하여 기록합니다.
+type memberWriter struct {
+	w      io.Writer
+	gw     *gzip.Writer
+	zw     *zstd.Encoder
+	offset int64
+}
+
+func newMemberWriter(w io.Writer, compression string) (*memberWriter, error) {
+	mw := &memberWriter{w: w}
+	if compression == CompressionZstd {
+		zw, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		mw.zw = zw
+	} else {
+		mw.gw = gzip.NewWriter(w)
+	}
+	return mw, nil
+}
+
+// write는 p를 압축 단위 하나로 기록하고 그 오프셋과 길이를 반환합니다.
+func (mw *memberWriter) write(p []byte) (int64, int64, error) {
+	cw := &iox.CountWriter{W: mw.w}
+	if mw.zw != nil {
+		if _, err := cw.Write(mw.zw.EncodeAll(p, nil)); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		mw.gw.Reset(cw)
+		if _, err := mw.gw.Write(p); err != nil {
+			return 0, 0, err
+		}
+		if err := mw.gw.Close(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	offset := mw.offset
+	mw.offset += cw.N
+	return offset, cw.N, nil
+}
+
+func (mw *memberWriter) close() error {
+	if mw.zw != nil {
+		return mw.zw.Close()
+	}
+	return nil
+}
+
+// wrcWriter는 Record를 wrc v2 파일의 레코드로 기록합니다 (pkg/wrc).
+// 판별 결과(Label)가 있으면 url 다음 줄에 함께 기록합니다.
+type wrcWriter struct {
+	w *wrc.Writer
+}
+
+func (ww *wrcWriter) Write(rec *Record) (int64, int64, error) {
+	offset, length, err := ww.w.Write(&wrc.Record{URL: rec.URL, Label: rec.Label, Content: rec.body()})
+	if err != nil {
+		return 0, 0, fmt.Errorf("writeWRC 오류(URL: %s): %w", rec.URL, err)
+	}
+	return offset, length, nil
+}
+
+func (ww *wrcWriter) Close() error {
+	return ww.w.Close()
+}
+
+// jsonlWriter는 Record를 JSON 한 줄로 기록합니다.
+type jsonlWriter struct {
+	mw *memberWriter
+}
+
+func (jw *jsonlWriter) Write(rec *Record) (int64, int64, error) {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(rec); err != nil {
+		return 0, 0, fmt.Errorf("JSONL 인코딩 오류(URL: %s): %w", rec.URL, err)
+	}
+
+	offset, length, err := jw.mw.write([]byte(buf.String()))
+	if err != nil {
+		return 0, 0, fmt.Errorf("JSONL 기록 오류(URL: %s): %w", rec.URL, err)
+	}
+	return offset, length, nil
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.mw.close()
+}
+
+// jsonlReader는 gzip 멤버나 zstd 프레임이 이어진 JSONL 출력 파일에서 Record를 차례로 읽습니다.
+type jsonlReader struct {
+	rc  io.ReadCloser
+	dec *json.Decoder
+}
+
+// newJSONLReader는 경로의 확장자(.jsonl.gz, .jsonl.zst)로 압축 방식을 정하여 r을 읽습니다.
+func newJSONLReader(r io.Reader, path string) (*jsonlReader, error) {
+	var rc io.ReadCloser
+	if strings.HasSuffix(path, ".zst") {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rc = zr.IOReadCloser()
+	} else {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rc = gr
+	}
+	return &jsonlReader{rc: rc, dec: json.NewDecoder(bufio.NewReader(rc))}, nil
+}
+
+// Next는 다음 레코드를 반환합니다. 더 이상 레코드가 없으면 io.EOF입니다.
+func (jr *jsonlReader) Next() (*Record, error) {
+	var rec Record
+	if err := jr.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (jr *jsonlReader) Close() error {
+	return jr.rc.Close()
+}
+
+// isJSONL은 path가 JSONL 출력 파일인지 확인합니다.
+func isJSONL(path string) bool {
+	return strings.HasSuffix(path, ".jsonl.gz") || strings.HasSuffix(path, ".jsonl.zst")
+}
@@ -0,0 +1,347 @@
+package crowl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"parkjunwoo.com/crowl/pkg/parquet"
+)
+
+// recordColumns는 Record를 parquet으로 기록할 때의 열 스키마입니다 (schema/record.schema.json과 같은 이름).
+// metadata와 wat은 JSON 문서 열로 기록합니다.
+var recordColumns = []parquet.Column{
+	{Name: "url", Type: parquet.ByteArray, Logical: parquet.String, Required: true},
+	{Name: "canonical_url", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "surt", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "warc_date", Type: parquet.Int64, Logical: parquet.TimestampMillis},
+	{Name: "record_id", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "status", Type: parquet.Int32},
+	{Name: "content_type", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "charset", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "html", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "text", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "lang", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "lang_confidence", Type: parquet.Double},
+	{Name: "dup_cluster", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "duplicate", Type: parquet.ByteArray, Logical: parquet.String},
+	{Name: "metadata", Type: parquet.ByteArray, Logical: parquet.JSON},
+	{Name: "wat", Type: parquet.ByteArray, Logical: parquet.JSON},
+	{Name: "label", Type: parquet.ByteArray, Logical: parquet.String},
+}
+
+// parquetCodecs는 출력 압축 방식에 해당하는 parquet 페이지 압축 방식입니다.
+var parquetCodecs = map[string]parquet.Codec{
+	CompressionSnappy: parquet.Snappy,
+	CompressionZstd:   parquet.Zstd,
+	CompressionGzip:   parquet.Gzip,
+	CompressionNone:   parquet.Uncompressed,
+}
+
+// parquetWriter는 Record를 parquet 행으로 기록합니다.
+// 행 그룹 단위로 압축되므로 레코드 하나의 오프셋과 길이는 없습니다 (-1).
+type parquetWriter struct {
+	pw *parquet.Writer
+}
+
+// newParquetWriter는 info와 crowl 버전을 파일 메타데이터(crowl.source, crowl.version, crowl.fingerprint)로 기록하는 parquetWriter를 만듭니다.
+func newParquetWriter(w io.Writer, cfg OutputConfig, info OutputInfo) (*parquetWriter, error) {
+	kv := map[string]string{"crowl.version": Version}
+	if info.Source != "" {
+		kv["crowl.source"] = info.Source
+	}
+	if info.Fingerprint != "" {
+		kv["crowl.fingerprint"] = info.Fingerprint
+	}
+	pw, err := parquet.NewWriter(w, recordColumns, parquet.Options{
+		Codec:        parquetCodecs[cfg.Compression],
+		RowGroupSize: cfg.RowGroupSize,
+		CreatedBy:    "crowl version " + Version,
+		KeyValue:     kv,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{pw: pw}, nil
+}
+
+func (p *parquetWriter) Write(rec *Record) (int64, int64, error) {
+	row, err := parquetRow(rec)
+	if err == nil {
+		err = p.pw.Write(row)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("parquet 기록 오류(URL: %s): %w", rec.URL, err)
+	}
+	return -1, -1, nil
+}
+
+func (p *parquetWriter) Close() error {
+	return p.pw.Close()
+}
+
+// parquetRow는 Record를 recordColumns 순서의 값으로 바꿉니다. 빈 값은 null로 기록합니다.
+func parquetRow(rec *Record) ([]any, error) {
+	var date, status, confidence, metadata, wat any
+	if t, err := time.Parse(time.RFC3339, rec.Date); err == nil {
+		date = t
+	}
+	if rec.Status != 0 {
+		status = rec.Status
+	}
+	if rec.Lang != "" {
+		confidence = rec.LangConfidence
+	}
+	if rec.Metadata != nil {
+		b, err := json.Marshal(rec.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		metadata = b
+	}
+	if rec.WAT != nil {
+		b, err := json.Marshal(rec.WAT)
+		if err != nil {
+			return nil, err
+		}
+		wat = b
+	}
+	return []any{rec.URL, optional(rec.CanonicalURL), optional(rec.SURT), date, optional(rec.RecordID), status,
+		optional(rec.ContentType), optional(rec.Charset), optional(rec.HTML), optional(rec.Text),
+		optional(rec.Lang), confidence, optional(rec.Cluster), optional(rec.Duplicate),
+		metadata, wat, optional(rec.Label)}, nil
+}
+
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// partitionDefault는 파티션 값을 알 수 없는 레코드의 디렉토리 값입니다 (Hive 관례).
+const partitionDefault = "__HIVE_DEFAULT_PARTITION__"
+
+// partitionedOutput은 레코드를 PartitionBy 키의 key=value 디렉토리별 parquet 파일로 나눠 기록합니다.
+// 파티션이 수천 개(host)여도 열린 파일 수와 메모리가 늘지 않도록, 레코드는 파티션별로 모아 두었다가
+// 모은 크기가 RowGroupSize에 이르면 그 파티션 파일에 행 그룹 하나로 기록하고 파일을 바로 닫습니다.
+// 모든 파티션이 메모리에 모은 크기의 합이 RowGroupSize를 넘으면 모은 레코드를 파티션별 spill 파일로 옮깁니다.
+// 크기는 레코드의 JSON 인코딩 길이로 셉니다.
+type partitionedOutput struct {
+	cfg      OutputConfig
+	info     OutputInfo
+	dir      string // 파티션 디렉토리의 기준 디렉토리
+	name     string // 파티션마다 같은 파일 이름
+	parts    map[string]*partition
+	order    []string // 파티션 생성 순서 (paths 출력 순서)
+	buffered int64    // 모든 파티션이 메모리에 모은 크기
+}
+
+type partition struct {
+	f       *reopenFile
+	w       *parquetWriter // 처음 행 그룹을 기록할 때 생성
+	pending bytes.Buffer   // 메모리에 모은 레코드 (JSON 줄)
+	spilled int64          // spill 파일(path.spill)에 모은 크기
+}
+
+func newPartitionedOutput(path string, cfg OutputConfig, info OutputInfo) *partitionedOutput {
+	return &partitionedOutput{
+		cfg:   cfg,
+		info:  info,
+		dir:   filepath.Dir(path),
+		name:  filepath.Base(path),
+		parts: map[string]*partition{},
+	}
+}
+
+// partitionDir은 rec이 속할 파티션의 상대 디렉토리입니다 (예: year=2024/month=05/host=example.com).
+func (o *partitionedOutput) partitionDir(rec *Record) string {
+	date, dateErr := time.Parse(time.RFC3339, rec.Date)
+	dirs := make([]string, 0, len(o.cfg.PartitionBy))
+	for _, key := range o.cfg.PartitionBy {
+		value := partitionDefault
+		switch key {
+		case PartitionYear:
+			if dateErr == nil {
+				value = fmt.Sprintf("%04d", date.UTC().Year())
+			}
+		case PartitionMonth:
+			if dateErr == nil {
+				value = fmt.Sprintf("%02d", date.UTC().Month())
+			}
+		case PartitionHost:
+			if u, err := url.Parse(rec.URL); err == nil && u.Hostname() != "" {
+				value = url.PathEscape(strings.ToLower(u.Hostname()))
+			}
+		}
+		dirs = append(dirs, key+"="+value)
+	}
+	return filepath.Join(dirs...)
+}
+
+func (o *partitionedOutput) Write(rec *Record) (int64, int64, error) {
+	key := o.partitionDir(rec)
+	p, ok := o.parts[key]
+	if !ok {
+		path := filepath.Join(o.dir, key, o.name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return 0, 0, err
+		}
+		p = &partition{f: &reopenFile{path: path}}
+		o.parts[key] = p
+		o.order = append(o.order, key)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parquet 기록 오류(URL: %s): %w", rec.URL, err)
+	}
+	p.pending.Write(append(b, '\n'))
+	o.buffered += int64(len(b) + 1)
+
+	if int64(p.pending.Len())+p.spilled >= o.cfg.RowGroupSize {
+		if err := o.flush(p); err != nil {
+			return 0, 0, err
+		}
+	}
+	if o.buffered > o.cfg.RowGroupSize {
+		if err := o.spillAll(); err != nil {
+			return 0, 0, err
+		}
+	}
+	return -1, -1, nil
+}
+
+// spillAll은 모든 파티션이 메모리에 모은 레코드를 파티션별 spill 파일 끝에 덧붙입니다.
+func (o *partitionedOutput) spillAll() error {
+	for _, p := range o.parts {
+		if p.pending.Len() == 0 {
+			continue
+		}
+		f, err := os.OpenFile(p.f.path+".spill", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		n, err := f.Write(p.pending.Bytes())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("parquet spill 기록 오류: %w", err)
+		}
+		p.spilled += int64(n)
+		o.buffered -= int64(n)
+		p.pending.Reset()
+	}
+	return nil
+}
+
+// flush는 p의 spill 파일과 메모리에 모은 레코드를 행 그룹 하나로 기록하고 파티션 파일을 닫습니다.
+func (o *partitionedOutput) flush(p *partition) error {
+	if p.spilled == 0 && p.pending.Len() == 0 {
+		return nil
+	}
+	if p.w == nil {
+		// 행 그룹은 partitionedOutput이 나누므로 parquetWriter가 스스로 행 그룹을 닫지 않게 함
+		cfg := o.cfg
+		cfg.RowGroupSize = math.MaxInt64
+		w, err := newParquetWriter(p.f, cfg, o.info)
+		if err != nil {
+			return err
+		}
+		p.w = w
+	}
+
+	if p.spilled > 0 {
+		spill, err := os.Open(p.f.path + ".spill")
+		if err != nil {
+			return err
+		}
+		err = writeJSONRecords(p.w, spill)
+		spill.Close()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(spill.Name()); err != nil {
+			return err
+		}
+		p.spilled = 0
+	}
+	if err := writeJSONRecords(p.w, &p.pending); err != nil {
+		return err
+	}
+	o.buffered -= int64(p.pending.Len())
+	p.pending.Reset()
+
+	if err := p.w.pw.Flush(); err != nil {
+		return err
+	}
+	return p.f.release()
+}
+
+// writeJSONRecords는 r의 JSON 레코드를 모두 w에 기록합니다.
+func writeJSONRecords(w *parquetWriter, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("parquet 파티션 버퍼 읽기 오류: %w", err)
+		}
+		if _, _, err := w.Write(&rec); err != nil {
+			return err
+		}
+	}
+}
+
+// Close는 모은 레코드를 모두 기록하고 모든 파티션 파일의 footer를 기록합니다. 파일은 commit에서 확정합니다.
+func (o *partitionedOutput) Close() error {
+	for _, key := range o.order {
+		p := o.parts[key]
+		if err := o.flush(p); err != nil {
+			return err
+		}
+		if err := p.w.Close(); err != nil {
+			return err
+		}
+		if err := p.f.release(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *partitionedOutput) commit() error {
+	if err := o.Close(); err != nil {
+		return err
+	}
+	for _, key := range o.order {
+		if err := o.parts[key].f.commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *partitionedOutput) abort() {
+	for _, p := range o.parts {
+		p.f.abort()
+		os.Remove(p.f.path + ".spill")
+	}
+}
+
+func (o *partitionedOutput) paths() []string {
+	paths := make([]string, 0, len(o.order))
+	for _, key := range o.order {
+		paths = append(paths, o.parts[key].f.path)
+	}
+	return paths
+}
@@ -0,0 +1,101 @@
+package crowl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openFiles는 현재 프로세스의 열린 파일 수입니다. /proc이 없으면 -1입니다.
+func openFiles() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+func TestPartitionedOutputManyHosts(t *testing.T) {
+	dir := t.TempDir()
+	cfg := OutputConfig{Format: FormatParquet, RowGroupSize: 4 << 10, PartitionBy: []string{PartitionHost}}
+	if err := cfg.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	out := newPartitionedOutput(filepath.Join(dir, "a.parquet"), cfg, OutputInfo{Source: "a.warc.gz"})
+	defer out.abort()
+
+	const hosts, perHost = 500, 6
+	before := openFiles()
+	for i := 0; i < perHost; i++ {
+		for h := 0; h < hosts; h++ {
+			rec := &Record{
+				URL:  fmt.Sprintf("https://h%d.example.com/%d", h, i),
+				Date: "2025-04-01T00:00:00Z",
+				HTML: strings.Repeat("x", 200),
+			}
+			if _, _, err := out.Write(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if before >= 0 {
+		if after := openFiles(); after-before > 2 {
+			t.Errorf("파티션 기록 중 열린 파일: %d → %d", before, after)
+		}
+	}
+	if out.buffered > cfg.RowGroupSize {
+		t.Errorf("메모리 버퍼 %d > %d", out.buffered, cfg.RowGroupSize)
+	}
+
+	if err := out.commit(); err != nil {
+		t.Fatal(err)
+	}
+	paths := out.paths()
+	if len(paths) != hosts {
+		t.Fatalf("파티션 파일 %d개, 기대값 %d", len(paths), hosts)
+	}
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+			t.Errorf("%s: parquet magic 없음", p)
+		}
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "host=*", "*.*.*"))
+	for _, p := range leftovers {
+		if strings.HasSuffix(p, ".part") || strings.HasSuffix(p, ".spill") {
+			t.Errorf("임시 파일이 남음: %s", p)
+		}
+	}
+}
+
+func TestPartitionedOutputAbort(t *testing.T) {
+	dir := t.TempDir()
+	cfg := OutputConfig{Format: FormatParquet, RowGroupSize: 512, PartitionBy: []string{PartitionYear, PartitionHost}}
+	if err := cfg.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	out := newPartitionedOutput(filepath.Join(dir, "a.parquet"), cfg, OutputInfo{})
+	for i := 0; i < 50; i++ {
+		rec := &Record{URL: fmt.Sprintf("https://h%d.example.com/", i%7), Date: "2025-04-01T00:00:00Z", Text: strings.Repeat("y", 100)}
+		if _, _, err := out.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out.abort()
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("abort 후 파일이 남음: %s", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package crowl
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// progress는 여러 달에 걸친 파일 처리 현황을 한 곳에서 집계합니다.
+type progress struct {
+	total     int64
+	done      int64
+	failed    int64
+	skipped   int64
+	corrupted int64 // failed에 포함
+}
+
+func (p *progress) complete() {
+	atomic.AddInt64(&p.done, 1)
+	p.report()
+}
+
+func (p *progress) fail() {
+	atomic.AddInt64(&p.failed, 1)
+	p.report()
+}
+
+// corrupt는 무결성 검증에 실패한 파일을 실패로 집계합니다.
+func (p *progress) corrupt() {
+	atomic.AddInt64(&p.corrupted, 1)
+	p.fail()
+}
+
+func (p *progress) skip() {
+	atomic.AddInt64(&p.skipped, 1)
+}
+
+// report는 현재까지의 전체 진행 상황을 출력합니다.
+func (p *progress) report() {
+	done := atomic.LoadInt64(&p.done)
+	failed := atomic.LoadInt64(&p.failed)
+	skipped := atomic.LoadInt64(&p.skipped)
+	corrupted := atomic.LoadInt64(&p.corrupted)
+	fmt.Printf("[전체 진행] %d/%d 완료 (실패 %d, 손상 %d, 스킵 %d)\n", done+skipped, p.total, failed, corrupted, skipped)
+}
@@ -0,0 +1,231 @@
+package crowl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 서명 상수
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3UnsignedHash  = "UNSIGNED-PAYLOAD"
+	s3DefaultRegion = "us-east-1"
+)
+
+// S3Config는 s3:// base_url에서 사용하는 S3 호환 저장소 설정입니다.
+// 키가 비어 있으면 AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN 환경 변수를 사용하고,
+// 그마저 없으면 서명하지 않은 익명 요청을 보냅니다.
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`   // 비어 있으면 https://s3.<region>.amazonaws.com
+	Region    string `yaml:"region"`     // 기본값 us-east-1
+	AccessKey string `yaml:"access_key"` // 접근 키 ID
+	SecretKey string `yaml:"secret_key"` // 비밀 접근 키
+	PathStyle bool   `yaml:"path_style"` // endpoint/bucket/key 형식 주소 사용 (MinIO 등)
+}
+
+// s3Source는 S3 호환 저장소의 bucket/prefix 아래 파일을 SigV4 서명 요청으로 읽습니다.
+type s3Source struct {
+	bucket       string
+	prefix       string
+	endpoint     *url.URL
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pathStyle    bool
+	client       *retryClient
+}
+
+func newS3Source(bucket, prefix string, client *retryClient, cfg S3Config) (*s3Source, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3:// base_url에 버킷이 없습니다")
+	}
+
+	s := &s3Source{
+		bucket:    bucket,
+		prefix:    prefix,
+		region:    cfg.Region,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		client:    client,
+	}
+	if s.region == "" {
+		s.region = s3DefaultRegion
+	}
+	if s.accessKey == "" && s.secretKey == "" {
+		s.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s.region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("잘못된 S3 endpoint: %s", endpoint)
+	}
+	s.endpoint = u
+	return s, nil
+}
+
+func (s *s3Source) Stat(ctx context.Context, path string) (SourceInfo, error) {
+	resp, err := s.client.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		return s.newRequest(ctx, http.MethodHead, path)
+	})
+	if err != nil {
+		return SourceInfo{}, err
+	}
+	resp.Body.Close()
+	return statResponse(resp)
+}
+
+func (s *s3Source) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
+	return s.client.openRange(ctx, offset, func(ctx context.Context) (*http.Request, error) {
+		return s.newRequest(ctx, http.MethodGet, path)
+	})
+}
+
+// newRequest는 객체 키 prefix+path에 대한 요청을 만듭니다.
+// 서명은 재시도마다 시각이 달라지므로 요청을 만들 때마다 새로 계산합니다.
+// Range 헤더는 서명 대상에 포함하지 않으므로 서명 후에 추가해도 됩니다.
+func (s *s3Source) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	key := strings.TrimSuffix(s.prefix, "/")
+	if key != "" {
+		key += "/"
+	}
+	key += strings.TrimPrefix(path, "/")
+
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.accessKey != "" {
+		s.sign(req, time.Now().UTC())
+	}
+	return req, nil
+}
+
+// sign은 요청에 AWS Signature Version 4 인증 헤더를 추가합니다.
+// 본문이 없는 GET/HEAD 요청만 보내므로 X-Amz-Content-Sha256 헤더가 없으면 페이로드 해시는 UNSIGNED-PAYLOAD를 사용합니다.
+func (s *s3Source) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = s3UnsignedHash
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	// 서명할 헤더: host와 x-amz-* 헤더
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath는 SigV4 규칙대로 경로의 각 세그먼트를 인코딩합니다. (비예약 문자와 '/'만 그대로 둠)
+func s3EscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3CanonicalQuery는 쿼리 파라미터를 이름순으로 정렬하여 인코딩합니다.
+func s3CanonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, strings.ReplaceAll(url.QueryEscape(k), "+", "%20")+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
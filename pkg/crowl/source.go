@@ -0,0 +1,187 @@
+package crowl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Source는 Common Crawl 파일(crawl-data/... 상대 경로)을 가져오는 저장소입니다.
+// base_url의 스킴에 따라 HTTP(S), 로컬 디렉토리(file://), S3 호환 저장소(s3://) 구현을 사용합니다.
+type Source interface {
+	// Stat은 path 파일의 크기와, 알 수 있으면 MD5(hex)를 반환합니다.
+	Stat(ctx context.Context, path string) (SourceInfo, error)
+	// Open은 path 파일을 offset부터 읽는 스트림과 실제 시작 위치를 반환합니다.
+	// 저장소가 부분 읽기를 지원하지 않으면 시작 위치는 0일 수 있습니다.
+	// 요청은 한 번만 보내므로 일시적 오류는 호출자가 retryClient.backoff로 재시도합니다.
+	Open(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error)
+}
+
+// SourceInfo는 Source 파일의 크기와 MD5입니다.
+type SourceInfo struct {
+	Size int64
+	MD5  string
+}
+
+// localSource는 파일을 복사하지 않고 제자리에서 읽을 수 있는 Source입니다.
+type localSource interface {
+	LocalPath(path string) string
+}
+
+// newSource는 baseURL의 스킴에 맞는 Source를 만듭니다.
+func newSource(baseURL string, client *retryClient, s3cfg S3Config) (Source, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("잘못된 base_url(%s): %w", baseURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &httpSource{base: baseURL, client: client}, nil
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("file:// base_url에 디렉토리가 없습니다: %s", baseURL)
+		}
+		return &fileSource{root: filepath.FromSlash(u.Path)}, nil
+	case "s3":
+		return newS3Source(u.Host, strings.TrimPrefix(u.Path, "/"), client, s3cfg)
+	}
+	return nil, fmt.Errorf("지원하지 않는 base_url 스킴: %s", baseURL)
+}
+
+// fileSource는 로컬 디렉토리에 미러링된 Common Crawl 파일을 읽습니다.
+type fileSource struct {
+	root string
+}
+
+func (s *fileSource) LocalPath(path string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path))
+}
+
+func (s *fileSource) Stat(ctx context.Context, path string) (SourceInfo, error) {
+	fi, err := os.Stat(s.LocalPath(path))
+	if err != nil {
+		return SourceInfo{}, err
+	}
+	return SourceInfo{Size: fi.Size()}, nil
+}
+
+func (s *fileSource) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
+	f, err := os.Open(s.LocalPath(path))
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, offset, nil
+}
+
+// httpSource는 data.commoncrawl.org 같은 HTTP(S) 서버에서 파일을 받습니다.
+type httpSource struct {
+	base   string
+	client *retryClient
+}
+
+func (s *httpSource) Stat(ctx context.Context, path string) (SourceInfo, error) {
+	resp, err := s.client.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, s.base+path, nil)
+	})
+	if err != nil {
+		return SourceInfo{}, err
+	}
+	resp.Body.Close()
+	return statResponse(resp)
+}
+
+func (s *httpSource) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, int64, error) {
+	return s.client.openRange(ctx, offset, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, s.base+path, nil)
+	})
+}
+
+// statResponse는 HEAD 응답에서 SourceInfo를 구합니다.
+func statResponse(resp *http.Response) (SourceInfo, error) {
+	if resp.StatusCode != http.StatusOK {
+		return SourceInfo{}, fmt.Errorf("HEAD 요청 실패: %s", resp.Status)
+	}
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		return SourceInfo{}, fmt.Errorf("유효하지 않은 Content-Length: %v", err)
+	}
+	return SourceInfo{Size: size, MD5: expectedMD5(resp.Header)}, nil
+}
+
+// openRange는 newReq 요청에 Range 헤더를 붙여 재시도 없이 한 번 보내고 본문과 실제 시작 위치를 반환합니다.
+// 서버가 Range를 무시하고 전체(200)를 보내면 시작 위치 0을 반환하고, 206 응답의 Content-Range가
+// 없거나 시작 위치가 offset과 다르면 Range 없이 처음부터 다시 요청합니다.
+// 본문은 IdleTimeout 동안 수신이 없으면 중단됩니다.
+func (c *retryClient) openRange(ctx context.Context, offset int64, newReq func(ctx context.Context) (*http.Request, error)) (io.ReadCloser, int64, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	req, err := newReq(reqCtx)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		cr := resp.Header.Get("Content-Range")
+		if start, ok := contentRangeStart(cr); !ok || start != offset {
+			resp.Body.Close()
+			cancel()
+			if offset == 0 {
+				return nil, 0, fmt.Errorf("요청하지 않은 부분 응답: Content-Range %q", cr)
+			}
+			fmt.Printf("[재다운로드] Content-Range %q가 요청 위치 %d와 다름, 처음부터 받음\n", cr, offset)
+			return c.openRange(ctx, 0, newReq)
+		}
+	case http.StatusOK:
+		offset = 0
+	default:
+		resp.Body.Close()
+		cancel()
+		return nil, 0, newStatusError(resp)
+	}
+	return newIdleBody(resp.Body, c.cfg.IdleTimeout, cancel), offset, nil
+}
+
+// contentRangeStart는 "bytes 시작-끝/전체" 형식 Content-Range의 시작 위치입니다.
+func contentRangeStart(v string) (int64, bool) {
+	rng, ok := strings.CutPrefix(strings.TrimSpace(v), "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, rest, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, false
+	}
+	last, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || start < 0 || end < start {
+		return 0, false
+	}
+	return start, true
+}
@@ -0,0 +1,226 @@
+package crowl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("0123456789abcdef")
+	path := "crawl-data/CC-NEWS/2025/04/a.warc.gz"
+	if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, path), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := newSource("file://"+filepath.ToSlash(dir)+"/", nil, S3Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := src.(localSource); !ok {
+		t.Fatal("file:// Source가 localSource가 아닙니다")
+	}
+	ctx := context.Background()
+
+	info, err := src.Stat(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != int64(len(data)) || info.MD5 != "" {
+		t.Errorf("Stat = %+v", info)
+	}
+
+	for _, offset := range []int64{0, 7, int64(len(data))} {
+		body, start, err := src.Open(ctx, path, offset)
+		if err != nil {
+			t.Fatalf("Open(%d): %v", offset, err)
+		}
+		got, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start != offset || string(got) != string(data[offset:]) {
+			t.Errorf("Open(%d) = %d, %q", offset, start, got)
+		}
+	}
+
+	if _, err := src.Stat(ctx, "crawl-data/missing.warc.gz"); !os.IsNotExist(err) {
+		t.Errorf("Stat: 없는 파일 오류 = %v", err)
+	}
+	if _, _, err := src.Open(ctx, "crawl-data/missing.warc.gz", 0); !os.IsNotExist(err) {
+		t.Errorf("Open: 없는 파일 오류 = %v", err)
+	}
+}
+
+func TestNewSourceErrors(t *testing.T) {
+	for _, base := range []string{"ftp://example.com/", "file://", "s3:///prefix/"} {
+		if _, err := newSource(base, nil, S3Config{}); err == nil {
+			t.Errorf("newSource(%q): 오류가 없습니다", base)
+		}
+	}
+}
+
+func TestContentRangeStart(t *testing.T) {
+	tests := []struct {
+		value string
+		start int64
+		ok    bool
+	}{
+		{"bytes 100-199/200", 100, true},
+		{"bytes 0-0/*", 0, true},
+		{" bytes 7-9/10 ", 7, true},
+		{"bytes */200", 0, false},
+		{"bytes 10-5/20", 0, false},
+		{"bytes 5-", 0, false},
+		{"items 0-9/10", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if start, ok := contentRangeStart(tt.value); start != tt.start || ok != tt.ok {
+			t.Errorf("contentRangeStart(%q) = %d, %v", tt.value, start, ok)
+		}
+	}
+}
+
+// newRangeServer는 data를 제공하는 서버입니다. Range 요청에는 contentRange(start)를 Content-Range로 하여
+// 그 위치부터 보내고, contentRange가 빈 문자열을 반환하면 Content-Range 없이 보냅니다.
+func newRangeServer(t *testing.T, data []byte, contentRange func(start int) string) (*httptest.Server, *atomic.Int32) {
+	var gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+			return
+		}
+		gets.Add(1)
+		start := 0
+		if rng := r.Header.Get("Range"); rng != "" {
+			fmt.Sscanf(rng, "bytes=%d-", &start)
+			if cr := contentRange(start); cr != "" {
+				w.Header().Set("Content-Range", cr)
+				fmt.Sscanf(cr, "bytes %d-", &start)
+			} else {
+				start = 0
+			}
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		w.Write(data[start:])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &gets
+}
+
+func TestHTTPSourceContentRange(t *testing.T) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	cfg := HTTPConfig{Retries: -1}
+	cfg.setDefaults()
+
+	tests := []struct {
+		name         string
+		contentRange func(start int) string
+		start        int64
+		gets         int32
+	}{
+		{"요청 위치", func(start int) string { return fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)) }, 10, 1},
+		{"다른 위치", func(start int) string { return fmt.Sprintf("bytes %d-%d/%d", start-4, len(data)-1, len(data)) }, 0, 2},
+		{"Content-Range 없음", func(int) string { return "" }, 0, 2},
+		{"잘못된 Content-Range", func(int) string { return "bytes */36" }, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, gets := newRangeServer(t, data, tt.contentRange)
+			src, err := newSource(srv.URL+"/", newRetryClient(cfg), S3Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, start, err := src.Open(context.Background(), "a.warc.gz", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(body)
+			body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start != tt.start || string(got) != string(data[tt.start:]) {
+				t.Errorf("Open(10) = %d, %q", start, got)
+			}
+			if gets.Load() != tt.gets {
+				t.Errorf("GET 요청 %d회, 기대값 %d", gets.Load(), tt.gets)
+			}
+		})
+	}
+}
+
+func newTestCommonCrawl(t *testing.T, baseURL string, cfg HTTPConfig) *CommonCrawl {
+	cfg.setDefaults()
+	client := newRetryClient(cfg)
+	src, err := newSource(baseURL, client, S3Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CommonCrawl{TempDir: t.TempDir(), HTTP: cfg, client: client, source: src}
+}
+
+const testWarcPath = "crawl-data/CC-NEWS/2025/04/CC-NEWS-20250401000000-00001.warc.gz"
+
+// 이어받기 루프와 Source.Open이 모두 재시도하면 실패한 다운로드가 Retries²번 요청하게 됨
+func TestDownloadRetriesOnce(t *testing.T) {
+	var gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", "100")
+			return
+		}
+		gets.Add(1)
+		http.Error(w, "SlowDown", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cc := newTestCommonCrawl(t, srv.URL+"/", HTTPConfig{Retries: 3, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond})
+	if _, err := cc.downloadedWarc(context.Background(), testWarcPath); err == nil {
+		t.Fatal("503 응답에서 오류가 없습니다")
+	}
+	if got := gets.Load(); got != 4 {
+		t.Errorf("GET 요청 %d회, 기대값 %d (재시도 3회)", got, 4)
+	}
+}
+
+func TestDownloadResumeRangeMismatch(t *testing.T) {
+	data := []byte(strings.Repeat("WARC record body ", 100))
+	// 이어받기 요청에 항상 처음부터 보내면서 206을 응답하는 서버
+	srv, gets := newRangeServer(t, data, func(int) string { return fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)) })
+
+	cc := newTestCommonCrawl(t, srv.URL+"/", HTTPConfig{Retries: -1})
+	part := filepath.Join(cc.TempDir, filepath.Base(testWarcPath)) + ".part"
+	if err := os.WriteFile(part, data[:100], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := cc.downloadedWarc(context.Background(), testWarcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("받은 파일 %d바이트, 원본 %d바이트", len(got), len(data))
+	}
+	if gets.Load() != 2 {
+		t.Errorf("GET 요청 %d회, 기대값 2", gets.Load())
+	}
+}
@@ -0,0 +1,353 @@
+package crowl
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 파일 처리 상태
+const (
+	StatusPending     = "pending"
+	StatusDownloading = "downloading"
+	StatusDownloaded  = "downloaded"
+	StatusParsing     = "parsing"
+	StatusDone        = "done"
+	StatusFailed      = "failed"
+)
+
+// 상태 저장소 파일 이름 (saveDir 아래)
+const (
+	stateFileName  = "state.jsonl"
+	legacyLogName  = "completed"
+	stateLineLimit = 16 << 20
+)
+
+// FileState는 크롤 파일 하나의 처리 상태입니다. 출력 파일 이름(Name)으로 구분합니다.
+type FileState struct {
+	Name        string        `json:"name"`
+	Source      string        `json:"source,omitempty"` // 원격 경로 또는 로컬 입력 파일
+	Status      string        `json:"status"`
+	Bytes       int64         `json:"bytes,omitempty"`        // 입력 파일 크기
+	OutputBytes int64         `json:"output_bytes,omitempty"` // 출력 파일 크기
+	Records     int64         `json:"records,omitempty"`      // 출력 레코드 수
+	Download    time.Duration `json:"download,omitempty"`     // 다운로드 소요 시간 (나노초)
+	Parse       time.Duration `json:"parse,omitempty"`        // 파싱 소요 시간 (나노초)
+	Error       string        `json:"error,omitempty"`
+	Corrupted   bool          `json:"corrupted,omitempty"`   // 무결성 검증 실패 여부
+	Superseded  bool          `json:"superseded,omitempty"`  // 출력한 캡처 중 일부가 다른 파일의 더 나은 캡처로 대체됨 (url.keep)
+	Fingerprint string        `json:"fingerprint,omitempty"` // 출력을 만든 정제 설정의 지문
+	Version     string        `json:"version,omitempty"`     // 출력을 만든 crowl 버전
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// stateStore는 saveDir 하나(CC-NEWS 한 달 또는 CC-MAIN 크롤 하나)의 파일 상태 저장소입니다.
+// 변경은 상태 전체를 JSON 한 줄로 state.jsonl에 추가하고 fsync하며, 열 때 마지막 줄 기준으로 재구성한 뒤
+// 임시 파일과 rename으로 압축해 다시 씁니다. 기록 도중 중단되어 잘린 마지막 줄은 무시합니다.
+type stateStore struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	files map[string]FileState
+}
+
+// openState는 dir의 상태 저장소를 엽니다. 상태 파일이 없고 이전 형식의 completed 로그가 있으면
+// 로그의 파일들을 완료 상태로 가져옵니다.
+func openState(dir string) (*stateStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	s := &stateStore{path: filepath.Join(dir, stateFileName), files: map[string]FileState{}}
+	lines, err := s.load()
+	if os.IsNotExist(err) {
+		err = s.importLegacy(filepath.Join(dir, legacyLogName))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 줄 수가 파일 수보다 많으면 압축
+	if lines != len(s.files) {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.f, err = os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load는 state.jsonl을 재생하여 files를 채우고 읽은 줄 수를 반환합니다.
+func (s *stateStore) load() (int, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var lines int
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), stateLineLimit)
+	for sc.Scan() {
+		lines++
+		var fs FileState
+		if err := json.Unmarshal(sc.Bytes(), &fs); err != nil || fs.Name == "" {
+			continue
+		}
+		s.files[fs.Name] = fs
+	}
+	return lines, sc.Err()
+}
+
+// importLegacy는 이전 형식의 completed 로그(파일 이름 목록)를 완료 상태로 가져옵니다.
+func (s *stateStore) importLegacy(logPath string) error {
+	f, err := os.Open(logPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	now := time.Now().UTC()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if name := strings.TrimSpace(sc.Text()); name != "" {
+			s.files[name] = FileState{Name: name, Status: StatusDone, UpdatedAt: now}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(s.files) > 0 {
+		fmt.Printf("[상태] 이전 완료 로그에서 %d개 파일을 가져왔습니다: %s\n", len(s.files), logPath)
+	}
+	return nil
+}
+
+// compact는 현재 상태를 파일당 한 줄로 임시 파일에 쓴 뒤 rename으로 교체합니다.
+func (s *stateStore) compact() error {
+	tmp, err := createAtomic(s.path)
+	if err != nil {
+		return err
+	}
+	defer tmp.abort()
+
+	w := bufio.NewWriter(tmp)
+	for _, fs := range s.sorted() {
+		line, err := json.Marshal(fs)
+		if err != nil {
+			return err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return tmp.commit()
+}
+
+// get은 name 파일의 상태를 반환합니다.
+func (s *stateStore) get(name string) (FileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fs, ok := s.files[name]
+	return fs, ok
+}
+
+// update는 name 파일의 상태를 fn으로 바꾸고 저장소에 기록합니다.
+func (s *stateStore) update(name string, fn func(fs *FileState)) error {
+	return s.updateMany([]string{name}, fn)
+}
+
+// updateMany는 여러 파일의 상태를 한 번의 기록과 fsync로 바꿉니다.
+func (s *stateStore) updateMany(names []string, fn func(fs *FileState)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	var buf []byte
+	changed := make([]FileState, 0, len(names))
+	for _, name := range names {
+		fs := s.files[name]
+		fs.Name = name
+		fn(&fs)
+		fs.UpdatedAt = now
+
+		line, err := json.Marshal(fs)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+		changed = append(changed, fs)
+	}
+
+	if _, err := s.f.Write(buf); err != nil {
+		return fmt.Errorf("상태 기록 오류(%s): %w", s.path, err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("상태 기록 오류(%s): %w", s.path, err)
+	}
+	for _, fs := range changed {
+		s.files[fs.Name] = fs
+	}
+	return nil
+}
+
+// list는 모든 파일 상태를 이름순으로 반환합니다.
+func (s *stateStore) list() []FileState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted()
+}
+
+func (s *stateStore) sorted() []FileState {
+	files := make([]FileState, 0, len(s.files))
+	for _, fs := range s.files {
+		files = append(files, fs)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+func (s *stateStore) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// state는 saveDir의 상태 저장소를 반환합니다. 같은 CommonCrawl 안에서는 디렉토리마다 하나만 엽니다.
+func (cc *CommonCrawl) state(saveDir string) (*stateStore, error) {
+	cc.statesMu.Lock()
+	defer cc.statesMu.Unlock()
+
+	key := filepath.Clean(saveDir)
+	if s, ok := cc.states[key]; ok {
+		return s, nil
+	}
+	s, err := openState(key)
+	if err != nil {
+		return nil, fmt.Errorf("상태 저장소 열기 오류(%s): %w", saveDir, err)
+	}
+	if cc.states == nil {
+		cc.states = map[string]*stateStore{}
+	}
+	cc.states[key] = s
+	return s, nil
+}
+
+// Close는 열려 있는 상태 저장소와 중복 판정 서명 저장소, URL 캡처 저장소를 모두 닫습니다.
+func (cc *CommonCrawl) Close() error {
+	cc.statesMu.Lock()
+	defer cc.statesMu.Unlock()
+
+	var firstErr error
+	for key, s := range cc.states {
+		if err := s.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(cc.states, key)
+	}
+	for key, s := range cc.dedups {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(cc.dedups, key)
+	}
+	for key, s := range cc.urls {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(cc.urls, key)
+	}
+	return firstErr
+}
+
+// FileStates는 크롤 저장 디렉토리의 파일별 처리 상태를 이름순으로 반환합니다.
+func (cc *CommonCrawl) FileStates(crawl Crawl) ([]FileState, error) {
+	saveDir := crawl.SaveDir(cc.DataDir)
+	if _, err := os.Stat(saveDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	s, err := cc.state(saveDir)
+	if err != nil {
+		return nil, err
+	}
+	return s.list(), nil
+}
+
+// Fingerprint는 출력 내용에 영향을 주는 유효 설정(처리 모드, 본문 추출 방식, 메타데이터 추출, 정제 규칙, WARC 출력, 응답 필터, 언어 판별, 중복 판정, URL 정규화)의 지문입니다.
+// 완료된 파일의 지문이나 버전이 현재와 다르면 IsStale이 true를 반환합니다.
+func (cc *CommonCrawl) Fingerprint() string {
+	// 기본 추출 방식(clean)은 지문에 넣지 않아 추출 방식 도입 전의 지문과 같게 유지
+	extractor := cc.Extractor
+	if extractor == ExtractorClean {
+		extractor = ""
+	}
+	// 언어 판별, 중복 판정, URL 정규화를 쓰지 않으면 지문에 넣지 않아 도입 전의 지문과 같게 유지
+	var language *LanguageConfig
+	if cc.Language.Enabled {
+		language = &cc.Language
+	}
+	var dedup *DedupConfig
+	if cc.Dedup.Enabled {
+		dedup = &cc.Dedup
+	}
+	var urls *URLConfig
+	if cc.URL.Normalize {
+		urls = &cc.URL
+	}
+	b, _ := json.Marshal(struct {
+		Mode            string
+		Extractor       string `json:",omitempty"`
+		Metadata        bool   `json:",omitempty"`
+		RemoveSelectors any
+		WarcOutput      any
+		Filter          any
+		Language        *LanguageConfig `json:",omitempty"`
+		Dedup           *DedupConfig    `json:",omitempty"`
+		URL             *URLConfig      `json:",omitempty"`
+	}{cc.Mode, extractor, cc.Metadata, cc.RemoveSelectors, cc.WarcOutput, cc.Filter, language, dedup, urls})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsStale은 완료된 파일이 현재와 다른 설정이나 crowl 버전으로 만들어졌는지, 또는 출력한 캡처가
+// 다른 파일의 더 나은 캡처로 대체되었는지 확인합니다.
+// 지문이 없는 이전 기록(completed 로그에서 가져온 항목 등)도 재처리 대상으로 봅니다.
+func (cc *CommonCrawl) IsStale(fs FileState) bool {
+	return fs.Status == StatusDone && (fs.Superseded || fs.Fingerprint != cc.Fingerprint() || fs.Version != Version)
+}
+
+// upToDate는 name 파일이 완료되어 이번 실행에서 다시 처리할 필요가 없는지 확인합니다.
+func (cc *CommonCrawl) upToDate(st *stateStore, name string) bool {
+	fs, ok := st.get(name)
+	if !ok || fs.Status != StatusDone {
+		return false
+	}
+	return !cc.Stale || !cc.IsStale(fs)
+}
+
+// needsRun은 name 파일을 이번 실행에서 처리해야 하는지 판단합니다.
+// 기본은 완료되지 않은 파일이고, Stale 모드에서는 완료됐지만 재처리 대상인 파일만 처리합니다.
+func (cc *CommonCrawl) needsRun(st *stateStore, name string) bool {
+	fs, ok := st.get(name)
+	if cc.Stale {
+		return ok && cc.IsStale(fs)
+	}
+	return !ok || fs.Status != StatusDone
+}
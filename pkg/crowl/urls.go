@@ -0,0 +1,92 @@
+package crowl
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"parkjunwoo.com/crowl/pkg/cdxj"
+	"parkjunwoo.com/crowl/pkg/dedup"
+	"parkjunwoo.com/crowl/pkg/urlnorm"
+)
+
+// errSeenURL은 같은 정규 URL의 더 나은 캡처를 이미 남겨 레코드를 건너뛰었음을 나타냅니다.
+var errSeenURL = errors.New("이미 남긴 URL")
+
+// URLConfig는 URL 정규화와 같은 URL의 반복 캡처 거르기 설정입니다.
+// Canonical이나 Keep을 설정하면 Normalize가 없어도 정규화합니다.
+// 남긴 캡처는 saveDir(한 달 또는 크롤 하나)마다 urls-<keep>.sig에 저장하여 모든 파싱 워커와 이후 실행이 함께 씁니다.
+// 먼저 출력한 캡처가 나중에 처리한 파일의 더 나은 캡처로 대체될 수 있으므로, Keep으로 URL마다 캡처를 하나만 남기려면
+// 첫 처리 뒤 대체 표시된 파일을 Stale로 한 번 더 처리해야 합니다 (reportStale).
+type URLConfig struct {
+	Normalize bool   `yaml:"normalize"` // 레코드에 정규 URL(canonical_url)과 SURT 키(surt) 기록
+	Canonical bool   `yaml:"canonical"` // warc 모드에서 문서의 <link rel=canonical>을 정규 URL로 사용
+	Keep      string `yaml:"keep"`      // 같은 정규 URL의 캡처 중 남길 것: first(가장 이른 WARC-Date) 또는 latest, 비어 있으면 모두
+}
+
+func (u *URLConfig) setDefaults(mode string) error {
+	if u.Canonical || u.Keep != "" {
+		u.Normalize = true
+	}
+	if u.Canonical && mode != KindWarc {
+		return errors.New("canonical은 HTML 문서를 처리하는 warc 모드에서만 쓸 수 있습니다")
+	}
+	switch u.Keep {
+	case "", dedup.KeepFirst, dedup.KeepLatest:
+	default:
+		return fmt.Errorf("알 수 없는 URL 캡처 기준: %s", u.Keep)
+	}
+	return nil
+}
+
+// urlSession은 saveDir의 URL 저장소에서 source 파일의 URLSession을 시작합니다.
+// 같은 CommonCrawl 안에서는 디렉토리마다 저장소를 하나만 엽니다.
+func (cc *CommonCrawl) urlSession(saveDir, source string) (*dedup.URLSession, error) {
+	cc.statesMu.Lock()
+	defer cc.statesMu.Unlock()
+
+	key := filepath.Clean(saveDir)
+	s, ok := cc.urls[key]
+	if !ok {
+		var err error
+		s, err = dedup.OpenURLs(filepath.Join(key, "urls-"+cc.URL.Keep+".sig"), cc.URL.Keep)
+		if err != nil {
+			return nil, err
+		}
+		if cc.urls == nil {
+			cc.urls = map[string]*dedup.URLStore{}
+		}
+		cc.urls[key] = s
+	}
+	return s.Begin(source), nil
+}
+
+// normalizeURL은 rec의 정규 URL과 SURT 키를 기록합니다. extract에서 찾은 canonical 주소가 있으면 그것을 씁니다.
+// 정규화할 수 없는 URL(http(s)가 아닌 경우 등)은 원래 URL의 SURT만 기록합니다.
+func (u URLConfig) normalizeURL(rec *Record) {
+	if !u.Normalize {
+		return
+	}
+	norm := rec.canonical
+	if norm == "" {
+		norm, _ = urlnorm.Normalize(rec.URL)
+	}
+	rec.CanonicalURL = norm
+	rec.SURT = cdxj.SURT(cmp.Or(norm, rec.URL))
+}
+
+// checkSeen은 rec의 SURT 키로 같은 URL의 캡처 중 rec를 남길지 판정합니다. 남기지 않으면 errSeenURL을 반환하고,
+// 이전에 남긴 다른 캡처를 대체했으면 replaced가 true입니다. WARC-Date가 없는 레코드는 판정하지 않고 남깁니다.
+func (u URLConfig) checkSeen(us *dedup.URLSession, rec *Record) (replaced bool, err error) {
+	date, err := time.Parse(time.RFC3339, rec.Date)
+	if err != nil {
+		return false, nil
+	}
+	ok, replaced := us.Keep(rec.SURT, date, cmp.Or(rec.RecordID, rec.URL+" "+rec.Date))
+	if !ok {
+		return false, fmt.Errorf("%w: %s", errSeenURL, rec.SURT)
+	}
+	return replaced, nil
+}
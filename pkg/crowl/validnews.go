@@ -1,35 +1,36 @@
 package crowl
 
 import (
-	"bufio"
 	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"gopkg.in/yaml.v3"
+	"parkjunwoo.com/crowl/pkg/wrc"
 )
 
 type ValidNews struct {
-	BatchSize int    `yaml:"batch_size"`
-	PyPath    string `yaml:"py_path"`
-	TempDir   string `yaml:"temp_dir"`
-	DataDir   string `yaml:"data_dir"`
+	BatchSize int          `yaml:"batch_size"`
+	PyPath    string       `yaml:"py_path"`
+	TempDir   string       `yaml:"temp_dir"`
+	DataDir   string       `yaml:"data_dir"`
+	Output    OutputConfig `yaml:"output"`
 }
 
 type newsItem struct {
-	url         string
-	htmlContent string
-	cleanText   string
+	rec       *Record
+	cleanText string
 }
 
 type inferRequest struct {
@@ -55,6 +56,10 @@ func NewValidNews(path string) (*ValidNews, error) {
 		cfg.BatchSize = 4
 	}
 
+	if err := cfg.Output.setDefaults(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -74,51 +79,45 @@ func cleanHTML(html string) string {
 	return text
 }
 
-func (vn *ValidNews) ProcessWRC(inputPath, outputPath string) error {
-	// Python 서버 시작
-	pyCmd := exec.Command("python3", vn.PyPath)
+// ProcessWRC는 inputPath의 레코드를 뉴스 판별 모델로 판정하여 outputPath에 기록합니다.
+// ctx가 취소되면 레코드 사이에서 멈추고, 추론 서버를 종료한 뒤 출력 파일을 남기지 않습니다.
+func (vn *ValidNews) ProcessWRC(ctx context.Context, inputPath, outputPath string) error {
+	// Python 서버 시작 (ctx가 취소되면 종료됨)
+	pyCmd := exec.CommandContext(ctx, "python3", vn.PyPath)
 	if err := pyCmd.Start(); err != nil {
 		return fmt.Errorf("start python server error: %v", err)
 	}
 
 	fmt.Println("🚀 starting python server...")
 
-	// FastAPI 서버 준비될 때까지 대기
-	if err := waitForPythonServer("http://127.0.0.1:8000", 120*time.Second); err != nil {
-		return fmt.Errorf("python server not ready: %v", err)
-	}
-
 	defer func() {
-		time.Sleep(3 * time.Second)
-		if err := pyCmd.Process.Kill(); err != nil {
+		if ctx.Err() == nil {
+			time.Sleep(3 * time.Second)
+		}
+		if err := pyCmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 			fmt.Printf("⚠️ failed python server close : %v\n", err)
 		} else {
 			fmt.Println("✅ closed python server.")
 		}
+		pyCmd.Wait()
 	}()
 
-	inFile, err := os.Open(inputPath)
-	if err != nil {
-		return err
+	// FastAPI 서버 준비될 때까지 대기
+	if err := waitForPythonServer(ctx, "http://127.0.0.1:8000", 120*time.Second); err != nil {
+		return fmt.Errorf("python server not ready: %w", err)
 	}
-	defer inFile.Close()
 
-	gzReader, err := gzip.NewReader(inFile)
+	inFile, err := os.Open(inputPath)
 	if err != nil {
 		return err
 	}
-	defer gzReader.Close()
-
-	reader := bufio.NewReader(gzReader)
+	defer inFile.Close()
 
-	outFile, err := os.Create(outputPath)
+	out, err := createOutput(outputPath, vn.Output, OutputInfo{Source: filepath.Base(inputPath)})
 	if err != nil {
 		return err
 	}
-	defer outFile.Close()
-
-	//gzWriter := gzip.NewWriter(outFile)
-	//defer gzWriter.Close()
+	defer out.abort()
 
 	var wg sync.WaitGroup
 	preprocessChan := make(chan newsItem, vn.BatchSize*2)
@@ -129,7 +128,7 @@ func (vn *ValidNews) ProcessWRC(inputPath, outputPath string) error {
 	go func() {
 		defer wg.Done()
 		for item := range preprocessChan {
-			cleaned := cleanHTML(item.htmlContent)
+			cleaned := cleanHTML(string(item.rec.body()))
 			if len(cleaned) == 0 {
 				continue
 			}
@@ -149,76 +148,116 @@ func (vn *ValidNews) ProcessWRC(inputPath, outputPath string) error {
 			batch = append(batch, item)
 
 			if len(batch) >= vn.BatchSize {
-				//vn.flushBatch(batch, gzWriter)
-				vn.flushBatch(batch, outFile)
+				vn.flushBatch(ctx, batch, out)
 				batch = []newsItem{}
 			}
 		}
 
 		if len(batch) > 0 {
-			//vn.flushBatch(batch, gzWriter)
-			vn.flushBatch(batch, outFile)
+			vn.flushBatch(ctx, batch, out)
 		}
 	}()
 
-	mainLoopCount := 0
-
 	// 데이터 읽기 및 전처리 워커로 전달
+	var readErr error
+	if isJSONL(inputPath) {
+		readErr = readJSONLRecords(ctx, inFile, inputPath, preprocessChan)
+	} else {
+		readErr = readWRCRecords(ctx, inFile, preprocessChan)
+	}
+
+	close(preprocessChan)
+	wg.Wait()
+
+	if readErr != nil {
+		return readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return out.commit()
+}
+
+// readJSONLRecords는 JSONL 출력 파일의 레코드를 items로 보냅니다.
+func readJSONLRecords(ctx context.Context, r io.Reader, path string, items chan<- newsItem) error {
+	jr, err := newJSONLReader(r, path)
+	if err != nil {
+		return err
+	}
+	defer jr.Close()
+
 	for {
-		url, err := reader.ReadString('\n')
+		rec, err := jr.Next()
 		if err == io.EOF {
-			break
+			return nil
 		}
-		url = strings.TrimSpace(url)
-
-		sizeLine, err := reader.ReadString('\n')
 		if err != nil {
-			fmt.Printf("size read error (%s): %v\n", url, err)
-			continue
+			return fmt.Errorf("JSONL 레코드 읽기 오류: %w", err)
 		}
-		size, err := strconv.Atoi(strings.TrimSpace(sizeLine))
-		if err != nil {
-			fmt.Printf("invalid size (%s): %v\n", url, err)
-			continue
-		}
-
-		htmlContent := make([]byte, size)
-		if _, err := io.ReadFull(reader, htmlContent); err != nil {
-			fmt.Printf("content read error (%s): %v\n", url, err)
-			continue
+		if err := sendItem(ctx, items, newsItem{rec: rec}); err != nil {
+			return err
 		}
+	}
+}
 
-		reader.ReadString('\n')
-		reader.ReadString('\n')
-
-		preprocessChan <- newsItem{url: url, htmlContent: string(htmlContent)}
+// readWRCRecords는 wrc.gz 파일의 레코드를 items로 보냅니다.
+// 파일 끝의 잘린 레코드는 경고만 출력하고 건너뜁니다.
+func readWRCRecords(ctx context.Context, r io.Reader, items chan<- newsItem) error {
+	wr, err := wrc.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer wr.Close()
 
-		mainLoopCount++
-		if mainLoopCount >= 100 {
-			fmt.Println("🚩 테스트를 위해 100개 항목 처리 후 종료.")
-			break
+	for rec, err := range wr.All() {
+		if errors.Is(err, wrc.ErrTruncated) {
+			fmt.Printf("⚠️ %v\n", err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("wrc 레코드 읽기 오류: %w", err)
+		}
+		if err := sendItem(ctx, items, newsItem{rec: &Record{URL: rec.URL, Label: rec.Label, content: rec.Content}}); err != nil {
+			return err
 		}
 	}
-
-	close(preprocessChan)
-	wg.Wait()
-
 	return nil
 }
 
-func (vn *ValidNews) flushBatch(items []newsItem, gzWriter *os.File) {
+// sendItem은 item을 items로 보내고, 그 전에 ctx가 취소되면 ctx의 오류를 반환합니다.
+func sendItem(ctx context.Context, items chan<- newsItem, item newsItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case items <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (vn *ValidNews) flushBatch(ctx context.Context, items []newsItem, out RecordWriter) {
 	texts := make([]string, len(items))
 	for i, item := range items {
 		texts[i] = item.cleanText
 	}
 
-	resp, err := http.Post("http://127.0.0.1:8000/infer",
-		"application/json",
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://127.0.0.1:8000/infer",
 		bytes.NewBuffer(jsonEncode(inferRequest{Texts: texts})))
 	if err != nil {
 		fmt.Printf("HTTP batch error: %v\n", err)
 		return
 	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("HTTP batch error: %v\n", err)
+		return
+	}
 
 	var inferRes inferResponse
 	if err := json.NewDecoder(resp.Body).Decode(&inferRes); err != nil {
@@ -235,11 +274,9 @@ func (vn *ValidNews) flushBatch(items []newsItem, gzWriter *os.File) {
 	}
 
 	for i, item := range items {
-		entry := fmt.Sprintf("%s\n%s\n%d\n%s\n\n",
-			item.url, inferRes.Answers[i], len(item.htmlContent), item.htmlContent)
-
-		if _, err := gzWriter.Write([]byte(entry)); err != nil {
-			fmt.Printf("Write Error (%s): %v\n", item.url, err)
+		item.rec.Label = inferRes.Answers[i]
+		if _, _, err := out.Write(item.rec); err != nil {
+			fmt.Printf("Write Error (%s): %v\n", item.rec.URL, err)
 		}
 	}
 }
@@ -249,18 +286,28 @@ func jsonEncode(v interface{}) []byte {
 	return data
 }
 
-// waitForPythonServer가 FastAPI 서버 준비 완료될 때까지 기다림
-func waitForPythonServer(url string, timeout time.Duration) error {
+// waitForPythonServer가 FastAPI 서버 준비 완료될 때까지 기다림 (ctx가 취소되면 중단)
+func waitForPythonServer(ctx context.Context, url string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
 	for {
 		if time.Now().After(deadline) {
 			return fmt.Errorf("timeout: Python server not ready within %v", timeout)
 		}
-		resp, err := http.Get(url + "/health")
-		if err == nil && resp.StatusCode == 200 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/health", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
 			resp.Body.Close()
-			return nil // 서버 준비 완료
+			if resp.StatusCode == 200 {
+				return nil // 서버 준비 완료
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
 		}
-		time.Sleep(2 * time.Second)
 	}
 }
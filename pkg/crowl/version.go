@@ -0,0 +1,5 @@
+package crowl
+
+// Version은 crowl의 버전입니다. 출력 내용(정제 방식, 출력 형식)이 바뀌는 변경에서 올리며,
+// 이전 버전으로 만든 출력은 재처리 대상(stale)이 됩니다.
+const Version = "0.5.0"
@@ -0,0 +1,71 @@
+package crowl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// watEnvelope는 WAT metadata 레코드 JSON 중 사용하는 부분입니다.
+type watEnvelope struct {
+	Envelope struct {
+		WarcHeader struct {
+			Type      string `json:"WARC-Type"`
+			TargetURI string `json:"WARC-Target-URI"`
+		} `json:"WARC-Header-Metadata"`
+		Payload struct {
+			HTTPResponse *struct {
+				ResponseMessage struct {
+					Status string `json:"Status"`
+				} `json:"Response-Message"`
+				Headers      map[string]any `json:"Headers"`
+				HTMLMetadata struct {
+					Head struct {
+						Title string `json:"Title"`
+					} `json:"Head"`
+					Links []WatLink `json:"Links"`
+				} `json:"HTML-Metadata"`
+			} `json:"HTTP-Response-Metadata"`
+		} `json:"Payload-Metadata"`
+	} `json:"Envelope"`
+}
+
+// WatLink는 WAT에 기록된 페이지 내 링크입니다.
+type WatLink struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+	Text string `json:"text,omitempty"`
+}
+
+// WatRecord는 wat 모드에서 출력하는 응답 메타데이터입니다.
+type WatRecord struct {
+	URL     string         `json:"url"`
+	Status  int            `json:"status"`
+	Title   string         `json:"title,omitempty"`
+	Headers map[string]any `json:"headers,omitempty"`
+	Links   []WatLink      `json:"links,omitempty"`
+}
+
+// parseWat은 WAT metadata 레코드의 JSON 봉투에서 응답 메타데이터를 추립니다.
+// 요청(request)이나 메타데이터(metadata) 레코드에 대한 봉투는 오류를 반환하여 건너뜁니다.
+func parseWat(content []byte) (*WatRecord, error) {
+	var env watEnvelope
+	if err := json.Unmarshal(content, &env); err != nil {
+		return nil, fmt.Errorf("WAT JSON 파싱 오류: %w", err)
+	}
+
+	header := env.Envelope.WarcHeader
+	resp := env.Envelope.Payload.HTTPResponse
+	if header.Type != "response" || resp == nil {
+		return nil, fmt.Errorf("응답 메타데이터가 아님: %s", header.Type)
+	}
+
+	status, _ := strconv.Atoi(resp.ResponseMessage.Status)
+	return &WatRecord{
+		URL:     header.TargetURI,
+		Status:  status,
+		Title:   resp.HTMLMetadata.Head.Title,
+		Headers: resp.Headers,
+		Links:   resp.HTMLMetadata.Links,
+	}, nil
+}
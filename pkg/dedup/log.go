@@ -0,0 +1,94 @@
+package dedup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 서명 로그는 출처 파일별 블록을 추가만 하는 텍스트 파일입니다.
+// 블록은 "S <출처>" 줄, 항목 줄들, "E <항목 수>" 줄로 이뤄지며, 같은 출처의 블록이 다시 나오면
+// 앞의 블록을 대체합니다. E 줄이 없거나 항목 수가 맞지 않는 잘린 블록은 무시합니다.
+
+// readLog는 path의 로그에서 완전한 블록마다 apply를 호출합니다. apply가 false를 반환하거나
+// 잘린 블록이 있으면 dirty가 true이며, 이때는 compact로 로그를 다시 쓰는 것이 좋습니다.
+func readLog(path string, apply func(source string, lines []string) bool) (dirty bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var source string
+	var lines []string
+	inBlock := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "S "):
+			dirty = dirty || inBlock
+			source, lines, inBlock = line[2:], lines[:0], true
+		case strings.HasPrefix(line, "E "):
+			n, err := strconv.Atoi(line[2:])
+			if !inBlock || err != nil || n != len(lines) {
+				dirty, inBlock = true, false
+				continue
+			}
+			if !apply(source, lines) {
+				dirty = true
+			}
+			inBlock = false
+		case inBlock:
+			lines = append(lines, line)
+		default:
+			dirty = true
+		}
+	}
+	return dirty || inBlock, sc.Err()
+}
+
+// appendBlock은 source의 항목 줄들을 로그 블록으로 buf에 덧붙입니다.
+func appendBlock(buf []byte, source string, lines []string) []byte {
+	buf = fmt.Appendf(buf, "S %s\n", source)
+	for _, line := range lines {
+		buf = append(append(buf, line...), '\n')
+	}
+	return fmt.Appendf(buf, "E %d\n", len(lines))
+}
+
+// compactLog는 blocks가 만든 내용을 임시 파일에 쓴 뒤 rename으로 path를 교체합니다.
+func compactLog(path string, blocks []byte) error {
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(blocks); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendLog는 블록 하나를 로그 파일 f에 기록하고 fsync합니다.
+func appendLog(f *os.File, block []byte) error {
+	if _, err := f.Write(block); err != nil {
+		return fmt.Errorf("dedup: 로그 기록 오류(%s): %w", f.Name(), err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("dedup: 로그 기록 오류(%s): %w", f.Name(), err)
+	}
+	return nil
+}
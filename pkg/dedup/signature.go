@@ -0,0 +1,95 @@
+// Package dedup는 본문 텍스트의 완전 중복과 유사 중복을 찾습니다.
+//
+// 완전 중복은 정규화한 텍스트(소문자, 문장부호·공백 통일)의 해시로, 유사 중복은 문자 shingle의
+// 64비트 SimHash 해밍 거리로 판정합니다. 서명은 Store에 모아 여러 워커와 실행이 함께 씁니다.
+// 같은 URL을 여러 번 캡처한 경우는 URLStore가 정규 URL마다 처음 또는 마지막 캡처만 남깁니다.
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	shingleSize  = 5   // SimHash에 쓰는 문자 shingle 길이
+	minNearRunes = 100 // 유사 중복을 판정할 정규화 텍스트의 최소 문자 수
+)
+
+// Signature는 텍스트 하나의 중복 판정용 서명입니다.
+type Signature struct {
+	Hash    uint64 // 정규화한 텍스트의 SHA-256 앞 8바이트
+	SimHash uint64 // 문자 shingle의 SimHash, 텍스트가 짧으면 0 (유사 중복 판정 안 함)
+}
+
+// Sign은 text의 서명을 계산합니다. 빈 텍스트면 ok가 false입니다.
+func Sign(text string) (sig Signature, ok bool) {
+	norm := normalize(text)
+	if len(norm) == 0 {
+		return Signature{}, false
+	}
+	sum := sha256.Sum256([]byte(string(norm)))
+	sig.Hash = binary.BigEndian.Uint64(sum[:8])
+	if len(norm) >= minNearRunes {
+		sig.SimHash = simHash(norm)
+	}
+	return sig, true
+}
+
+// Distance는 두 SimHash의 해밍 거리입니다.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// normalize는 글자와 숫자만 소문자로 남기고, 그 사이의 공백·문장부호는 공백 하나로 바꿉니다.
+func normalize(text string) []rune {
+	norm := make([]rune, 0, len(text))
+	space := false
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if space && len(norm) > 0 {
+				norm = append(norm, ' ')
+			}
+			norm = append(norm, unicode.ToLower(r))
+			space = false
+			continue
+		}
+		space = true
+	}
+	return norm
+}
+
+// simHash는 shingleSize 문자 shingle마다 FNV-64a 해시의 각 비트를 더하고 빼서 부호로 64비트를 정합니다.
+// 띄어쓰기가 없는 중국어·일본어에도 쓸 수 있도록 단어 대신 문자 shingle을 씁니다.
+func simHash(norm []rune) uint64 {
+	var v [64]int
+	h := fnv.New64a()
+	var b strings.Builder
+	for i := 0; i+shingleSize <= len(norm); i++ {
+		b.Reset()
+		for _, r := range norm[i : i+shingleSize] {
+			b.WriteRune(r)
+		}
+		h.Reset()
+		h.Write([]byte(b.String()))
+		x := h.Sum64()
+		for j := range v {
+			if x&(1<<j) != 0 {
+				v[j]++
+			} else {
+				v[j]--
+			}
+		}
+	}
+
+	var sim uint64
+	for j, n := range v {
+		if n > 0 {
+			sim |= 1 << j
+		}
+	}
+	return sim
+}
@@ -0,0 +1,116 @@
+package dedup
+
+import (
+	"strings"
+	"testing"
+)
+
+// 정규화 후 100자를 넘는 기사 본문 (유사 중복 판정 대상)
+var article = "서울시는 내년부터 시내버스와 지하철 기본요금을 각각 300원씩 인상하기로 했다고 1일 밝혔다. " +
+	"시는 연료비와 인건비가 오르면서 대중교통 운영 적자가 해마다 커지고 있어, 요금 조정이 불가피하다고 설명했다. " +
+	"시민단체는 서민의 교통비 부담이 커진다며 반발하고 있으며, 시의회는 다음 달 공청회를 열어 의견을 듣기로 했다. " +
+	"한편 시는 청소년과 노인에 대한 할인 폭을 넓히고, 정기권 가격은 동결하는 방안을 함께 검토하고 있다."
+
+func sign(t *testing.T, text string) Signature {
+	t.Helper()
+	sig, ok := Sign(text)
+	if !ok {
+		t.Fatalf("Sign(%q): ok가 false입니다", text)
+	}
+	return sig
+}
+
+func TestSignExact(t *testing.T) {
+	base := sign(t, article)
+	if base.SimHash == 0 {
+		t.Fatal("긴 텍스트의 SimHash가 0입니다")
+	}
+	tests := []struct {
+		name string
+		text string
+		same bool
+	}{
+		{"공백 차이", "  " + strings.ReplaceAll(article, " ", "\n\t ") + "\n", true},
+		{"문장부호 차이", strings.ReplaceAll(strings.ReplaceAll(article, ".", "!!"), ",", " ·"), true},
+		{"단어 추가", strings.Replace(article, "서울시는", "서울시는 올해", 1), false},
+		{"글자 하나 차이", strings.Replace(article, "300원", "400원", 1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sign(t, tt.text); (got == base) != tt.same {
+				t.Errorf("Sign = %+v, 원문 %+v, 같음 기대 %v", got, base, tt.same)
+			}
+		})
+	}
+
+	if a, b := sign(t, "Breaking NEWS: Seoul"), sign(t, "breaking news seoul"); a != b {
+		t.Errorf("대소문자만 다른 텍스트: %+v, %+v", a, b)
+	}
+}
+
+func TestSignNear(t *testing.T) {
+	base := sign(t, article)
+	tests := []struct {
+		name string
+		text string
+		max  int // 허용하는 최대 해밍 거리
+		min  int // 요구하는 최소 해밍 거리
+	}{
+		{"숫자 하나 수정", strings.Replace(article, "300원", "400원", 1), 5, 1},
+		{"끝에 기자 이름 추가", article + " 홍길동 기자", 5, 0},
+		{"문장 하나 삭제", strings.Replace(article, "한편 시는 청소년과 노인에 대한 할인 폭을 넓히고, 정기권 가격은 동결하는 방안을 함께 검토하고 있다.", "", 1), 12, 0},
+		{"다른 기사", strings.Repeat("프로야구 개막전에서 홈팀이 연장 접전 끝에 끝내기 안타로 승리하며 시즌을 기분 좋게 출발했다. ", 3), 64, 16},
+		{"영문 기사", strings.Repeat("The central bank held interest rates steady on Thursday, citing persistent inflation. ", 3), 64, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sign(t, tt.text)
+			if d := Distance(base.SimHash, got.SimHash); d > tt.max || d < tt.min {
+				t.Errorf("Distance = %d, 기대 %d~%d", d, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSignShort(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		ok   bool
+	}{
+		{"빈 텍스트", "", false},
+		{"공백과 문장부호만", " \n\t.,!?— ", false},
+		{"짧은 텍스트", "짧은 기사 본문입니다.", true},
+		{"99자", strings.Repeat("가", 99), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, ok := Sign(tt.text)
+			if ok != tt.ok || sig.SimHash != 0 || ok != (sig.Hash != 0) {
+				t.Errorf("Sign = %+v, %v, ok 기대 %v (SimHash 0)", sig, ok, tt.ok)
+			}
+		})
+	}
+	if sig := sign(t, strings.Repeat("가", 100)); sig.SimHash == 0 {
+		t.Error("100자 텍스트의 SimHash가 0입니다")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0xff, 0xff, 0},
+		{0, 1, 1},
+		{0xf0, 0x0f, 8},
+		{0, ^uint64(0), 64},
+		{1 << 63, 1, 2},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 중복 종류
+const (
+	Exact = "exact" // 정규화한 텍스트가 같음
+	Near  = "near"  // SimHash 해밍 거리가 MaxDistance 이하
+)
+
+// MaxDistanceLimit은 유사 중복 판정에 쓸 수 있는 최대 해밍 거리입니다.
+const MaxDistanceLimit = 7
+
+// Match는 Session.Add의 판정 결과입니다.
+type Match struct {
+	Cluster   string // 클러스터 id (처음 본 텍스트의 해시, 16자리 16진수)
+	Duplicate string // 처음 본 텍스트면 빈 문자열, 중복이면 Exact 또는 Near
+}
+
+// Store는 본문 서명을 출처 파일별 블록으로 로그 파일(log.go)에 저장하고, 메모리 색인으로 중복을 찾습니다.
+// 완전 중복은 해시 맵으로, 유사 중복은 SimHash를 MaxDistance+1개 구간으로 나눈 색인으로 후보를 찾습니다
+// (거리가 MaxDistance 이하인 두 값은 적어도 한 구간이 같음). 처음 본 텍스트의 서명만 저장합니다.
+// 로그 항목은 "<해시> <SimHash>" 줄입니다.
+// 여러 고루틴에서 동시에 사용할 수 있습니다.
+type Store struct {
+	mu          sync.Mutex
+	path        string
+	f           *os.File
+	maxDistance int
+
+	entries []entry
+	exact   map[uint64]int32
+	bands   []map[uint64][]int32
+	sources map[string][]int32 // 출처별 항목 (저장된 블록과 진행 중인 Session)
+}
+
+type entry struct {
+	sig     Signature
+	removed bool
+}
+
+// Open은 path의 서명 로그를 읽어 Store를 엽니다. maxDistance가 0이면 완전 중복만 찾습니다.
+// 대체되었거나 잘린 블록이 있으면 로그를 다시 씁니다.
+func Open(path string, maxDistance int) (*Store, error) {
+	if maxDistance < 0 || maxDistance > MaxDistanceLimit {
+		return nil, fmt.Errorf("dedup: 해밍 거리는 0~%d: %d", MaxDistanceLimit, maxDistance)
+	}
+	s := &Store{
+		path:        path,
+		maxDistance: maxDistance,
+		exact:       map[uint64]int32{},
+		sources:     map[string][]int32{},
+	}
+	if maxDistance > 0 {
+		s.bands = make([]map[uint64][]int32, maxDistance+1)
+		for i := range s.bands {
+			s.bands[i] = map[uint64][]int32{}
+		}
+	}
+
+	dirty, err := s.load()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("dedup: 서명 로그 읽기 오류(%s): %w", path, err)
+	}
+	if dirty {
+		if err := s.compact(); err != nil {
+			return nil, fmt.Errorf("dedup: 서명 로그 압축 오류(%s): %w", path, err)
+		}
+	}
+
+	s.f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load는 로그를 재생하여 색인을 채웁니다. 대체된 블록이나 잘린 블록이 있으면 dirty가 true입니다.
+func (s *Store) load() (bool, error) {
+	return readLog(s.path, func(source string, lines []string) bool {
+		sigs := make([]Signature, 0, len(lines))
+		for _, line := range lines {
+			sig, err := parseSignature(line)
+			if err != nil {
+				return false
+			}
+			sigs = append(sigs, sig)
+		}
+		replaced := len(s.sources[source]) > 0
+		s.forget(source)
+		for _, sig := range sigs {
+			s.add(source, sig)
+		}
+		return !replaced
+	})
+}
+
+func parseSignature(line string) (Signature, error) {
+	h, sim, ok := strings.Cut(line, " ")
+	if !ok {
+		return Signature{}, errors.New("잘못된 서명 줄")
+	}
+	hash, err := strconv.ParseUint(h, 16, 64)
+	if err != nil {
+		return Signature{}, err
+	}
+	simHash, err := strconv.ParseUint(sim, 16, 64)
+	if err != nil {
+		return Signature{}, err
+	}
+	return Signature{Hash: hash, SimHash: simHash}, nil
+}
+
+// compact는 남아 있는 블록만 로그에 다시 씁니다.
+func (s *Store) compact() error {
+	var buf []byte
+	for source := range s.sources {
+		buf = s.block(buf, source)
+	}
+	return compactLog(s.path, buf)
+}
+
+// block은 source의 서명을 로그 블록으로 buf에 덧붙입니다.
+func (s *Store) block(buf []byte, source string) []byte {
+	idx := s.sources[source]
+	lines := make([]string, len(idx))
+	for j, i := range idx {
+		sig := s.entries[i].sig
+		lines[j] = fmt.Sprintf("%016x %016x", sig.Hash, sig.SimHash)
+	}
+	return appendBlock(buf, source, lines)
+}
+
+// add는 서명을 색인에 추가합니다. s.mu를 잡은 상태에서 호출합니다.
+func (s *Store) add(source string, sig Signature) {
+	i := int32(len(s.entries))
+	s.entries = append(s.entries, entry{sig: sig})
+	if _, ok := s.exact[sig.Hash]; !ok {
+		s.exact[sig.Hash] = i
+	}
+	if sig.SimHash != 0 {
+		for b, band := range s.bands {
+			key := s.band(sig.SimHash, b)
+			band[key] = append(band[key], i)
+		}
+	}
+	s.sources[source] = append(s.sources[source], i)
+}
+
+// forget은 source의 항목을 색인에서 지웁니다. s.mu를 잡은 상태에서 호출합니다.
+// 구간 색인에는 남겨 두고 조회할 때 건너뜁니다.
+func (s *Store) forget(source string) {
+	for _, i := range s.sources[source] {
+		e := &s.entries[i]
+		e.removed = true
+		if j, ok := s.exact[e.sig.Hash]; ok && j == i {
+			delete(s.exact, e.sig.Hash)
+		}
+	}
+	delete(s.sources, source)
+}
+
+// band는 SimHash의 b번째 구간 값입니다. 구간 번호를 상위 비트에 넣어 구간끼리 겹치지 않게 합니다.
+func (s *Store) band(sim uint64, b int) uint64 {
+	width := 64 / len(s.bands)
+	start := b * width
+	if b == len(s.bands)-1 {
+		width = 64 - start
+	}
+	return (sim>>start)&(1<<width-1) | uint64(b)<<60
+}
+
+// lookup은 sig와 같거나 가까운 항목을 찾습니다. s.mu를 잡은 상태에서 호출합니다.
+func (s *Store) lookup(sig Signature) (Match, bool) {
+	if i, ok := s.exact[sig.Hash]; ok && !s.entries[i].removed {
+		return Match{Cluster: clusterID(s.entries[i].sig), Duplicate: Exact}, true
+	}
+	if sig.SimHash == 0 {
+		return Match{}, false
+	}
+
+	best, bestDist := int32(-1), s.maxDistance+1
+	for b, band := range s.bands {
+		for _, i := range band[s.band(sig.SimHash, b)] {
+			e := s.entries[i]
+			if e.removed || e.sig.SimHash == 0 {
+				continue
+			}
+			if d := Distance(sig.SimHash, e.sig.SimHash); d < bestDist || d == bestDist && i < best {
+				best, bestDist = i, d
+			}
+		}
+	}
+	if best < 0 {
+		return Match{}, false
+	}
+	return Match{Cluster: clusterID(s.entries[best].sig), Duplicate: Near}, true
+}
+
+func clusterID(sig Signature) string {
+	return fmt.Sprintf("%016x", sig.Hash)
+}
+
+// Len은 저장된 서명 수입니다.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.exact)
+}
+
+// Close는 로그 파일을 닫습니다.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// Session은 출처 파일 하나를 처리하는 동안 추가한 서명입니다.
+// 추가한 서명은 바로 다른 Session의 조회에도 쓰이며, Commit하면 로그에 기록되고 Abort하면 지워집니다.
+type Session struct {
+	s      *Store
+	source string
+	done   bool
+}
+
+// Begin은 source의 Session을 시작합니다. 같은 출처를 다시 처리하는 경우이므로
+// 이전에 저장한 source의 서명은 지웁니다 (자기 자신과 중복으로 판정하지 않도록).
+func (s *Store) Begin(source string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forget(source)
+	return &Session{s: s, source: source}
+}
+
+// Add는 sig를 이미 본 텍스트와 비교합니다. 처음 본 텍스트면 서명을 추가합니다.
+func (ss *Session) Add(sig Signature) Match {
+	s := ss.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.lookup(sig); ok {
+		return m
+	}
+	s.add(ss.source, sig)
+	return Match{Cluster: clusterID(sig)}
+}
+
+// Commit은 Session에서 추가한 서명을 블록 하나로 로그에 기록하고 fsync합니다.
+func (ss *Session) Commit() error {
+	s := ss.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ss.done {
+		return nil
+	}
+	ss.done = true
+
+	return appendLog(s.f, s.block(nil, ss.source))
+}
+
+// Abort는 Commit하지 않은 Session의 서명을 지웁니다. Commit 이후 호출하면 아무 일도 하지 않습니다.
+func (ss *Session) Abort() {
+	s := ss.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ss.done {
+		return
+	}
+	ss.done = true
+	s.forget(ss.source)
+}
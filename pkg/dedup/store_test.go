@@ -0,0 +1,133 @@
+package dedup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openStore(t *testing.T, path string, maxDistance int) *Store {
+	t.Helper()
+	s, err := Open(path, maxDistance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStoreAdd(t *testing.T) {
+	base := sign(t, article)
+	near := sign(t, strings.Replace(article, "300원", "400원", 1))
+	other := sign(t, strings.Repeat("프로야구 개막전에서 홈팀이 연장 접전 끝에 끝내기 안타로 승리하며 시즌을 기분 좋게 출발했다. ", 3))
+	short := sign(t, "짧은 기사 본문입니다.")
+	shortNear := Signature{Hash: short.Hash + 1}
+	flip := func(n int) Signature { return Signature{Hash: base.Hash + 1, SimHash: base.SimHash ^ (1<<n-1)<<7} }
+	cluster := clusterID(base)
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		sig         Signature
+		want        Match
+	}{
+		{"처음 본 텍스트", 3, other, Match{Cluster: clusterID(other)}},
+		{"완전 중복", 3, base, Match{Cluster: cluster, Duplicate: Exact}},
+		{"유사 중복", MaxDistanceLimit, near, Match{Cluster: cluster, Duplicate: Near}},
+		{"거리가 maxDistance와 같음", 3, flip(3), Match{Cluster: cluster, Duplicate: Near}},
+		{"거리가 maxDistance보다 큼", 3, flip(4), Match{Cluster: clusterID(flip(4))}},
+		{"유사 중복 판정 안 함", 0, flip(1), Match{Cluster: clusterID(flip(1))}},
+		{"짧은 텍스트 완전 중복", 3, short, Match{Cluster: clusterID(short), Duplicate: Exact}},
+		{"짧은 텍스트는 유사 중복 판정 안 함", MaxDistanceLimit, shortNear, Match{Cluster: clusterID(shortNear)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openStore(t, filepath.Join(t.TempDir(), "dedup.log"), tt.maxDistance)
+			ss := s.Begin("a.wrc.gz")
+			for _, sig := range []Signature{base, short} {
+				if m := ss.Add(sig); m.Duplicate != "" {
+					t.Fatalf("첫 서명 Add = %+v", m)
+				}
+			}
+			if got := s.Begin("b.wrc.gz").Add(tt.sig); got != tt.want {
+				t.Errorf("Add = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "dedup.log"), MaxDistanceLimit+1); err == nil {
+		t.Error("허용 범위를 넘는 해밍 거리에서 오류가 없습니다")
+	}
+}
+
+// Commit한 서명만 다시 열 때 남고, 같은 출처를 다시 처리하면 이전 서명을 대체합니다.
+func TestStoreSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.log")
+	a, b := sign(t, article), sign(t, "다른 기사 본문")
+
+	s := openStore(t, path, 3)
+	sa := s.Begin("a")
+	sa.Add(a)
+	if err := sa.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	sb := s.Begin("b")
+	sb.Add(b)
+	if m := s.Begin("c").Add(b); m.Duplicate != Exact {
+		t.Errorf("Commit 전 다른 Session의 서명: %+v", m)
+	}
+	sb.Abort()
+	sb.Abort()
+	if n := s.Len(); n != 1 {
+		t.Errorf("Abort 후 Len = %d, want 1", n)
+	}
+	s.Close()
+
+	s = openStore(t, path, 3)
+	if n := s.Len(); n != 1 {
+		t.Errorf("다시 연 Len = %d, want 1", n)
+	}
+	if m := s.Begin("x").Add(a); m.Duplicate != Exact {
+		t.Errorf("다시 연 뒤 Commit한 서명: %+v", m)
+	}
+	// 같은 출처를 다시 처리하면 자기 자신과 중복으로 판정하지 않음
+	sa = s.Begin("a")
+	if m := sa.Add(a); m.Duplicate != "" {
+		t.Errorf("같은 출처 다시 처리: %+v", m)
+	}
+	sa.Add(b)
+	if err := sa.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// 대체된 블록은 다시 열 때 로그에서 지움
+	s = openStore(t, path, 3)
+	if n := s.Len(); n != 2 {
+		t.Errorf("대체 후 Len = %d, want 2", n)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "S a\n"); n != 1 {
+		t.Errorf("출처 a 블록 %d개:\n%s", n, data)
+	}
+}
+
+// 잘린 블록(E 줄 없음)은 무시합니다.
+func TestStoreTruncatedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.log")
+	log := "S a\n0000000000000001 0000000000000000\nE 1\nS b\n0000000000000002 0000000000000000\n"
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := openStore(t, path, 0)
+	if n := s.Len(); n != 1 {
+		t.Errorf("Len = %d, want 1", n)
+	}
+	if m := s.Begin("x").Add(Signature{Hash: 2}); m.Duplicate != "" {
+		t.Errorf("잘린 블록의 서명: %+v", m)
+	}
+}
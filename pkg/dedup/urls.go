@@ -0,0 +1,286 @@
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 같은 URL의 여러 캡처 중 남길 캡처
+const (
+	KeepFirst  = "first"  // WARC-Date가 가장 이른 캡처
+	KeepLatest = "latest" // WARC-Date가 가장 늦은 캡처
+)
+
+// URLStore는 정규 URL 키마다 남길 캡처(WARC-Date와 레코드 ID)를 기억하여 다른 캡처를 걸러냅니다.
+// 키와 레코드 ID는 64비트 해시로 저장하며, 남긴 캡처를 출처 파일별 블록으로 로그 파일(log.go)에 기록합니다.
+// 로그 항목은 "<키 해시> <WARC-Date 밀리초> <레코드 ID 해시>" 줄입니다.
+//
+// 캡처는 (WARC-Date, 레코드 ID 해시) 순서로 비교하므로 날짜가 같아도 처리 순서와 관계없이 남길 캡처가 하나로 정해집니다.
+// 파일을 병렬로 처리하므로 이미 기록된 캡처보다 나은 캡처가 나중에 나올 수 있습니다.
+// 이때 새 캡처를 남기고 이전 캡처를 기록한 출처를 URLSession.Superseded로 알려 주므로,
+// 그 출처를 한 번 더 처리하면 대체된 캡처가 빠집니다. 여러 고루틴에서 동시에 사용할 수 있습니다.
+type URLStore struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	latest  bool
+	winners map[uint64]capture
+	names   []string         // 출처 번호 → 이름
+	ids     map[string]int32 // 출처 이름 → 번호
+	keys    map[int32][]uint64
+}
+
+type capture struct {
+	date   int64
+	record uint64
+	source int32
+}
+
+// OpenURLs는 path의 URL 로그를 읽어 keep(KeepFirst, KeepLatest) 기준의 URLStore를 엽니다.
+func OpenURLs(path, keep string) (*URLStore, error) {
+	if keep != KeepFirst && keep != KeepLatest {
+		return nil, fmt.Errorf("dedup: 알 수 없는 캡처 기준: %s", keep)
+	}
+	s := &URLStore{
+		path:    path,
+		latest:  keep == KeepLatest,
+		winners: map[uint64]capture{},
+		ids:     map[string]int32{},
+		keys:    map[int32][]uint64{},
+	}
+
+	dirty, err := readLog(path, s.apply)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("dedup: URL 로그 읽기 오류(%s): %w", path, err)
+	}
+	if dirty {
+		var buf []byte
+		for id := range s.keys {
+			buf = s.block(buf, id)
+		}
+		if err := compactLog(path, buf); err != nil {
+			return nil, fmt.Errorf("dedup: URL 로그 압축 오류(%s): %w", path, err)
+		}
+	}
+
+	s.f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// apply는 로그 블록 하나를 색인에 반영합니다. 같은 출처의 이전 블록은 대체합니다.
+func (s *URLStore) apply(source string, lines []string) bool {
+	id := s.source(source)
+	replaced := len(s.keys[id]) > 0
+	s.forget(id)
+	for _, line := range lines {
+		f := strings.Fields(line)
+		if len(f) != 3 {
+			return false
+		}
+		key, err1 := strconv.ParseUint(f[0], 16, 64)
+		date, err2 := strconv.ParseInt(f[1], 10, 64)
+		record, err3 := strconv.ParseUint(f[2], 16, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return false
+		}
+		c := capture{date: date, record: record, source: id}
+		if old, ok := s.winners[key]; !ok || s.better(c, old) {
+			s.set(key, c)
+		}
+	}
+	return !replaced
+}
+
+// block은 출처 id가 현재 남기고 있는 캡처를 로그 블록으로 buf에 덧붙입니다.
+func (s *URLStore) block(buf []byte, id int32) []byte {
+	var lines []string
+	seen := map[uint64]bool{}
+	for _, key := range s.keys[id] {
+		if c, ok := s.winners[key]; ok && c.source == id && !seen[key] {
+			seen[key] = true
+			lines = append(lines, fmt.Sprintf("%016x %d %016x", key, c.date, c.record))
+		}
+	}
+	return appendBlock(buf, s.names[id], lines)
+}
+
+func (s *URLStore) source(name string) int32 {
+	id, ok := s.ids[name]
+	if !ok {
+		id = int32(len(s.names))
+		s.names = append(s.names, name)
+		s.ids[name] = id
+	}
+	return id
+}
+
+// better는 캡처 c가 현재 남긴 캡처 old보다 나은지 확인합니다.
+// 날짜가 같으면 레코드 ID 해시가 작은 쪽을 남겨, 어느 캡처를 먼저 보았는지가 결과에 영향을 주지 않게 합니다.
+func (s *URLStore) better(c, old capture) bool {
+	switch {
+	case c.date != old.date && s.latest:
+		return c.date > old.date
+	case c.date != old.date:
+		return c.date < old.date
+	}
+	return c.record < old.record
+}
+
+func (s *URLStore) set(key uint64, c capture) {
+	s.winners[key] = c
+	s.keys[c.source] = append(s.keys[c.source], key)
+}
+
+// forget은 출처 id가 남기고 있는 캡처를 지웁니다. s.mu를 잡은 상태에서 호출합니다.
+func (s *URLStore) forget(id int32) {
+	for _, key := range s.keys[id] {
+		if c, ok := s.winners[key]; ok && c.source == id {
+			delete(s.winners, key)
+		}
+	}
+	delete(s.keys, id)
+}
+
+// Close는 로그 파일을 닫습니다.
+func (s *URLStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// URLSession은 출처 파일 하나를 처리하는 동안의 URL 판정입니다.
+// 남긴 캡처는 바로 다른 URLSession의 판정에도 쓰이며, Commit하면 로그에 기록되고 Abort하면 되돌립니다.
+type URLSession struct {
+	s          *URLStore
+	id         int32
+	prior      map[uint64]capture // 이전 처리에서 이 출처가 남긴 캡처
+	blocked    map[uint64]bool    // prior 때문에 거른 키
+	undo       []urlUndo
+	superseded map[string]bool
+	done       bool
+}
+
+type urlUndo struct {
+	key  uint64
+	prev capture
+	had  bool
+}
+
+// Begin은 source의 URLSession을 시작합니다. 같은 출처를 다시 처리하는 경우이므로
+// 이전에 source가 남긴 캡처는 지우되, 파일 안에서 같은 URL의 캡처를 다시 처리할 때
+// 처리 순서와 관계없이 이전과 같은 캡처를 남기도록 기억해 둡니다.
+func (s *URLStore) Begin(source string) *URLSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.source(source)
+	prior := map[uint64]capture{}
+	for _, key := range s.keys[id] {
+		if c, ok := s.winners[key]; ok && c.source == id {
+			prior[key] = c
+		}
+	}
+	s.forget(id)
+	return &URLSession{s: s, id: id, prior: prior, blocked: map[uint64]bool{}, superseded: map[string]bool{}}
+}
+
+// Keep은 key(정규 URL)의 캡처 하나를 남길지 판정합니다. 이미 더 나은 캡처를 남겼으면 ok가 false이고,
+// 이전에 남긴 다른 캡처를 대체했으면 replaced가 true입니다.
+func (us *URLSession) Keep(key string, date time.Time, recordID string) (ok, replaced bool) {
+	s := us.s
+	k := hash64(key)
+	c := capture{date: date.UnixMilli(), record: hash64(recordID), source: us.id}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, had := s.winners[k]
+	if had && prev.record == c.record {
+		return true, false
+	}
+	if p, ok := us.prior[k]; ok && p.record != c.record && !s.better(c, p) {
+		us.blocked[k] = true
+		return false, false
+	}
+	if had {
+		if !s.better(c, prev) {
+			return false, false
+		}
+		us.superseded[s.names[prev.source]] = true
+	}
+	us.undo = append(us.undo, urlUndo{key: k, prev: prev, had: had})
+	s.set(k, c)
+	return true, had
+}
+
+// Superseded는 이 URLSession에서 더 나은 캡처로 대체된 캡처를 남긴 출처 이름입니다 (자기 자신 포함).
+func (us *URLSession) Superseded() []string {
+	us.s.mu.Lock()
+	defer us.s.mu.Unlock()
+	names := make([]string, 0, len(us.superseded))
+	for name := range us.superseded {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Commit은 이 출처가 남긴 캡처를 블록 하나로 로그에 기록하고 fsync합니다.
+// 이전에 남긴 캡처가 이번에 나오지 않아(설정 변경 등) 걸러낸 키가 있으면 자신도 Superseded에 넣어,
+// 다시 처리할 때 그 키의 다른 캡처를 남기게 합니다.
+func (us *URLSession) Commit() error {
+	s := us.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if us.done {
+		return nil
+	}
+	us.done = true
+	for k := range us.blocked {
+		if c, ok := s.winners[k]; !ok || c.source != us.id {
+			us.superseded[s.names[us.id]] = true
+			break
+		}
+	}
+	return appendLog(s.f, s.block(nil, us.id))
+}
+
+// Abort는 Commit하지 않은 URLSession의 판정을 되돌리고 이전에 남긴 캡처를 복원합니다.
+// 그 사이 다른 출처가 바꾼 키는 그대로 둡니다. Commit 이후 호출하면 아무 일도 하지 않습니다.
+func (us *URLSession) Abort() {
+	s := us.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if us.done {
+		return
+	}
+	us.done = true
+	for i := len(us.undo) - 1; i >= 0; i-- {
+		u := us.undo[i]
+		if c, ok := s.winners[u.key]; !ok || c.source != us.id {
+			continue
+		}
+		if u.had {
+			s.set(u.key, u.prev)
+		} else {
+			delete(s.winners, u.key)
+		}
+	}
+	delete(s.keys, us.id)
+	for k, c := range us.prior {
+		if _, ok := s.winners[k]; !ok {
+			s.set(k, c)
+		}
+	}
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,146 @@
+package dedup
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func openURLs(t *testing.T, path, keep string) *URLStore {
+	t.Helper()
+	s, err := OpenURLs(path, keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+type testCapture struct {
+	source string
+	date   time.Time
+	id     string
+}
+
+// keepAll은 captures를 순서대로 같은 URL로 판정하고 (출처마다 Session 하나) 마지막으로 남은 캡처의 ID를 반환합니다.
+func keepAll(t *testing.T, keep string, captures []testCapture) string {
+	t.Helper()
+	s := openURLs(t, filepath.Join(t.TempDir(), "urls.sig"), keep)
+	var winner string
+	for _, c := range captures {
+		us := s.Begin(c.source)
+		if ok, _ := us.Keep("com,example)/a", c.date, c.id); ok {
+			winner = c.id
+		}
+		if err := us.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return winner
+}
+
+func TestURLStoreWinner(t *testing.T) {
+	d1 := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	d2 := d1.Add(time.Hour)
+	// 날짜가 같으면 레코드 ID 해시가 작은 쪽이 남음
+	lo, hi := "<urn:uuid:1>", "<urn:uuid:2>"
+	if hash64(lo) > hash64(hi) {
+		lo, hi = hi, lo
+	}
+
+	tests := []struct {
+		name     string
+		keep     string
+		captures []testCapture
+		want     string
+	}{
+		{"first 이른 캡처 먼저", KeepFirst, []testCapture{{"a", d1, "early"}, {"b", d2, "late"}}, "early"},
+		{"first 늦은 캡처 먼저", KeepFirst, []testCapture{{"a", d2, "late"}, {"b", d1, "early"}}, "early"},
+		{"latest 이른 캡처 먼저", KeepLatest, []testCapture{{"a", d1, "early"}, {"b", d2, "late"}}, "late"},
+		{"latest 늦은 캡처 먼저", KeepLatest, []testCapture{{"a", d2, "late"}, {"b", d1, "early"}}, "late"},
+		{"같은 날짜 작은 해시 먼저", KeepFirst, []testCapture{{"a", d1, lo}, {"b", d1, hi}}, lo},
+		{"같은 날짜 큰 해시 먼저", KeepFirst, []testCapture{{"a", d1, hi}, {"b", d1, lo}}, lo},
+		{"latest 같은 날짜", KeepLatest, []testCapture{{"a", d1, hi}, {"b", d1, lo}}, lo},
+		{"한 파일 안의 캡처", KeepLatest, []testCapture{{"a", d1, "early"}, {"a", d2, "late"}}, "late"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keepAll(t, tt.keep, tt.captures); got != tt.want {
+				t.Errorf("남은 캡처 %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := OpenURLs(filepath.Join(t.TempDir(), "urls.sig"), "middle"); err == nil {
+		t.Error("알 수 없는 기준에서 오류가 없습니다")
+	}
+}
+
+func TestURLSessionSuperseded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.sig")
+	d1 := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	d2 := d1.Add(time.Hour)
+
+	s := openURLs(t, path, KeepFirst)
+	a := s.Begin("a")
+	if ok, replaced := a.Keep("k", d2, "late"); !ok || replaced {
+		t.Fatalf("a Keep = %v, %v", ok, replaced)
+	}
+	if err := a.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := s.Begin("b")
+	if ok, replaced := b.Keep("k", d1, "early"); !ok || !replaced {
+		t.Errorf("b Keep = %v, %v, want true, true", ok, replaced)
+	}
+	if ok, _ := b.Keep("k", d2, "late"); ok {
+		t.Error("대체된 캡처를 다시 남겼습니다")
+	}
+	if got := b.Superseded(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("Superseded = %v, want [a]", got)
+	}
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// 다시 열어도 b의 캡처가 남음
+	s = openURLs(t, path, KeepFirst)
+	if ok, _ := s.Begin("c").Keep("k", d2, "late"); ok {
+		t.Error("다시 연 뒤 대체된 캡처를 남겼습니다")
+	}
+}
+
+// Abort하면 이전 처리에서 남긴 캡처를 복원합니다.
+func TestURLSessionAbort(t *testing.T) {
+	d1 := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	s := openURLs(t, filepath.Join(t.TempDir(), "urls.sig"), KeepFirst)
+	a := s.Begin("a")
+	a.Keep("k", d1.Add(time.Hour), "a1")
+	if err := a.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := s.Begin("b")
+	b.Keep("k", d1, "b1")
+	b.Keep("new", d1, "b2")
+	b.Abort()
+	b.Abort()
+
+	c := s.Begin("c")
+	if ok, _ := c.Keep("k", d1.Add(2*time.Hour), "c1"); ok {
+		t.Error("Abort 뒤 a의 캡처가 복원되지 않았습니다")
+	}
+	if ok, replaced := c.Keep("new", d1.Add(time.Hour), "c2"); !ok || replaced {
+		t.Errorf("Abort한 캡처가 남았습니다: %v, %v", ok, replaced)
+	}
+
+	// 같은 출처를 다시 처리하다 Abort해도 이전 캡처가 남음
+	a = s.Begin("a")
+	a.Abort()
+	if ok, _ := s.Begin("d").Keep("k", d1.Add(2*time.Hour), "d1"); ok {
+		t.Error("다시 처리를 Abort한 뒤 a의 캡처가 없습니다")
+	}
+}
@@ -0,0 +1,319 @@
+// Package langid는 외부 서비스 없이 텍스트의 언어를 판별합니다.
+//
+// 먼저 문자 체계(script)를 세어 한국어(한글), 일본어(가나), 중국어(한자만), 아랍어, 히브리어, 태국어,
+// 그리스어, 힌디어(데바나가리)처럼 문자만으로 정해지는 언어를 판별하고, 라틴·키릴 문자 텍스트는
+// corpus/의 언어별 표본 텍스트(언어마다 수십 KB의 기사체 문장)로 만든 문자 3-gram 빈도 모델(나이브 베이즈)로 판별합니다.
+// 한글 음절 하나는 자모 2~3개를 담고 있으므로 문자 체계를 비교할 때 글자 수 대신 자모 수로 셉니다.
+package langid
+
+import (
+	"embed"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Result는 판별 결과입니다. Lang은 ISO 639-1 코드이며, 판별할 수 없으면 빈 문자열입니다.
+// Confidence는 0~1 사이의 신뢰도입니다.
+type Result struct {
+	Lang       string
+	Confidence float64
+}
+
+const (
+	maxRunes   = 10000 // 판별에 쓰는 텍스트 앞부분의 최대 문자 수
+	minLetters = 20    // 판별에 필요한 최소 글자 수
+	evidenceN  = 50    // 신뢰도 계산에서 3-gram 증거를 최대 이만큼만 반영 (짧은 텍스트일수록 낮은 신뢰도)
+	smoothing  = 0.5   // 표본에 없는 3-gram의 가산 평활 값
+)
+
+// scriptLangs는 문자 체계만으로 정하는 언어입니다.
+var scriptLangs = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Devanagari, "hi"},
+}
+
+// 3-gram 모델로 판별하는 문자 체계
+const (
+	scriptLatin    = "latin"
+	scriptCyrillic = "cyrillic"
+)
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// profile은 언어 하나의 3-gram 로그 확률 모델입니다.
+type profile struct {
+	lang    string
+	script  string
+	logProb map[string]float64
+	unseen  float64 // 표본에 없는 3-gram의 로그 확률
+}
+
+var profiles = sync.OnceValue(loadProfiles)
+
+// loadProfiles는 corpus/<언어>.txt 표본에서 언어별 3-gram 모델을 만듭니다.
+func loadProfiles() []*profile {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+	var ps []*profile
+	for _, e := range entries {
+		text, err := corpus.ReadFile(path.Join("corpus", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		counts := map[string]int{}
+		total := 0
+		letters := map[string]int{}
+		for _, g := range trigrams(string(text), maxRunes*10) {
+			counts[g]++
+			total++
+		}
+		for _, r := range string(text) {
+			if unicode.IsLetter(r) {
+				letters[scriptOf(r)]++
+			}
+		}
+
+		p := &profile{
+			lang:    strings.TrimSuffix(e.Name(), ".txt"),
+			script:  scriptLatin,
+			logProb: make(map[string]float64, len(counts)),
+		}
+		if letters[scriptCyrillic] > letters[scriptLatin] {
+			p.script = scriptCyrillic
+		}
+		denom := float64(total) + smoothing*float64(len(counts)+1)
+		for g, n := range counts {
+			p.logProb[g] = math.Log((float64(n) + smoothing) / denom)
+		}
+		p.unseen = math.Log(smoothing / denom)
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// Languages는 판별할 수 있는 언어 코드를 정렬하여 반환합니다.
+func Languages() []string {
+	langs := []string{"ko", "ja", "zh"}
+	for _, s := range scriptLangs {
+		langs = append(langs, s.lang)
+	}
+	for _, p := range profiles() {
+		langs = append(langs, p.lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supported는 lang을 판별할 수 있는지 확인합니다.
+func Supported(lang string) bool {
+	for _, l := range Languages() {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// 한글·가나·한자 한 글자를 라틴 문자 몇 글자로 셀지 정하는 가중치입니다.
+// 한글 음절은 자모 수(받침이 있으면 3, 없으면 2), 가나는 자음과 모음 한 쌍, 한자는 한 음절(병음 평균 약 3자)로 봅니다.
+const (
+	kanaWeight = 2
+	hanWeight  = 3
+)
+
+// Detect는 text의 언어를 판별합니다. URL과 이메일 주소는 문자 체계와 3-gram 계산에서 뺍니다.
+// 한국어·일본어·중국어와 다른 문자 체계가 섞여 있으면 위 가중치로 센 글자 수가 많은 쪽을 고르므로,
+// 영문 이름이나 주소가 섞인 한국어 기사도 한국어로 판별합니다.
+func Detect(text string) Result {
+	text = stripAddresses(text)
+	var letters, hangul, kana, han, cjkWeight, otherWeight int
+	scripts := map[string]int{}
+	n := 0
+	for _, r := range text {
+		if n++; n > maxRunes {
+			break
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+			cjkWeight += jamoCount(r)
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+			cjkWeight += kanaWeight
+		case unicode.Is(unicode.Han, r):
+			han++
+			cjkWeight += hanWeight
+		default:
+			scripts[scriptOf(r)]++
+			otherWeight++
+		}
+	}
+	if letters < minLetters {
+		return Result{}
+	}
+
+	// 가장 많이 쓰인 문자 체계. 한글·가나와 함께 쓰인 한자는 한국어·일본어 글자로 셈
+	cjk := hangul + kana + han
+	best, bestN := "", 0
+	for s, c := range scripts {
+		if c > bestN || (c == bestN && s < best) {
+			best, bestN = s, c
+		}
+	}
+	if cjk > 0 && cjkWeight >= bestN {
+		share := float64(cjkWeight) / float64(cjkWeight+otherWeight)
+		switch {
+		case hangul > 0 && hangul*3 >= cjk:
+			return result("ko", share)
+		case kana > 0 && kana*10 >= kana+han:
+			return result("ja", share)
+		default:
+			return result("zh", share)
+		}
+	}
+
+	share := float64(bestN) / float64(cjkWeight+otherWeight)
+	for _, s := range scriptLangs {
+		if best == s.lang {
+			return result(s.lang, share)
+		}
+	}
+	if best != scriptLatin && best != scriptCyrillic {
+		return Result{}
+	}
+	return classify(text, best, share)
+}
+
+// classify는 script 문자 체계의 3-gram 모델 중 로그 우도가 가장 높은 언어를 고릅니다.
+// 신뢰도는 3-gram당 평균 로그 우도에 최대 evidenceN개의 증거를 곱한 값의 softmax에 문자 체계 비율을 곱한 값입니다.
+func classify(text, script string, share float64) Result {
+	grams := trigrams(text, maxRunes)
+	if len(grams) == 0 {
+		return Result{}
+	}
+
+	var langs []string
+	var scores []float64
+	for _, p := range profiles() {
+		if p.script != script {
+			continue
+		}
+		var sum float64
+		for _, g := range grams {
+			if lp, ok := p.logProb[g]; ok {
+				sum += lp
+			} else {
+				sum += p.unseen
+			}
+		}
+		langs = append(langs, p.lang)
+		scores = append(scores, sum/float64(len(grams))*float64(min(len(grams), evidenceN)))
+	}
+	if len(langs) == 0 {
+		return Result{}
+	}
+
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	var z float64
+	for _, s := range scores {
+		z += math.Exp(s - scores[best])
+	}
+	return result(langs[best], share/z)
+}
+
+func result(lang string, confidence float64) Result {
+	return Result{Lang: lang, Confidence: math.Round(confidence*1000) / 1000}
+}
+
+// trigrams는 소문자로 바꾼 단어마다 앞뒤에 공백을 붙여 문자 3-gram을 만듭니다.
+// 숫자와 문장부호는 단어 경계로 봅니다. 텍스트 앞부분 limit 문자까지만 봅니다.
+func trigrams(text string, limit int) []string {
+	var grams []string
+	word := []rune{' '}
+	flush := func() {
+		if len(word) > 1 {
+			word = append(word, ' ')
+			for i := 0; i+3 <= len(word); i++ {
+				grams = append(grams, string(word[i:i+3]))
+			}
+		}
+		word = word[:1]
+	}
+	n := 0
+	for _, r := range text {
+		if n++; n > limit {
+			break
+		}
+		if unicode.IsLetter(r) || r == '\'' && len(word) > 1 {
+			word = append(word, unicode.ToLower(r))
+			continue
+		}
+		flush()
+	}
+	flush()
+	return grams
+}
+
+// jamoCount는 한글 글자 r의 자모 수입니다. 완성형 음절은 받침 유무에 따라 3 또는 2이고, 낱자모는 1입니다.
+func jamoCount(r rune) int {
+	const first, last, finals = 0xAC00, 0xD7A3, 28
+	switch {
+	case r < first || r > last:
+		return 1
+	case (r-first)%finals == 0:
+		return 2
+	}
+	return 3
+}
+
+// stripAddresses는 URL(://가 있거나 www.로 시작하는 단어)과 이메일 주소를 공백으로 바꿉니다.
+func stripAddresses(text string) string {
+	if !strings.Contains(text, "://") && !strings.Contains(text, "www.") && !strings.Contains(text, "@") {
+		return text
+	}
+	fields := strings.Fields(text)
+	for i, f := range fields {
+		if strings.Contains(f, "://") || strings.HasPrefix(f, "www.") || strings.Contains(f, "@") && strings.Contains(f, ".") {
+			fields[i] = ""
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// scriptOf는 글자의 문자 체계 이름입니다. scriptLangs에 있는 문자 체계는 언어 코드를 씁니다.
+func scriptOf(r rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return scriptLatin
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic
+	}
+	for _, s := range scriptLangs {
+		if unicode.Is(s.table, r) {
+			return s.lang
+		}
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package langid
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		lang string
+	}{
+		{"ko", "서울시는 내년부터 시내버스 요금을 인상하기로 했다고 밝혔다. 시민단체는 서민 부담이 커진다며 반발하고 있다.", "ko"},
+		{"ko 짧은 기사", "정부가 최저임금 인상안을 발표했다. 노동계는 환영했다.", "ko"},
+		{"ko+en 이름과 URL", "Apple CEO Tim Cook은 지난 9일 California Cupertino 본사에서 열린 행사에서 새 iPhone을 공개했다. " +
+			"자세한 내용은 https://www.apple.com/newsroom/2025/09/introducing-the-new-iphone/ 에서 볼 수 있다.", "ko"},
+		{"ko+en 약어", "NASA와 ESA, JAXA가 공동 개발한 James Webb Space Telescope 관측 결과가 Nature에 실렸다.", "ko"},
+		{"ja", "東京都は来年度から都営バスの運賃を値上げすると発表した。利用者からは反対の声が上がっている。", "ja"},
+		{"ja+en", "AppleのTim Cook CEOは、新しいiPhoneをCaliforniaの本社で発表しました。", "ja"},
+		{"zh", "北京市政府宣布，明年起将提高公交车票价。许多市民对此表示不满，认为会增加生活负担。", "zh"},
+		{"en", "The city council voted on Tuesday to expand the bike lane network, despite objections from local shop owners.", "en"},
+		{"en 이메일", "Readers can send their questions to letters@example.com and the editors will answer a selection of them every Sunday.", "en"},
+		{"de", "Die Stadtverwaltung hat am Dienstag beschlossen, das Radwegenetz auszubauen, obwohl viele Geschäftsleute dagegen protestiert haben.", "de"},
+		{"fr", "Le conseil municipal a voté mardi l'extension du réseau de pistes cyclables, malgré l'opposition des commerçants du quartier.", "fr"},
+		{"es", "El ayuntamiento aprobó el martes la ampliación de la red de carriles bici, a pesar de las quejas de los comerciantes.", "es"},
+		{"pt", "A câmara municipal aprovou na terça-feira a ampliação da rede de ciclovias, apesar das queixas dos comerciantes do bairro.", "pt"},
+		{"ru", "Городской совет во вторник проголосовал за расширение сети велосипедных дорожек, несмотря на возражения владельцев магазинов.", "ru"},
+		{"uk", "Міська рада у вівторок проголосувала за розширення мережі велосипедних доріжок, попри заперечення власників крамниць.", "uk"},
+		{"ar", "أعلنت الحكومة يوم الثلاثاء عن خطة جديدة لتوسيع شبكة النقل العام في العاصمة خلال السنوات المقبلة.", "ar"},
+		{"짧은 입력", "안녕하세요", ""},
+		{"짧은 영어", "Hello world", ""},
+		{"빈 입력", "", ""},
+		{"숫자와 기호", "2025-04-01 12:00:00 +0900 | 1,234.56 $ € ¥ ### ---- ****", ""},
+		{"URL만", "https://example.com/a/very/long/path/that/has/many/letters/in/it/index.html", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.text)
+			if got.Lang != tt.lang {
+				t.Errorf("Detect() = %+v, want %q", got, tt.lang)
+			}
+			if tt.lang == "" && got.Confidence != 0 {
+				t.Errorf("판별하지 못했는데 신뢰도가 %v", got.Confidence)
+			}
+			if tt.lang != "" && (got.Confidence <= 0 || got.Confidence > 1) {
+				t.Errorf("신뢰도 %v가 (0, 1] 밖입니다", got.Confidence)
+			}
+		})
+	}
+}
+
+func TestJamoCount(t *testing.T) {
+	for _, tt := range []struct {
+		r    rune
+		want int
+	}{
+		{'가', 2}, {'각', 3}, {'힣', 3}, {'ㄱ', 1}, {'ㅏ', 1},
+	} {
+		if got := jamoCount(tt.r); got != tt.want {
+			t.Errorf("jamoCount(%q) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for _, lang := range []string{"ko", "ja", "zh", "en", "ru", "ar"} {
+		if !Supported(lang) {
+			t.Errorf("Supported(%q) = false", lang)
+		}
+	}
+	if Supported("xx") {
+		t.Error(`Supported("xx") = true`)
+	}
+}
@@ -0,0 +1,111 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Thrift compact 프로토콜 필드 타입
+const (
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// compactWriter는 Parquet 메타데이터(FileMetaData, PageHeader)를 Thrift compact 프로토콜로 인코딩합니다.
+// 필드는 id 오름차순으로 기록해야 하며, 구조체가 중첩되면 이전 필드 id를 스택에 보존합니다.
+type compactWriter struct {
+	buf    []byte
+	last   int16
+	parent []int16
+}
+
+func (w *compactWriter) varint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+func (w *compactWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// field는 필드 헤더를 기록합니다. id 차이가 1~15이면 한 바이트로 줄여 씁니다.
+func (w *compactWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.zigzag(int64(id))
+	}
+	w.last = id
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, ctI32)
+	w.zigzag(int64(v))
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, ctI64)
+	w.zigzag(v)
+}
+
+func (w *compactWriter) binary(id int16, v []byte) {
+	w.field(id, ctBinary)
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *compactWriter) string(id int16, v string) {
+	w.binary(id, []byte(v))
+}
+
+// beginStruct는 구조체 필드를 시작합니다. 구조체 안의 필드 id는 0부터 다시 셉니다.
+func (w *compactWriter) beginStruct(id int16) {
+	w.field(id, ctStruct)
+	w.push()
+}
+
+func (w *compactWriter) push() {
+	w.parent = append(w.parent, w.last)
+	w.last = 0
+}
+
+// endStruct는 STOP 바이트를 기록하고 바깥 구조체의 필드 id를 복원합니다.
+func (w *compactWriter) endStruct() {
+	w.buf = append(w.buf, 0)
+	w.last = w.parent[len(w.parent)-1]
+	w.parent = w.parent[:len(w.parent)-1]
+}
+
+// list는 리스트 필드 헤더를 기록합니다. 이어서 원소 n개를 elem 타입으로 기록해야 합니다.
+func (w *compactWriter) list(id int16, elem byte, n int) {
+	w.field(id, ctList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elem)
+	} else {
+		w.buf = append(w.buf, 0xf0|elem)
+		w.varint(uint64(n))
+	}
+}
+
+// listI32는 i32 리스트 원소 하나를 기록합니다.
+func (w *compactWriter) listI32(v int32) {
+	w.zigzag(int64(v))
+}
+
+// listString은 binary 리스트 원소 하나를 기록합니다.
+func (w *compactWriter) listString(v string) {
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// beginListStruct는 구조체 리스트 원소 하나를 시작합니다. endStruct로 닫습니다.
+func (w *compactWriter) beginListStruct() {
+	w.push()
+}
+
+// stop은 최상위 구조체를 닫습니다.
+func (w *compactWriter) stop() {
+	w.buf = append(w.buf, 0)
+}
@@ -0,0 +1,516 @@
+// Package parquet는 중첩 없는 평면 스키마의 Parquet 파일을 쓰는 최소한의 기능을 제공합니다.
+// 값은 PLAIN, 정의 레벨은 RLE로 인코딩한 DATA_PAGE(v1)만 사용하며,
+// 행 그룹 크기와 페이지 압축 방식(UNCOMPRESSED, SNAPPY, GZIP, ZSTD)을 지정할 수 있습니다.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// magic은 Parquet 파일의 시작과 끝에 오는 4바이트입니다.
+const magic = "PAR1"
+
+// Type은 Parquet 물리 타입입니다.
+type Type int32
+
+const (
+	Int32     Type = 1
+	Int64     Type = 2
+	Double    Type = 5
+	ByteArray Type = 6
+)
+
+// Logical은 물리 타입에 붙이는 논리 타입(ConvertedType)입니다.
+type Logical int
+
+const (
+	None            Logical = iota
+	String                  // UTF-8 문자열 (ByteArray)
+	JSON                    // JSON 문서 (ByteArray)
+	TimestampMillis         // 1970-01-01 UTC 기준 밀리초 (Int64)
+)
+
+// convertedType은 Logical에 해당하는 Thrift ConvertedType 값입니다.
+var convertedType = map[Logical]int32{String: 0, JSON: 19, TimestampMillis: 9}
+
+// Codec은 페이지 압축 방식입니다.
+type Codec int32
+
+const (
+	Uncompressed Codec = 0
+	Snappy       Codec = 1
+	Gzip         Codec = 2
+	Zstd         Codec = 6
+)
+
+// Parquet 인코딩과 페이지 종류
+const (
+	encodingPlain = 0
+	encodingRLE   = 3
+	pageData      = 0
+)
+
+// Column은 스키마의 열 하나입니다. Required가 false이면 null(nil)을 허용합니다.
+type Column struct {
+	Name     string
+	Type     Type
+	Logical  Logical
+	Required bool
+}
+
+// Options는 Writer 설정입니다. 0인 값은 기본값을 씁니다.
+type Options struct {
+	Codec        Codec
+	RowGroupSize int64             // 행 그룹을 닫는 압축 전 크기 (기본 64 MiB)
+	PageSize     int64             // 페이지를 닫는 압축 전 크기 (기본 1 MiB)
+	CreatedBy    string            // 파일 메타데이터의 created_by
+	KeyValue     map[string]string // 파일 메타데이터의 key_value_metadata
+}
+
+// 기본 크기
+const (
+	DefaultRowGroupSize = 64 << 20
+	DefaultPageSize     = 1 << 20
+)
+
+// zstdEncoder는 모든 Writer가 함께 쓰는 zstd 인코더입니다. EncodeAll은 동시에 호출할 수 있으므로
+// 파티션마다 Writer가 수천 개 있어도 인코더는 하나만 만듭니다.
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+})
+
+// Writer는 행을 열별로 모아 행 그룹 단위로 기록합니다. 동시에 사용할 수 없습니다.
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	opts    Options
+	zw      *zstd.Encoder
+
+	offset    int64
+	started   bool
+	chunks    []*columnChunk
+	rows      int64 // 현재 행 그룹의 행 수
+	buffered  int64 // 현재 행 그룹의 압축 전 크기
+	rowGroups []rowGroup
+	totalRows int64
+	closed    bool
+}
+
+// columnChunk는 현재 행 그룹에서 열 하나의 페이지를 모읍니다.
+type columnChunk struct {
+	col        Column
+	defs       []byte // 현재 페이지의 정의 레벨 (0: null, 1: 값 있음)
+	values     bytes.Buffer
+	pageValues int
+
+	pages        bytes.Buffer // 닫힌 페이지(헤더 + 압축된 본문)
+	numValues    int64
+	uncompressed int64
+	compressed   int64
+}
+
+type rowGroup struct {
+	columns    []chunkMeta
+	numRows    int64
+	totalBytes int64
+	compressed int64
+	fileOffset int64
+}
+
+type chunkMeta struct {
+	offset       int64
+	numValues    int64
+	uncompressed int64
+	compressed   int64
+}
+
+// NewWriter는 columns 스키마로 w에 기록하는 Writer를 만듭니다.
+func NewWriter(w io.Writer, columns []Column, opts Options) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: 열이 없습니다")
+	}
+	if opts.RowGroupSize <= 0 {
+		opts.RowGroupSize = DefaultRowGroupSize
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+
+	pw := &Writer{w: w, columns: columns, opts: opts}
+	switch opts.Codec {
+	case Uncompressed, Snappy, Gzip:
+	case Zstd:
+		zw, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		pw.zw = zw
+	default:
+		return nil, fmt.Errorf("parquet: 지원하지 않는 압축 방식: %d", opts.Codec)
+	}
+
+	pw.chunks = make([]*columnChunk, len(columns))
+	for i, col := range columns {
+		pw.chunks[i] = &columnChunk{col: col}
+	}
+	return pw, nil
+}
+
+// Write는 행 하나를 추가합니다. row는 열 순서대로의 값이며, nil은 null입니다.
+// ByteArray는 string/[]byte, Int32는 int32/int, Int64는 int64/int/time.Time(TimestampMillis), Double은 float64를 받습니다.
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return errors.New("parquet: 닫힌 Writer")
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: 열 %d개에 값 %d개", len(w.columns), len(row))
+	}
+
+	// 잘못된 값이 있으면 어느 열에도 추가하지 않도록 먼저 모두 인코딩
+	encoded := make([][]byte, len(row))
+	for i, v := range row {
+		b, err := w.chunks[i].encode(v)
+		if err != nil {
+			return fmt.Errorf("parquet: 열 %s: %w", w.columns[i].Name, err)
+		}
+		encoded[i] = b
+	}
+	for i, c := range w.chunks {
+		c.add(encoded[i])
+		w.buffered += int64(len(encoded[i])) + 1
+	}
+	w.rows++
+
+	for _, c := range w.chunks {
+		if int64(c.values.Len()) >= w.opts.PageSize {
+			if err := w.flushPage(c); err != nil {
+				return err
+			}
+		}
+	}
+	if w.buffered >= w.opts.RowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Buffered는 아직 기록하지 않은 현재 행 그룹의 압축 전 크기입니다.
+func (w *Writer) Buffered() int64 {
+	return w.buffered
+}
+
+// encode는 값을 PLAIN 인코딩으로 바꿉니다. null이면 nil을 반환합니다.
+func (c *columnChunk) encode(v any) ([]byte, error) {
+	if v == nil {
+		if c.col.Required {
+			return nil, errors.New("필수 열에 null")
+		}
+		return nil, nil
+	}
+
+	switch c.col.Type {
+	case ByteArray:
+		var p []byte
+		switch v := v.(type) {
+		case string:
+			p = []byte(v)
+		case []byte:
+			p = v
+		default:
+			return nil, fmt.Errorf("ByteArray에 %T", v)
+		}
+		return append(binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(p)), uint32(len(p))), p...), nil
+	case Int32:
+		var n int32
+		switch v := v.(type) {
+		case int32:
+			n = v
+		case int:
+			n = int32(v)
+		default:
+			return nil, fmt.Errorf("Int32에 %T", v)
+		}
+		return binary.LittleEndian.AppendUint32(nil, uint32(n)), nil
+	case Int64:
+		var n int64
+		switch v := v.(type) {
+		case int64:
+			n = v
+		case int:
+			n = int64(v)
+		case time.Time:
+			n = v.UnixMilli()
+		default:
+			return nil, fmt.Errorf("Int64에 %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(nil, uint64(n)), nil
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("Double에 %T", v)
+		}
+		return binary.LittleEndian.AppendUint64(nil, math.Float64bits(f)), nil
+	}
+	return nil, fmt.Errorf("지원하지 않는 타입: %d", c.col.Type)
+}
+
+// add는 encode한 값을 현재 페이지에 추가합니다. nil은 null입니다.
+func (c *columnChunk) add(b []byte) {
+	c.pageValues++
+	if !c.col.Required {
+		if b == nil {
+			c.defs = append(c.defs, 0)
+			return
+		}
+		c.defs = append(c.defs, 1)
+	}
+	c.values.Write(b)
+}
+
+// flushPage는 열의 현재 페이지를 압축하여 DATA_PAGE로 닫습니다.
+func (w *Writer) flushPage(c *columnChunk) error {
+	if c.pageValues == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	if !c.col.Required {
+		levels := encodeLevels(c.defs)
+		var n [4]byte
+		binary.LittleEndian.PutUint32(n[:], uint32(len(levels)))
+		body.Write(n[:])
+		body.Write(levels)
+	}
+	body.Write(c.values.Bytes())
+
+	data, err := w.compress(body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var h compactWriter
+	h.i32(1, pageData)
+	h.i32(2, int32(body.Len()))
+	h.i32(3, int32(len(data)))
+	h.beginStruct(5)
+	h.i32(1, int32(c.pageValues))
+	h.i32(2, encodingPlain)
+	h.i32(3, encodingRLE)
+	h.i32(4, encodingRLE)
+	h.endStruct()
+	h.stop()
+
+	c.pages.Write(h.buf)
+	c.pages.Write(data)
+	c.numValues += int64(c.pageValues)
+	c.uncompressed += int64(len(h.buf) + body.Len())
+	c.compressed += int64(len(h.buf) + len(data))
+
+	c.defs = c.defs[:0]
+	c.values.Reset()
+	c.pageValues = 0
+	return nil
+}
+
+// encodeLevels는 비트 폭 1의 정의 레벨을 RLE/비트 패킹 혼합 인코딩의 RLE 런으로 인코딩합니다.
+func encodeLevels(levels []byte) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		out = append(out, levels[i])
+		i = j
+	}
+	return out
+}
+
+func (w *Writer) compress(p []byte) ([]byte, error) {
+	switch w.opts.Codec {
+	case Snappy:
+		return snappy.Encode(nil, p), nil
+	case Gzip:
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(p); err != nil {
+			return nil, err
+		}
+		if err := gw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		return w.zw.EncodeAll(p, nil), nil
+	}
+	return p, nil
+}
+
+func (w *Writer) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write([]byte(magic))
+}
+
+// Flush는 모은 행을 행 그룹 하나로 기록합니다. 모은 행이 없으면 아무 일도 하지 않습니다.
+func (w *Writer) Flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	rg := rowGroup{numRows: w.rows, fileOffset: w.offset}
+	for _, c := range w.chunks {
+		if err := w.flushPage(c); err != nil {
+			return err
+		}
+		meta := chunkMeta{
+			offset:       w.offset,
+			numValues:    c.numValues,
+			uncompressed: c.uncompressed,
+			compressed:   c.compressed,
+		}
+		if err := w.write(c.pages.Bytes()); err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, meta)
+		rg.totalBytes += c.uncompressed
+		rg.compressed += c.compressed
+
+		c.pages.Reset()
+		c.numValues, c.uncompressed, c.compressed = 0, 0, 0
+	}
+
+	w.rowGroups = append(w.rowGroups, rg)
+	w.totalRows += w.rows
+	w.rows = 0
+	w.buffered = 0
+	return nil
+}
+
+// Close는 남은 행을 기록하고 파일 메타데이터(footer)를 씁니다. 기반 io.Writer는 닫지 않습니다.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	footer := w.fileMetaData()
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer)))
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	if err := w.write(n[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+// fileMetaData는 FileMetaData 구조체를 Thrift compact 프로토콜로 인코딩합니다.
+func (w *Writer) fileMetaData() []byte {
+	var m compactWriter
+	m.i32(1, 1)
+
+	// 스키마: 루트 요소 다음에 열들이 옴
+	m.list(2, ctStruct, len(w.columns)+1)
+	m.beginListStruct()
+	m.string(4, "schema")
+	m.i32(5, int32(len(w.columns)))
+	m.endStruct()
+	for _, col := range w.columns {
+		m.beginListStruct()
+		m.i32(1, int32(col.Type))
+		if col.Required {
+			m.i32(3, 0)
+		} else {
+			m.i32(3, 1)
+		}
+		m.string(4, col.Name)
+		if ct, ok := convertedType[col.Logical]; ok {
+			m.i32(6, ct)
+		}
+		m.endStruct()
+	}
+
+	m.i64(3, w.totalRows)
+
+	m.list(4, ctStruct, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		m.beginListStruct()
+		m.list(1, ctStruct, len(rg.columns))
+		for i, cm := range rg.columns {
+			col := w.columns[i]
+			m.beginListStruct()
+			m.i64(2, cm.offset)
+			m.beginStruct(3)
+			m.i32(1, int32(col.Type))
+			m.list(2, ctI32, 2)
+			m.listI32(encodingPlain)
+			m.listI32(encodingRLE)
+			m.list(3, ctBinary, 1)
+			m.listString(col.Name)
+			m.i32(4, int32(w.opts.Codec))
+			m.i64(5, cm.numValues)
+			m.i64(6, cm.uncompressed)
+			m.i64(7, cm.compressed)
+			m.i64(9, cm.offset)
+			m.endStruct()
+			m.endStruct()
+		}
+		m.i64(2, rg.totalBytes)
+		m.i64(3, rg.numRows)
+		m.i64(5, rg.fileOffset)
+		m.i64(6, rg.compressed)
+		m.endStruct()
+	}
+
+	if len(w.opts.KeyValue) > 0 {
+		keys := make([]string, 0, len(w.opts.KeyValue))
+		for k := range w.opts.KeyValue {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		m.list(5, ctStruct, len(keys))
+		for _, k := range keys {
+			m.beginListStruct()
+			m.string(1, k)
+			m.string(2, w.opts.KeyValue[k])
+			m.endStruct()
+		}
+	}
+	if w.opts.CreatedBy != "" {
+		m.string(6, w.opts.CreatedBy)
+	}
+	m.stop()
+	return m.buf
+}
@@ -0,0 +1,492 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 이 파일의 reader는 Writer의 인코더를 쓰지 않고 Parquet 형식 명세(parquet.thrift, Encodings.md)와
+// Thrift compact 프로토콜 명세만으로 파일을 다시 읽어, 기록한 값과 메타데이터를 비교합니다.
+
+// compactReader는 Thrift compact 프로토콜 값을 일반 구조(struct는 필드 id → 값 맵)로 읽습니다.
+type compactReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *compactReader) byte() byte {
+	c := r.b[r.pos]
+	r.pos++
+	return c
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		panic("잘못된 varint")
+	}
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 3:
+		return int8(r.byte())
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.b[r.pos:]))
+		r.pos += 8
+		return v
+	case 8:
+		n := int(r.uvarint())
+		v := r.b[r.pos : r.pos+n]
+		r.pos += n
+		return v
+	case 9, 10:
+		h := r.byte()
+		n, elem := int(h>>4), h&0x0f
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			if elem == 1 || elem == 2 {
+				list[i] = r.byte() == 1
+				continue
+			}
+			list[i] = r.value(elem)
+		}
+		return list
+	case 12:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("지원하지 않는 compact 타입 %d", typ))
+}
+
+func (r *compactReader) structure() map[int]any {
+	fields := map[int]any{}
+	var last int
+	for {
+		h := r.byte()
+		if h == 0 {
+			return fields
+		}
+		typ := h & 0x0f
+		id := last + int(h>>4)
+		if h>>4 == 0 {
+			id = int(r.zigzag())
+		}
+		fields[id] = r.value(typ)
+		last = id
+	}
+}
+
+func field[T any](s map[int]any, id int) T {
+	v, _ := s[id].(T)
+	return v
+}
+
+// readFile은 Parquet 파일을 읽어 스키마, 열 순서대로의 행, 파일 메타데이터를 반환합니다.
+type readResult struct {
+	columns   []Column
+	rows      [][]any
+	numRows   int64
+	rowGroups int
+	codecs    []int64
+	keyValue  map[string]string
+	createdBy string
+}
+
+func readFile(t *testing.T, b []byte) readResult {
+	t.Helper()
+	if len(b) < 12 || string(b[:4]) != "PAR1" || string(b[len(b)-4:]) != "PAR1" {
+		t.Fatalf("parquet magic 없음")
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	footer := &compactReader{b: b[len(b)-8-n : len(b)-8]}
+	meta := footer.structure()
+	if footer.pos != n {
+		t.Fatalf("footer 길이 %d, 읽은 길이 %d", n, footer.pos)
+	}
+	if v := field[int64](meta, 1); v != 1 {
+		t.Errorf("version = %d", v)
+	}
+
+	var res readResult
+	schema := field[[]any](meta, 2)
+	root := schema[0].(map[int]any)
+	if int(field[int64](root, 5)) != len(schema)-1 {
+		t.Fatalf("루트 num_children = %d, 열 %d개", field[int64](root, 5), len(schema)-1)
+	}
+	logical := map[int64]Logical{0: String, 19: JSON, 9: TimestampMillis}
+	for _, e := range schema[1:] {
+		el := e.(map[int]any)
+		col := Column{
+			Name:     string(field[[]byte](el, 4)),
+			Type:     Type(field[int64](el, 1)),
+			Required: field[int64](el, 3) == 0,
+		}
+		if ct, ok := el[6]; ok {
+			col.Logical = logical[ct.(int64)]
+		}
+		res.columns = append(res.columns, col)
+	}
+	res.numRows = field[int64](meta, 3)
+
+	for _, g := range field[[]any](meta, 4) {
+		rg := g.(map[int]any)
+		numRows := int(field[int64](rg, 3))
+		start := len(res.rows)
+		for i := 0; i < numRows; i++ {
+			res.rows = append(res.rows, make([]any, len(res.columns)))
+		}
+		chunks := field[[]any](rg, 1)
+		if len(chunks) != len(res.columns) {
+			t.Fatalf("행 그룹의 열 %d개, 스키마 %d개", len(chunks), len(res.columns))
+		}
+		var compressed int64
+		for i, c := range chunks {
+			cm := field[map[int]any](c.(map[int]any), 3)
+			if path := field[[]any](cm, 3); len(path) != 1 || string(path[0].([]byte)) != res.columns[i].Name {
+				t.Errorf("path_in_schema = %q", path)
+			}
+			codec := field[int64](cm, 4)
+			res.codecs = append(res.codecs, codec)
+			values := readChunk(t, b, res.columns[i], codec, field[int64](cm, 9), field[int64](cm, 5))
+			if len(values) != numRows {
+				t.Fatalf("열 %s: 값 %d개, 행 %d개", res.columns[i].Name, len(values), numRows)
+			}
+			for j, v := range values {
+				res.rows[start+j][i] = v
+			}
+			compressed += field[int64](cm, 7)
+		}
+		if got := field[int64](rg, 6); got != compressed {
+			t.Errorf("total_compressed_size = %d, 열 합 %d", got, compressed)
+		}
+		res.rowGroups++
+	}
+
+	if kvs, ok := meta[5]; ok {
+		res.keyValue = map[string]string{}
+		for _, kv := range kvs.([]any) {
+			m := kv.(map[int]any)
+			res.keyValue[string(field[[]byte](m, 1))] = string(field[[]byte](m, 2))
+		}
+	}
+	res.createdBy = string(field[[]byte](meta, 6))
+	return res
+}
+
+// readChunk는 offset의 열 청크에서 numValues개의 값을 읽습니다 (null은 nil).
+func readChunk(t *testing.T, b []byte, col Column, codec, offset, numValues int64) []any {
+	t.Helper()
+	var values []any
+	pos := int(offset)
+	for int64(len(values)) < numValues {
+		r := &compactReader{b: b, pos: pos}
+		h := r.structure()
+		if typ := field[int64](h, 1); typ != 0 {
+			t.Fatalf("DATA_PAGE가 아닌 페이지 %d", typ)
+		}
+		size := int(field[int64](h, 3))
+		body := decompress(t, codec, b[r.pos:r.pos+size])
+		if int64(len(body)) != field[int64](h, 2) {
+			t.Fatalf("압축 해제 크기 %d, 헤더 %d", len(body), field[int64](h, 2))
+		}
+		pos = r.pos + size
+
+		dp := field[map[int]any](h, 5)
+		n := int(field[int64](dp, 1))
+		defs := make([]byte, n)
+		for i := range defs {
+			defs[i] = 1
+		}
+		if !col.Required {
+			l := int(binary.LittleEndian.Uint32(body))
+			defs = decodeHybrid(t, body[4:4+l], n)
+			body = body[4+l:]
+		}
+		for _, d := range defs {
+			if d == 0 {
+				values = append(values, nil)
+				continue
+			}
+			var v any
+			v, body = plainValue(t, col, body)
+			values = append(values, v)
+		}
+		if len(body) != 0 {
+			t.Fatalf("열 %s: 페이지에 %d바이트가 남음", col.Name, len(body))
+		}
+	}
+	return values
+}
+
+func decompress(t *testing.T, codec int64, p []byte) []byte {
+	t.Helper()
+	var out []byte
+	var err error
+	switch Codec(codec) {
+	case Uncompressed:
+		return p
+	case Snappy:
+		out, err = snappy.Decode(nil, p)
+	case Gzip:
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(bytes.NewReader(p)); err == nil {
+			out, err = io.ReadAll(zr)
+		}
+	case Zstd:
+		var zr *zstd.Decoder
+		if zr, err = zstd.NewReader(nil); err == nil {
+			out, err = zr.DecodeAll(p, nil)
+			zr.Close()
+		}
+	default:
+		t.Fatalf("알 수 없는 codec %d", codec)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// decodeHybrid는 비트 폭 1의 RLE/비트 패킹 혼합 인코딩에서 n개의 레벨을 읽습니다.
+func decodeHybrid(t *testing.T, p []byte, n int) []byte {
+	t.Helper()
+	var out []byte
+	for len(out) < n {
+		h, k := binary.Uvarint(p)
+		if k <= 0 {
+			t.Fatal("잘못된 런 헤더")
+		}
+		p = p[k:]
+		if h&1 == 0 { // RLE 런: 개수, 값 1바이트
+			for i := uint64(0); i < h>>1; i++ {
+				out = append(out, p[0])
+			}
+			p = p[1:]
+			continue
+		}
+		groups := int(h >> 1) // 비트 패킹 런: 8개씩 묶음, 묶음마다 1바이트
+		for g := 0; g < groups; g++ {
+			for bit := 0; bit < 8; bit++ {
+				out = append(out, p[g]>>bit&1)
+			}
+		}
+		p = p[groups:]
+	}
+	return out[:n]
+}
+
+func plainValue(t *testing.T, col Column, p []byte) (any, []byte) {
+	t.Helper()
+	switch col.Type {
+	case ByteArray:
+		n := binary.LittleEndian.Uint32(p)
+		return string(p[4 : 4+n]), p[4+n:]
+	case Int32:
+		return int32(binary.LittleEndian.Uint32(p)), p[4:]
+	case Int64:
+		return int64(binary.LittleEndian.Uint64(p)), p[8:]
+	case Double:
+		return math.Float64frombits(binary.LittleEndian.Uint64(p)), p[8:]
+	}
+	t.Fatalf("알 수 없는 타입 %d", col.Type)
+	return nil, nil
+}
+
+var testColumns = []Column{
+	{Name: "url", Type: ByteArray, Logical: String, Required: true},
+	{Name: "date", Type: Int64, Logical: TimestampMillis},
+	{Name: "status", Type: Int32},
+	{Name: "score", Type: Double},
+	{Name: "meta", Type: ByteArray, Logical: JSON},
+	{Name: "count", Type: Int64, Required: true},
+}
+
+// testRow는 i번째 테스트 행과, 그 행을 다시 읽었을 때 기대하는 값입니다.
+func testRow(i int) (row, want []any) {
+	date := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute)
+	row = []any{fmt.Sprintf("https://example.com/%d", i), date, i % 600, float64(i) / 3, nil, int64(i) * 1000}
+	want = []any{row[0], date.UnixMilli(), int32(i % 600), float64(i) / 3, nil, int64(i) * 1000}
+	if i%3 == 0 {
+		row[1], want[1] = nil, nil
+	}
+	if i%5 == 0 {
+		row[2], want[2] = nil, nil
+	}
+	if i%7 != 0 {
+		row[4] = []byte(fmt.Sprintf(`{"n":%d}`, i))
+		want[4] = fmt.Sprintf(`{"n":%d}`, i)
+	}
+	return row, want
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	codecs := map[string]Codec{"none": Uncompressed, "snappy": Snappy, "gzip": Gzip, "zstd": Zstd}
+	sizes := []struct {
+		name         string
+		rows         int
+		rowGroupSize int64
+		pageSize     int64
+		rowGroups    int // 0이면 1개 이상
+	}{
+		{"empty", 0, 0, 0, 0},
+		{"single", 1, 0, 0, 1},
+		{"one-group", 300, 0, 0, 1},
+		{"many-groups-pages", 2000, 8 << 10, 512, 0},
+	}
+
+	for codecName, codec := range codecs {
+		for _, size := range sizes {
+			t.Run(codecName+"/"+size.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				w, err := NewWriter(&buf, testColumns, Options{
+					Codec:        codec,
+					RowGroupSize: size.rowGroupSize,
+					PageSize:     size.pageSize,
+					CreatedBy:    "crowl test",
+					KeyValue:     map[string]string{"crowl.version": "test", "crowl.source": "a.warc.gz"},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				var want [][]any
+				for i := 0; i < size.rows; i++ {
+					row, w2 := testRow(i)
+					if err := w.Write(row); err != nil {
+						t.Fatal(err)
+					}
+					want = append(want, w2)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				got := readFile(t, buf.Bytes())
+				if !reflect.DeepEqual(got.columns, testColumns) {
+					t.Errorf("스키마\n got: %+v\nwant: %+v", got.columns, testColumns)
+				}
+				if got.numRows != int64(size.rows) || len(got.rows) != size.rows {
+					t.Fatalf("행 수: num_rows %d, 읽은 행 %d, 기대값 %d", got.numRows, len(got.rows), size.rows)
+				}
+				for i := range want {
+					if !reflect.DeepEqual(got.rows[i], want[i]) {
+						t.Fatalf("행 %d\n got: %v\nwant: %v", i, got.rows[i], want[i])
+					}
+				}
+				switch {
+				case size.rows == 0 && got.rowGroups != 0:
+					t.Errorf("빈 파일의 행 그룹 %d개", got.rowGroups)
+				case size.rowGroups > 0 && got.rowGroups != size.rowGroups:
+					t.Errorf("행 그룹 %d개, 기대값 %d", got.rowGroups, size.rowGroups)
+				case size.rowGroups == 0 && size.rows > 0 && got.rowGroups < 2:
+					t.Errorf("행 그룹 %d개, 여러 개를 기대", got.rowGroups)
+				}
+				for _, c := range got.codecs {
+					if Codec(c) != codec {
+						t.Errorf("codec = %d, 기대값 %d", c, codec)
+					}
+				}
+				if got.createdBy != "crowl test" || got.keyValue["crowl.version"] != "test" || got.keyValue["crowl.source"] != "a.warc.gz" {
+					t.Errorf("메타데이터: created_by %q, key_value %v", got.createdBy, got.keyValue)
+				}
+			})
+		}
+	}
+}
+
+func TestWriterFlushKeepsRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		row, _ := testRow(i)
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+		if i%4 == 3 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := readFile(t, buf.Bytes())
+	if len(got.rows) != 10 || got.rowGroups != 3 {
+		t.Errorf("행 %d개, 행 그룹 %d개", len(got.rows), got.rowGroups)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	if _, err := NewWriter(io.Discard, nil, Options{}); err == nil {
+		t.Error("열 없는 스키마에서 오류가 없습니다")
+	}
+	if _, err := NewWriter(io.Discard, testColumns, Options{Codec: 3}); err == nil {
+		t.Error("지원하지 않는 압축 방식에서 오류가 없습니다")
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, want := testRow(1)
+	if err := w.Write(good); err != nil {
+		t.Fatal(err)
+	}
+	bad := [][]any{
+		{nil, nil, nil, nil, nil, int64(1)},                  // 필수 열에 null
+		{"u", "not a time", nil, nil, nil, int64(1)},         // Int64에 string
+		{"u", nil, nil, float32(1), nil, int64(1)},           // Double에 float32
+		{"u", nil, nil, nil, nil},                            // 값 개수
+		{"u", nil, int64(1), nil, nil, int64(1)},             // Int32에 int64
+		{123, nil, nil, nil, nil, int64(1)},                  // ByteArray에 int
+		{"u", nil, nil, nil, map[string]int{}, int64(1)},     // ByteArray에 map
+		{"u", time.Now(), nil, math.NaN(), nil, "not int64"}, // Int64에 string
+	}
+	for _, row := range bad {
+		if err := w.Write(row); err == nil {
+			t.Errorf("Write(%v): 오류가 없습니다", row)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(good); err == nil {
+		t.Error("닫힌 Writer에 기록했는데 오류가 없습니다")
+	}
+
+	// 거부한 행은 어느 열에도 남지 않아야 함
+	got := readFile(t, buf.Bytes())
+	if len(got.rows) != 1 || !reflect.DeepEqual(got.rows[0], want) {
+		t.Errorf("행 = %v, 기대값 [%v]", got.rows, want)
+	}
+}
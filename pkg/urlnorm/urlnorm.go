@@ -0,0 +1,162 @@
+// Package urlnorm은 같은 문서를 가리키는 여러 형태의 URL을 하나의 정규 URL로 맞춥니다.
+//
+// 호스트를 소문자로 바꾸고 기본 포트, 모바일(m.)·AMP 호스트, AMP 경로, 추적 파라미터(utm_*, fbclid 등),
+// 프래그먼트, 끝의 슬래시를 지웁니다. 남은 쿼리 파라미터는 정렬합니다.
+// 문서의 <link rel=canonical>을 쓰려면 Canonical로 페이지 URL 기준으로 해석한 뒤 정규화합니다.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams는 지우는 추적 파라미터 이름입니다 (소문자). utm_로 시작하는 파라미터도 지웁니다.
+var trackingParams = map[string]bool{
+	"fbclid":  true, // Facebook
+	"gclid":   true, // Google Ads
+	"dclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"msclkid": true, // Microsoft Ads
+	"yclid":   true, // Yandex
+	"igshid":  true, // Instagram
+	"mc_cid":  true, // Mailchimp
+	"mc_eid":  true,
+	"_hsenc":  true, // HubSpot
+	"_hsmi":   true,
+}
+
+// mobilePrefixes는 데스크톱 호스트와 같은 문서를 내보내는 모바일·AMP 호스트의 접두어입니다.
+var mobilePrefixes = []string{"m.", "mobile.", "amp."}
+
+// Normalize는 rawURL을 정규 URL로 바꿉니다. http(s)가 아니거나 호스트가 없으면 오류를 반환합니다.
+// 예: HTTPS://M.Example.com:443/news/1/amp/?utm_source=x&b=2&a=1#top → https://example.com/news/1?a=1&b=2
+func Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("urlnorm: %w", err)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("urlnorm: http(s) URL이 아닙니다: %s", rawURL)
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return "", errors.New("urlnorm: 호스트가 없습니다: " + rawURL)
+	}
+	if port := u.Port(); port != "" && !IsDefaultPort(scheme, port) {
+		host += ":" + port
+	}
+
+	var b strings.Builder
+	b.WriteString(scheme + "://" + host + normalizePath(u.EscapedPath()))
+	if query := normalizeQuery(u.RawQuery); query != "" {
+		b.WriteString("?" + query)
+	}
+	return b.String(), nil
+}
+
+// Canonical은 pageURL 문서의 <link rel=canonical> 주소 href를 해석하여 정규 URL로 바꿉니다.
+// href가 비었거나 http(s)가 아니면, 또는 루트가 아닌 페이지가 사이트 루트를 가리키면(잘못 설정된 템플릿)
+// 빈 문자열을 반환합니다.
+func Canonical(pageURL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil {
+		return ""
+	}
+	ref, err := base.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if strings.Trim(ref.Path, "/") == "" && strings.Trim(base.Path, "/") != "" {
+		return ""
+	}
+	norm, err := Normalize(ref.String())
+	if err != nil {
+		return ""
+	}
+	return norm
+}
+
+// normalizeHost는 호스트를 소문자로 바꾸고 끝의 점과 모바일·AMP 접두어를 지웁니다.
+// 접두어는 지운 뒤에도 도메인이 남는 경우(레이블 2개 이상)에만 지웁니다.
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, prefix := range mobilePrefixes {
+		if rest, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(rest, ".") {
+			return rest
+		}
+	}
+	return host
+}
+
+// normalizePath는 빈 세그먼트와 점 세그먼트, AMP 세그먼트를 정리하고 끝의 슬래시를 지웁니다 (루트는 /).
+func normalizePath(p string) string {
+	var segs []string
+	for _, seg := range strings.Split(p, "/") {
+		switch strings.ToLower(seg) {
+		case "", ".", "amp":
+			continue
+		case "..":
+			if len(segs) > 0 {
+				segs = segs[:len(segs)-1]
+			}
+			continue
+		}
+		segs = append(segs, seg)
+	}
+	if len(segs) == 0 {
+		return "/"
+	}
+
+	last := segs[len(segs)-1]
+	lower := strings.ToLower(last)
+	switch {
+	case strings.HasSuffix(lower, ".amp.html"):
+		last = last[:len(last)-len(".amp.html")] + ".html"
+	case strings.HasSuffix(lower, ".amp") && len(last) > len(".amp"):
+		last = last[:len(last)-len(".amp")]
+	}
+	segs[len(segs)-1] = last
+	return "/" + strings.Join(segs, "/")
+}
+
+// normalizeQuery는 추적·AMP 파라미터와 빈 파라미터를 지우고 나머지를 정렬합니다.
+// 값의 인코딩은 바꾸지 않습니다.
+func normalizeQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	var params []string
+	for _, param := range strings.Split(raw, "&") {
+		if param == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(param, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		key = strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(key, "utm_"), trackingParams[key]:
+			continue
+		case key == "amp", key == "_amp", key == "outputtype" && strings.EqualFold(value, "amp"):
+			continue
+		}
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// IsDefaultPort는 port가 scheme(소문자 http, https)의 기본 포트인지 확인합니다.
+func IsDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
@@ -0,0 +1,94 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"HTTPS://M.Example.com:443/news/1/amp/?utm_source=x&b=2&a=1#top", "https://example.com/news/1?a=1&b=2"},
+		{"http://example.com", "http://example.com/"},
+		{"http://example.com:80/a/", "http://example.com/a"},
+		{"https://example.com:8443/a", "https://example.com:8443/a"},
+		{"http://example.com:443/a", "http://example.com:443/a"},
+		{"https://Example.COM./A/B", "https://example.com/A/B"},
+		{"https://mobile.example.com/a", "https://example.com/a"},
+		{"https://amp.example.com/a", "https://example.com/a"},
+		{"https://m.com/a", "https://m.com/a"},
+		{"https://www.example.com/a", "https://www.example.com/a"},
+		{"https://example.com//a/./b/../c", "https://example.com/a/c"},
+		{"https://example.com/../a", "https://example.com/a"},
+		{"https://example.com/news/1.amp.html", "https://example.com/news/1.html"},
+		{"https://example.com/news/1.amp", "https://example.com/news/1"},
+		{"https://example.com/news/.amp", "https://example.com/news/.amp"},
+		{"https://example.com/AMP/news", "https://example.com/news"},
+		{"https://example.com/a?fbclid=1&gclid=2&UTM_Medium=3&id=7", "https://example.com/a?id=7"},
+		{"https://example.com/a?amp=1&_amp=true&outputType=AMP&x=1", "https://example.com/a?x=1"},
+		{"https://example.com/a?outputType=json", "https://example.com/a?outputType=json"},
+		{"https://example.com/a?&&b=2&&a=1", "https://example.com/a?a=1&b=2"},
+		{"https://example.com/a?utm_source=x", "https://example.com/a"},
+		{"https://example.com/a?q=%EA%B0%80+b", "https://example.com/a?q=%EA%B0%80+b"},
+		{"https://example.com/기사", "https://example.com/%EA%B8%B0%EC%82%AC"},
+		{"  https://example.com/a  ", "https://example.com/a"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "ftp://example.com/a", "mailto:a@example.com", "/relative", "https:///a", "http://%zz"} {
+		if got, err := Normalize(bad); err == nil {
+			t.Errorf("Normalize(%q) = %q: 오류가 없습니다", bad, got)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	page := "https://m.example.com/news/1?utm_source=x"
+	tests := []struct {
+		name string
+		href string
+		want string
+	}{
+		{"절대 주소", "https://www.example.com/news/1?ref=a", "https://www.example.com/news/1?ref=a"},
+		{"상대 주소", "/news/1/amp", "https://example.com/news/1"},
+		{"경로 상대 주소", "2", "https://example.com/news/2"},
+		{"스킴 상대 주소", "//example.org/x", "https://example.org/x"},
+		{"빈 href", "  ", ""},
+		{"사이트 루트", "/", ""},
+		{"http(s)가 아님", "javascript:void(0)", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Canonical(page, tt.href); got != tt.want {
+				t.Errorf("Canonical(%q) = %q, want %q", tt.href, got, tt.want)
+			}
+		})
+	}
+	if got := Canonical("https://example.com/", "https://example.com/"); got != "https://example.com/" {
+		t.Errorf("루트 페이지의 루트 canonical = %q", got)
+	}
+}
+
+func TestIsDefaultPort(t *testing.T) {
+	tests := []struct {
+		scheme, port string
+		want         bool
+	}{
+		{"http", "80", true},
+		{"https", "443", true},
+		{"http", "443", false},
+		{"https", "80", false},
+		{"https", "8443", false},
+		{"ftp", "21", false},
+		{"http", "", false},
+	}
+	for _, tt := range tests {
+		if got := IsDefaultPort(tt.scheme, tt.port); got != tt.want {
+			t.Errorf("IsDefaultPort(%q, %q) = %v, want %v", tt.scheme, tt.port, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package warc
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// ErrDigest는 레코드 블록 또는 페이로드의 다이제스트가 헤더 값과 다름을 나타냅니다.
+var ErrDigest = errors.New("warc: 다이제스트 불일치")
+
+// Verify는 block의 WARC-Block-Digest와, HTTP 응답 레코드라면 WARC-Payload-Digest를 검증합니다.
+// 헤더가 없거나 지원하지 않는 알고리즘(sha1, sha256 외)이면 검증을 건너뜁니다.
+// 잘린(WARC-Truncated) 레코드의 페이로드 다이제스트는 원본 기준이므로 검증하지 않습니다.
+func Verify(h Header, block []byte) error {
+	if err := checkDigest("WARC-Block-Digest", h.Get("WARC-Block-Digest"), block); err != nil {
+		return err
+	}
+
+	want := h.Get("WARC-Payload-Digest")
+	if want == "" || !isHTTPResponse(h) || h.Get("WARC-Truncated") != "" {
+		return nil
+	}
+	payload, ok := HTTPPayload(block)
+	if !ok {
+		return nil
+	}
+	return checkDigest("WARC-Payload-Digest", want, payload)
+}
+
+// checkDigest는 "알고리즘:값" 형식의 want와 data의 해시를 비교합니다.
+// 값은 base32(Common Crawl 기본)와 hex 인코딩을 모두 허용합니다.
+func checkDigest(name, want string, data []byte) error {
+	algo, value, ok := strings.Cut(want, ":")
+	if !ok {
+		return nil
+	}
+
+	var h hash.Hash
+	switch strings.ToLower(algo) {
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	default:
+		return nil
+	}
+	h.Write(data)
+	sum := h.Sum(nil)
+
+	got := base32.StdEncoding.EncodeToString(sum)
+	if len(value) == hex.EncodedLen(len(sum)) {
+		got = hex.EncodeToString(sum)
+	}
+	if !strings.EqualFold(strings.TrimRight(value, "="), strings.TrimRight(got, "=")) {
+		return fmt.Errorf("%w: %s %s (계산값 %s:%s)", ErrDigest, name, want, algo, got)
+	}
+	return nil
+}
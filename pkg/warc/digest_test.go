@@ -0,0 +1,68 @@
+package warc
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	block := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<p>본문</p>")
+	payload := []byte("<p>본문</p>")
+	sha1B32 := func(p []byte) string { s := sha1.Sum(p); return "sha1:" + base32.StdEncoding.EncodeToString(s[:]) }
+	sha1Hex := func(p []byte) string { s := sha1.Sum(p); return "sha1:" + hex.EncodeToString(s[:]) }
+	sha256B32 := func(p []byte) string {
+		s := sha256.Sum256(p)
+		return "sha256:" + base32.StdEncoding.EncodeToString(s[:])
+	}
+	sha256Hex := func(p []byte) string { s := sha256.Sum256(p); return "sha256:" + hex.EncodeToString(s[:]) }
+	wrong := sha1B32([]byte("다른 내용"))
+
+	response := func(fields ...string) Header {
+		h := Header{{Name: "WARC-Type", Value: TypeResponse}, {Name: "Content-Type", Value: "application/http; msgtype=response"}}
+		for i := 0; i+1 < len(fields); i += 2 {
+			h.Add(fields[i], fields[i+1])
+		}
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		header Header
+		block  []byte
+		err    bool
+	}{
+		{"sha1 base32", response("WARC-Block-Digest", sha1B32(block), "WARC-Payload-Digest", sha1B32(payload)), block, false},
+		{"sha1 hex", response("WARC-Block-Digest", sha1Hex(block)), block, false},
+		{"sha256 base32", response("WARC-Block-Digest", sha256B32(block), "WARC-Payload-Digest", sha256B32(payload)), block, false},
+		{"sha256 hex", response("WARC-Payload-Digest", sha256Hex(payload)), block, false},
+		{"패딩 없는 base32", response("WARC-Block-Digest", strings.TrimRight(sha256B32(block), "=")), block, false},
+		{"대문자 알고리즘 이름", response("WARC-Block-Digest", strings.ToUpper(sha1Hex(block)[:5])+sha1Hex(block)[5:]), block, false},
+		{"Digest 함수", response("WARC-Block-Digest", Digest(block), "WARC-Payload-Digest", Digest(payload)), block, false},
+		{"다이제스트 없음", response(), block, false},
+		{"지원하지 않는 알고리즘", response("WARC-Block-Digest", "md5:abcd"), block, false},
+		{"형식 오류", response("WARC-Block-Digest", "abcd"), block, false},
+
+		{"블록 불일치", response("WARC-Block-Digest", wrong), block, true},
+		{"페이로드 불일치", response("WARC-Block-Digest", sha1B32(block), "WARC-Payload-Digest", wrong), block, true},
+		{"블록 변조", response("WARC-Block-Digest", sha1B32(block)), []byte(string(block) + "!"), true},
+		{"hex 불일치", response("WARC-Block-Digest", sha1Hex(payload)), block, true},
+
+		// 페이로드 다이제스트는 HTTP 응답이고 잘리지 않은 레코드에서만 검증
+		{"잘린 레코드", response("WARC-Payload-Digest", wrong, "WARC-Truncated", "length"), block, false},
+		{"HTTP 응답이 아님", Header{{Name: "WARC-Type", Value: TypeResource}, {Name: "WARC-Payload-Digest", Value: wrong}}, block, false},
+		{"HTTP 헤더 끝 없음", response("WARC-Payload-Digest", wrong), []byte("HTTP/1.1 200 OK\r\n"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.block)
+			if tt.err != (err != nil) || err != nil && !errors.Is(err, ErrDigest) {
+				t.Errorf("Verify = %v, 오류 기대 %v", err, tt.err)
+			}
+		})
+	}
+}
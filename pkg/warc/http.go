@@ -0,0 +1,237 @@
+package warc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+var (
+	ErrHTTPHeader   = errors.New("warc: HTTP 헤더 구분자 없음")
+	ErrHTTPStatus   = errors.New("warc: 잘못된 HTTP 상태 줄")
+	ErrHTTPEncoding = errors.New("warc: HTTP 본문 인코딩 해제 실패")
+)
+
+// maxDecodedSize는 Content-Encoding을 해제한 본문의 최대 크기입니다 (압축 폭탄 방지).
+const maxDecodedSize = 64 << 20
+
+var errDecodedSize = fmt.Errorf("해제한 본문이 %d바이트를 넘음", maxDecodedSize)
+
+// Response는 response 레코드 블록의 HTTP 응답입니다.
+type Response struct {
+	Proto      string // 예: HTTP/1.1
+	StatusCode int
+	Reason     string
+	Header     Header
+	Body       []byte // Transfer-Encoding(chunked)과 Content-Encoding(gzip, deflate, br)을 되돌린 본문
+
+	// EncodingErr는 Content-Encoding 해제에 실패하여 Body에 해제하지 못한 본문을 그대로 둔 경우의 오류입니다
+	// (ErrHTTPEncoding). 헤더와 달리 이미 해제된 본문을 저장한 크롤러가 있어 레코드를 버리지 않습니다.
+	EncodingErr error
+}
+
+// ParseResponse는 HTTP 응답 블록에서 상태 줄, 헤더, 본문을 분리합니다.
+// 크롤러가 남겨 둔 chunked 전송 인코딩과 gzip/deflate/br 콘텐츠 인코딩은 되돌립니다.
+// 잘린 레코드처럼 인코딩 해제가 도중에 실패하면 해제된 부분까지를 본문으로 씁니다.
+// 해제된 부분이 없으면 해제하지 않은 본문을 쓰고 EncodingErr에 기록하며, 해제한 본문이 너무 크면 오류를 반환합니다.
+func ParseResponse(block []byte) (*Response, error) {
+	head, body, ok := splitHTTP(block)
+	if !ok {
+		return nil, ErrHTTPHeader
+	}
+
+	lines := strings.Split(string(head), "\n")
+	resp := &Response{}
+	if err := resp.parseStatus(strings.TrimRight(lines[0], "\r")); err != nil {
+		return nil, err
+	}
+	resp.Header = parseHTTPHeader(lines[1:])
+
+	if hasToken(strings.Join(resp.Header.Values("Transfer-Encoding"), ","), "chunked") {
+		if decoded, err := dechunk(body); err == nil || len(decoded) > 0 {
+			body = decoded
+		}
+	}
+
+	// 여러 인코딩(쉼표로 나열하거나 필드를 반복)은 적용된 역순으로 해제
+	encodings := strings.Split(strings.Join(resp.Header.Values("Content-Encoding"), ","), ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoded, err := decode(strings.ToLower(strings.TrimSpace(encodings[i])), body)
+		if errors.Is(err, errDecodedSize) {
+			return nil, fmt.Errorf("%w (%s): %w", ErrHTTPEncoding, encodings[i], err)
+		}
+		if err != nil && len(decoded) == 0 {
+			resp.EncodingErr = fmt.Errorf("%w (%s): %w", ErrHTTPEncoding, encodings[i], err)
+			break
+		}
+		body = decoded
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// splitHTTP는 블록을 헤더와 본문으로 나눕니다. CRLF CRLF와 LF LF 중 먼저 나오는 구분자를 씁니다.
+func splitHTTP(block []byte) (head, body []byte, ok bool) {
+	end, sep := bytes.Index(block, []byte("\r\n\r\n")), 4
+	if i := bytes.Index(block, []byte("\n\n")); i != -1 && (end == -1 || i < end) {
+		end, sep = i, 2
+	}
+	if end == -1 {
+		return nil, nil, false
+	}
+	return block[:end], block[end+sep:], true
+}
+
+// parseStatus는 "HTTP/1.1 200 OK" 형식의 상태 줄을 해석합니다. 사유 구문은 없어도 됩니다.
+func (r *Response) parseStatus(line string) error {
+	proto, rest, _ := strings.Cut(line, " ")
+	code, reason, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	status, err := strconv.Atoi(code)
+	if !strings.HasPrefix(proto, "HTTP/") || err != nil || status < 100 || status > 999 {
+		return fmt.Errorf("%w: %q", ErrHTTPStatus, line)
+	}
+	r.Proto, r.StatusCode, r.Reason = proto, status, strings.TrimSpace(reason)
+	return nil
+}
+
+// parseHTTPHeader는 헤더 줄을 해석합니다. 공백으로 시작하는 줄은 앞 필드 값의 연장으로 보고,
+// 콜론이 없는 잘못된 줄은 건너뜁니다.
+func parseHTTPHeader(lines []string) Header {
+	var h Header
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(h) > 0 {
+			h[len(h)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		h.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return h
+}
+
+// dechunk는 chunked 전송 인코딩을 되돌립니다. 형식이 어긋나거나 중간에 끝나면
+// 그때까지 읽은 데이터와 오류를 반환합니다.
+func dechunk(body []byte) ([]byte, error) {
+	var out []byte
+	for {
+		i := bytes.IndexByte(body, '\n')
+		if i == -1 {
+			return out, io.ErrUnexpectedEOF
+		}
+		line := strings.TrimSpace(string(body[:i]))
+		line, _, _ = strings.Cut(line, ";") // chunk 확장 무시
+		size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+		if err != nil || size < 0 {
+			return out, fmt.Errorf("잘못된 chunk 크기: %q", line)
+		}
+		body = body[i+1:]
+		if size == 0 {
+			return out, nil
+		}
+		if int64(len(body)) < size {
+			return append(out, body...), io.ErrUnexpectedEOF
+		}
+		out = append(out, body[:size]...)
+		body = bytes.TrimPrefix(bytes.TrimPrefix(body[size:], []byte("\r")), []byte("\n"))
+	}
+}
+
+// decode는 콘텐츠 인코딩 하나를 해제합니다. 알 수 없는 인코딩이면 그대로 반환합니다.
+// 도중에 실패하면 그때까지 해제한 데이터와 오류를 반환합니다.
+func decode(encoding string, body []byte) ([]byte, error) {
+	var r io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	case "deflate":
+		// 표준은 zlib 래퍼를 쓰지만 래퍼 없는 raw deflate를 보내는 서버도 있음
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r = flate.NewReader(bytes.NewReader(body))
+		} else {
+			defer zr.Close()
+			r = zr
+		}
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	default:
+		return body, nil
+	}
+
+	out, err := io.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+	if err == nil && len(out) > maxDecodedSize {
+		return nil, errDecodedSize
+	}
+	return out, err
+}
+
+// hasToken은 쉼표로 구분된 헤더 값에 token이 있는지 대소문자 구분 없이 확인합니다.
+func hasToken(value, token string) bool {
+	for _, v := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// MediaType은 Content-Type의 미디어 타입(소문자, 매개변수 제외)입니다. 없으면 빈 문자열입니다.
+func (r *Response) MediaType() string {
+	mt, _, _ := r.contentType()
+	return mt
+}
+
+// Charset은 Content-Type의 charset 매개변수(소문자)입니다. 없으면 빈 문자열입니다.
+func (r *Response) Charset() string {
+	_, params, _ := r.contentType()
+	return strings.ToLower(params["charset"])
+}
+
+func (r *Response) contentType() (string, map[string]string, error) {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return "", nil, nil
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		// 매개변수가 잘못된 경우에도 미디어 타입은 사용
+		mt, _, _ = strings.Cut(ct, ";")
+		return strings.ToLower(strings.TrimSpace(mt)), nil, err
+	}
+	return mt, params, nil
+}
+
+// IsRedirect는 3xx 응답인지 확인합니다.
+func (r *Response) IsRedirect() bool {
+	return r.StatusCode >= 300 && r.StatusCode < 400
+}
+
+// IsHTML은 Content-Type이 HTML(text/html, application/xhtml+xml)인지 확인합니다.
+func (r *Response) IsHTML() bool {
+	switch r.MediaType() {
+	case "text/html", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
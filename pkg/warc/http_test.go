@@ -0,0 +1,272 @@
+package warc
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compress는 encoding으로 p를 압축합니다. deflate는 zlib 래퍼를, raw-deflate는 래퍼 없는 deflate를 씁니다.
+func compress(t *testing.T, encoding string, p []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	default:
+		t.Fatalf("알 수 없는 인코딩 %s", encoding)
+	}
+	if _, err := w.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func response(head string, body []byte) []byte {
+	return append([]byte(head), body...)
+}
+
+func TestParseResponseStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		proto  string
+		code   int
+		reason string
+		err    error
+	}{
+		{"기본", "HTTP/1.1 200 OK", "HTTP/1.1", 200, "OK", nil},
+		{"여러 단어 사유", "HTTP/1.0 404 Not Found", "HTTP/1.0", 404, "Not Found", nil},
+		{"사유 없음", "HTTP/2 204", "HTTP/2", 204, "", nil},
+		{"겹친 공백", "HTTP/1.1  301   Moved Permanently ", "HTTP/1.1", 301, "Moved Permanently", nil},
+		{"비표준 코드", "HTTP/1.1 999 Whatever", "HTTP/1.1", 999, "Whatever", nil},
+		{"HTTP가 아님", "ICY 200 OK", "", 0, "", ErrHTTPStatus},
+		{"숫자가 아닌 코드", "HTTP/1.1 OK 200", "", 0, "", ErrHTTPStatus},
+		{"범위 밖 코드", "HTTP/1.1 99 Low", "", 0, "", ErrHTTPStatus},
+		{"코드 없음", "HTTP/1.1", "", 0, "", ErrHTTPStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ParseResponse([]byte(tt.line + "\r\nContent-Type: text/html\r\n\r\nbody"))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("오류 = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Proto != tt.proto || resp.StatusCode != tt.code || resp.Reason != tt.reason {
+				t.Errorf("= %q %d %q", resp.Proto, resp.StatusCode, resp.Reason)
+			}
+		})
+	}
+}
+
+func TestParseResponseHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string
+		want  Header
+		body  string
+	}{
+		{"CRLF", "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nX-A:  b \r\n\r\nbody",
+			Header{{"Content-Type", "text/html"}, {"X-A", "b"}}, "body"},
+		{"LF만", "HTTP/1.1 200 OK\nContent-Type: text/html\n\nbody\r\n\r\nmore",
+			Header{{"Content-Type", "text/html"}}, "body\r\n\r\nmore"},
+		{"LF가 먼저", "HTTP/1.1 200 OK\nA: 1\n\nbody\r\n\r\n",
+			Header{{"A", "1"}}, "body\r\n\r\n"},
+		{"obs-fold", "HTTP/1.1 200 OK\r\nX-Long: first\r\n second\r\n\tthird\r\nX-Next: n\r\n\r\n",
+			Header{{"X-Long", "first second third"}, {"X-Next", "n"}}, ""},
+		{"잘못된 줄", "HTTP/1.1 200 OK\r\nno colon here\r\n: empty name\r\nA: 1\r\n\r\nb",
+			Header{{"A", "1"}}, "b"},
+		{"값의 콜론", "HTTP/1.1 200 OK\r\nLocation: https://example.com:8443/a\r\n\r\n",
+			Header{{"Location", "https://example.com:8443/a"}}, ""},
+		{"헤더 없음", "HTTP/1.1 200 OK\n\nbody", nil, "body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ParseResponse([]byte(tt.block))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Header) != len(tt.want) {
+				t.Fatalf("Header = %q, want %q", resp.Header, tt.want)
+			}
+			for i := range tt.want {
+				if resp.Header[i] != tt.want[i] {
+					t.Errorf("Header[%d] = %q, want %q", i, resp.Header[i], tt.want[i])
+				}
+			}
+			if string(resp.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", resp.Body, tt.body)
+			}
+		})
+	}
+
+	if _, err := ParseResponse([]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n")); !errors.Is(err, ErrHTTPHeader) {
+		t.Errorf("구분자 없음: 오류 = %v", err)
+	}
+}
+
+func TestDechunk(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		want  string
+		noErr bool
+	}{
+		{"기본", "5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n", "hello world", true},
+		{"LF만", "5\nhello\n0\n\n", "hello", true},
+		{"대문자 16진수", "A\r\n0123456789\r\n0\r\n\r\n", "0123456789", true},
+		{"확장", "5;name=value\r\nhello\r\n3 ; ext\r\nabc\r\n0;last\r\n\r\n", "helloabc", true},
+		{"트레일러", "3\r\nabc\r\n0\r\nExpires: never\r\n\r\n", "abc", true},
+		{"본문의 CRLF", "4\r\na\r\nb\r\n0\r\n\r\n", "a\r\nb", true},
+		{"chunk 잘림", "5\r\nhello\r\n10\r\nshort", "helloshort", false},
+		{"크기 줄 잘림", "5\r\nhello\r\n1", "hello", false},
+		{"마지막 chunk 없음", "5\r\nhello\r\n", "hello", false},
+		{"잘못된 크기", "5\r\nhello\r\nzz\r\nabc\r\n0\r\n\r\n", "hello", false},
+		{"음수 크기", "-1\r\nabc\r\n", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dechunk([]byte(tt.body))
+			if string(got) != tt.want {
+				t.Errorf("dechunk = %q, want %q", got, tt.want)
+			}
+			if (err == nil) != tt.noErr {
+				t.Errorf("오류 = %v", err)
+			}
+		})
+	}
+
+	// ParseResponse는 잘린 chunk도 받은 데이터까지 본문으로 씀
+	resp, err := ParseResponse([]byte("HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n10\r\nwor"))
+	if err != nil || string(resp.Body) != "hellowor" {
+		t.Errorf("잘린 chunked 응답 = %q, %v", resp.Body, err)
+	}
+	// chunked가 아닌 Transfer-Encoding은 그대로 둠
+	resp, err = ParseResponse([]byte("HTTP/1.1 200 OK\r\nTransfer-Encoding: identity\r\n\r\n5\r\nhello\r\n0\r\n\r\n"))
+	if err != nil || string(resp.Body) != "5\r\nhello\r\n0\r\n\r\n" {
+		t.Errorf("identity 응답 = %q, %v", resp.Body, err)
+	}
+}
+
+func TestParseResponseEncoding(t *testing.T) {
+	text := []byte(strings.Repeat("<p>압축된 본문입니다.</p>\n", 50))
+	gz := compress(t, "gzip", text)
+
+	tests := []struct {
+		name string
+		head string
+		body []byte
+		want []byte
+		fail bool // EncodingErr 기대
+	}{
+		{"gzip", "Content-Encoding: gzip\r\n", gz, text, false},
+		{"x-gzip 대문자", "Content-Encoding: X-GZIP\r\n", gz, text, false},
+		{"deflate(zlib)", "Content-Encoding: deflate\r\n", compress(t, "deflate", text), text, false},
+		{"deflate(raw)", "Content-Encoding: deflate\r\n", compress(t, "raw-deflate", text), text, false},
+		{"br", "Content-Encoding: br\r\n", compress(t, "br", text), text, false},
+		{"identity", "Content-Encoding: identity\r\n", text, text, false},
+		{"알 수 없는 인코딩", "Content-Encoding: zstd-unknown\r\n", []byte("raw"), []byte("raw"), false},
+		{"gzip 다음 br", "Content-Encoding: gzip, br\r\n", compress(t, "br", gz), text, false},
+		{"반복 필드", "Content-Encoding: gzip\r\nContent-Encoding: br\r\n", compress(t, "br", gz), text, false},
+		{"chunked와 gzip", "Transfer-Encoding: chunked\r\nContent-Encoding: gzip\r\n", chunked(gz, 16), text, false},
+		{"chunked 대소문자", "Transfer-Encoding: Chunked\r\nContent-Encoding: gzip\r\n", chunked(gz, 100), text, false},
+		{"잘린 gzip", "Content-Encoding: gzip\r\n", compress(t, "gzip", text)[:60], nil, false},
+
+		// 해제된 부분이 없으면 해제하지 않은 본문을 그대로 씀
+		{"gzip이 아닌 본문", "Content-Encoding: gzip\r\n", text, text, true},
+		{"gzip 헤더만 있음", "Content-Encoding: gzip\r\n", gz[:10], gz[:10], true},
+		{"br이 아닌 본문", "Content-Encoding: br\r\n", []byte("plain"), []byte("plain"), true},
+		{"gzip 다음 br 중 gzip 실패", "Content-Encoding: gzip, br\r\n", compress(t, "br", text), text, true},
+		{"gzip 다음 br 중 br 실패", "Content-Encoding: gzip, br\r\n", gz, gz, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ParseResponse(response("HTTP/1.1 200 OK\r\n"+tt.head+"\r\n", tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (resp.EncodingErr != nil) != tt.fail || tt.fail && !errors.Is(resp.EncodingErr, ErrHTTPEncoding) {
+				t.Errorf("EncodingErr = %v", resp.EncodingErr)
+			}
+			if tt.want == nil {
+				// 잘린 본문은 해제된 앞부분만 남음
+				if len(resp.Body) == 0 || !bytes.HasPrefix(text, resp.Body) {
+					t.Errorf("Body = %q", resp.Body)
+				}
+				return
+			}
+			if !bytes.Equal(resp.Body, tt.want) {
+				t.Errorf("Body = %q", resp.Body)
+			}
+		})
+	}
+}
+
+func TestParseResponseDecompressionBomb(t *testing.T) {
+	zeros := make([]byte, 1<<20)
+	for _, encoding := range []string{"gzip", "deflate", "br"} {
+		t.Run(encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			var w io.WriteCloser
+			switch encoding {
+			case "gzip":
+				w = gzip.NewWriter(&buf)
+			case "deflate":
+				w = zlib.NewWriter(&buf)
+			case "br":
+				w = brotli.NewWriter(&buf)
+			}
+			for i := 0; i <= maxDecodedSize>>20; i++ {
+				w.Write(zeros)
+			}
+			w.Close()
+
+			_, err := ParseResponse(response("HTTP/1.1 200 OK\r\nContent-Encoding: "+encoding+"\r\n\r\n", buf.Bytes()))
+			if !errors.Is(err, ErrHTTPEncoding) {
+				t.Errorf("%d바이트 압축 본문(해제 시 %d MiB): 오류 = %v", buf.Len(), maxDecodedSize>>20+1, err)
+			}
+		})
+	}
+
+	// 한도 이하는 그대로 해제
+	exact := compress(t, "gzip", make([]byte, maxDecodedSize))
+	resp, err := ParseResponse(response("HTTP/1.1 200 OK\r\nContent-Encoding: gzip\r\n\r\n", exact))
+	if err != nil || len(resp.Body) != maxDecodedSize {
+		t.Errorf("한도 크기 본문: %d바이트, %v", len(resp.Body), err)
+	}
+}
+
+// chunked는 p를 size바이트씩 나누어 chunked 전송 인코딩으로 만듭니다.
+func chunked(p []byte, size int) []byte {
+	var buf bytes.Buffer
+	for len(p) > 0 {
+		n := min(size, len(p))
+		fmt.Fprintf(&buf, "%x\r\n%s\r\n", n, p[:n])
+		p = p[n:]
+	}
+	buf.WriteString("0\r\n\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Member는 레코드 단위로 압축된 WARC 파일 안의 gzip 멤버 위치입니다.
+type Member struct {
+	Offset int64
+	Length int64
+}
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// Members는 r을 처음부터 끝까지 읽으며 gzip 멤버의 오프셋과 길이를 모두 반환합니다.
+// 경계를 정확히 알기 위해 전체를 한 번 압축 해제합니다.
+func Members(r io.Reader) ([]Member, error) {
+	cr := &countReader{br: bufio.NewReader(r)}
+	zr := new(gzip.Reader)
+
+	var members []Member
+	for {
+		if _, err := cr.br.Peek(1); err == io.EOF {
+			return members, nil
+		}
+
+		start := cr.n
+		if err := zr.Reset(cr); err != nil {
+			return members, fmt.Errorf("warc: gzip 멤버 헤더 오류(offset %d): %w", start, err)
+		}
+		zr.Multistream(false)
+		if _, err := io.Copy(io.Discard, zr); err != nil {
+			return members, fmt.Errorf("warc: gzip 멤버 압축 해제 오류(offset %d): %w", start, err)
+		}
+		members = append(members, Member{Offset: start, Length: cr.n - start})
+	}
+}
+
+// OpenRecord는 offset 위치의 gzip 멤버 하나를 열어 그 안의 레코드를 반환합니다.
+// length가 0 이하이면 멤버가 끝날 때까지 읽습니다.
+func OpenRecord(ra io.ReaderAt, offset, length int64) (*Record, error) {
+	if length <= 0 {
+		length = 1<<63 - 1 - offset
+	}
+	zr, err := gzip.NewReader(io.NewSectionReader(ra, offset, length))
+	if err != nil {
+		return nil, fmt.Errorf("warc: gzip 멤버 열기 오류(offset %d): %w", offset, err)
+	}
+	zr.Multistream(false)
+	return NewReader(zr).Next()
+}
+
+// SplitMembers는 크기 size인 파일을 최대 n개 구간으로 나눌 수 있도록
+// gzip 멤버 경계에 맞춘 시작 오프셋 목록을 반환합니다. 첫 오프셋은 항상 0입니다.
+// 압축 해제 없이 gzip 매직 바이트를 찾고, 그 위치에서 WARC 레코드가 시작하는지 확인합니다.
+// 각 구간 [offsets[i], offsets[i+1])은 독립적으로 압축 해제할 수 있습니다.
+func SplitMembers(ra io.ReaderAt, size int64, n int) ([]int64, error) {
+	offsets := []int64{0}
+	for i := 1; i < n; i++ {
+		from := size * int64(i) / int64(n)
+		if from <= offsets[len(offsets)-1] {
+			continue
+		}
+		off, err := nextMember(ra, size, from)
+		if err != nil {
+			return nil, err
+		}
+		if off < 0 {
+			break
+		}
+		if off > offsets[len(offsets)-1] {
+			offsets = append(offsets, off)
+		}
+	}
+	return offsets, nil
+}
+
+// nextMember는 from 이후 처음으로 WARC 레코드가 시작되는 gzip 멤버의 오프셋을 찾습니다.
+// 찾지 못하면 -1을 반환합니다.
+func nextMember(ra io.ReaderAt, size, from int64) (int64, error) {
+	const chunkSize = 64 << 10
+	buf := make([]byte, chunkSize+len(gzipMagic)-1)
+
+	for pos := from; pos < size; pos += chunkSize {
+		n, err := ra.ReadAt(buf, pos)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return -1, err
+		}
+		chunk := buf[:n]
+		for i := 0; ; {
+			j := bytes.Index(chunk[i:], gzipMagic)
+			if j == -1 {
+				break
+			}
+			off := pos + int64(i+j)
+			if isRecordMember(ra, off, size) {
+				return off, nil
+			}
+			i += j + 1
+		}
+		if n < len(buf) {
+			break
+		}
+	}
+	return -1, nil
+}
+
+// isRecordMember는 offset에서 시작하는 gzip 멤버가 WARC 레코드로 시작하는지 확인합니다.
+func isRecordMember(ra io.ReaderAt, offset, size int64) bool {
+	zr, err := gzip.NewReader(io.NewSectionReader(ra, offset, size-offset))
+	if err != nil {
+		return false
+	}
+	zr.Multistream(false)
+	head := make([]byte, len("WARC/1."))
+	if _, err := io.ReadFull(zr, head); err != nil {
+		return false
+	}
+	return string(head) == "WARC/1."
+}
+
+// countReader는 gzip.Reader가 추가 버퍼링 없이 사용할 수 있도록 io.ByteReader를 구현하며,
+// 소비한 압축 바이트 수를 셉니다.
+type countReader struct {
+	br *bufio.Reader
+	n  int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.br.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countReader) ReadByte() (byte, error) {
+	b, err := c.br.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
@@ -0,0 +1,150 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// memberFile은 n개의 response 레코드를 레코드별 gzip 멤버로 기록합니다.
+// 본문에 gzip 매직 바이트를 넣고 압축하지 않은 멤버도 섞어, 멤버 경계가 아닌 매직 바이트를 만듭니다.
+func memberFile(t *testing.T, n int) ([]byte, []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	var uris []string
+	for i := range n {
+		uri := fmt.Sprintf("https://a.com/%d", i)
+		block := fmt.Sprintf("HTTP/1.1 200 OK\r\n\r\n%s", strings.Repeat(fmt.Sprintf("기사 %d ", i), 200+i*37))
+		level := gzip.DefaultCompression
+		if i%3 == 1 {
+			block += "\x1f\x8b\x08 gzip 아님"
+			level = gzip.NoCompression
+		}
+		var rec bytes.Buffer
+		w := NewWriter(&rec)
+		w.Compress = false
+		if _, err := w.WriteRecord(Header{{Name: "WARC-Type", Value: TypeResponse}, {Name: "WARC-Target-URI", Value: uri}}, []byte(block)); err != nil {
+			t.Fatal(err)
+		}
+		gw, _ := gzip.NewWriterLevel(&buf, level)
+		gw.Write(rec.Bytes())
+		gw.Close()
+		uris = append(uris, uri)
+	}
+	return buf.Bytes(), uris
+}
+
+// readURIs는 gzip 스트림 r의 레코드 URI를 모두 읽습니다.
+func readURIs(r io.Reader) ([]string, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	wr := NewReader(zr)
+	var uris []string
+	for {
+		rec, err := wr.Next()
+		if err == io.EOF {
+			return uris, nil
+		}
+		if err != nil {
+			return uris, err
+		}
+		uris = append(uris, rec.TargetURI())
+	}
+}
+
+func TestMembers(t *testing.T) {
+	data, uris := memberFile(t, 7)
+	members, err := Members(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != len(uris) {
+		t.Fatalf("멤버 %d개, 기대값 %d", len(members), len(uris))
+	}
+	var end int64
+	for i, m := range members {
+		if m.Offset != end || m.Length <= 0 {
+			t.Errorf("멤버 %d = %+v, 앞 멤버의 끝 %d", i, m, end)
+		}
+		end = m.Offset + m.Length
+		rec, err := OpenRecord(bytes.NewReader(data), m.Offset, m.Length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.TargetURI() != uris[i] {
+			t.Errorf("OpenRecord(%d) = %s, want %s", m.Offset, rec.TargetURI(), uris[i])
+		}
+	}
+	if end != int64(len(data)) {
+		t.Errorf("마지막 멤버 끝 %d, 파일 %d바이트", end, len(data))
+	}
+
+	if m, err := Members(bytes.NewReader(nil)); err != nil || len(m) != 0 {
+		t.Errorf("빈 입력: %v, %v", m, err)
+	}
+	if m, err := Members(bytes.NewReader(append(data[:len(data):len(data)], "garbage"...))); err == nil || len(m) != len(uris) {
+		t.Errorf("뒤에 붙은 쓰레기: 멤버 %d개, 오류 %v", len(m), err)
+	}
+	if _, err := Members(bytes.NewReader(data[:len(data)-5])); err == nil {
+		t.Error("잘린 마지막 멤버에서 오류가 없습니다")
+	}
+	if _, err := OpenRecord(bytes.NewReader(data), members[1].Offset+1, 0); err == nil {
+		t.Error("멤버 경계가 아닌 오프셋에서 오류가 없습니다")
+	}
+}
+
+// 어느 개수로 나누더라도 구간은 멤버 경계에서 시작하고, 구간별로 읽은 레코드를 이으면 전체와 같아야 합니다.
+func TestSplitMembers(t *testing.T) {
+	data, uris := memberFile(t, 7)
+	members, err := Members(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	boundary := map[int64]bool{}
+	for _, m := range members {
+		boundary[m.Offset] = true
+	}
+	size := int64(len(data))
+
+	for _, n := range []int{1, 2, 3, 5, 7, 20} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			offsets, err := SplitMembers(bytes.NewReader(data), size, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if offsets[0] != 0 || len(offsets) > n || len(offsets) > len(members) {
+				t.Fatalf("offsets = %v", offsets)
+			}
+			if n > 1 && len(offsets) < 2 {
+				t.Errorf("%d개로 나눴지만 구간이 %d개", n, len(offsets))
+			}
+			var got []string
+			for i, o := range offsets {
+				if !boundary[o] || i > 0 && o <= offsets[i-1] {
+					t.Fatalf("offsets = %v: %d는 멤버 경계가 아니거나 순서가 틀림", offsets, o)
+				}
+				end := size
+				if i+1 < len(offsets) {
+					end = offsets[i+1]
+				}
+				part, err := readURIs(io.NewSectionReader(bytes.NewReader(data), o, end-o))
+				if err != nil {
+					t.Fatalf("구간 %d: %v", i, err)
+				}
+				got = append(got, part...)
+			}
+			if strings.Join(got, " ") != strings.Join(uris, " ") {
+				t.Errorf("레코드 %v, want %v", got, uris)
+			}
+		})
+	}
+
+	if offsets, err := SplitMembers(bytes.NewReader(data[:100]), 100, 4); err != nil || len(offsets) != 1 {
+		t.Errorf("멤버 하나 안의 구간: %v, %v", offsets, err)
+	}
+}
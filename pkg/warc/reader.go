@@ -0,0 +1,144 @@
+package warc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrVersion       = errors.New("warc: 지원하지 않는 버전 라인")
+	ErrHeader        = errors.New("warc: 잘못된 헤더 라인")
+	ErrContentLength = errors.New("warc: Content-Length 누락 또는 오류")
+	ErrTrailer       = errors.New("warc: 레코드 끝 CRLF CRLF 누락")
+)
+
+// Reader는 io.Reader로부터 WARC 레코드를 순서대로 읽습니다.
+// gzip 압축된 파일은 호출자가 gzip.Reader로 감싸서 전달해야 합니다.
+type Reader struct {
+	br   *bufio.Reader
+	body *io.LimitedReader
+}
+
+// NewReader는 r에서 WARC 레코드를 읽는 Reader를 생성합니다.
+func NewReader(r io.Reader) *Reader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{br: br}
+}
+
+// Next는 다음 레코드를 반환합니다.
+// 이전 레코드 본문 중 읽지 않은 부분은 버리고, 레코드 사이의 CRLF CRLF를 확인합니다.
+// 더 이상 레코드가 없으면 io.EOF를 반환합니다.
+func (r *Reader) Next() (*Record, error) {
+	if r.body != nil {
+		if _, err := io.Copy(io.Discard, r.body); err != nil {
+			return nil, err
+		}
+		if r.body.N > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		r.body = nil
+		if err := r.readTrailer(); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := r.readVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrContentLength, header.Get("Content-Length"))
+	}
+
+	r.body = &io.LimitedReader{R: r.br, N: length}
+	return &Record{Version: version, Header: header, Body: r.body}, nil
+}
+
+// readVersion은 레코드 앞의 빈 줄을 건너뛰고 버전 라인을 읽습니다.
+func (r *Reader) readVersion() (string, error) {
+	for {
+		line, err := r.br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return "", io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if err == io.EOF {
+				return "", io.EOF
+			}
+			continue
+		}
+		if line != Version10 && line != Version11 {
+			return "", fmt.Errorf("%w: %q", ErrVersion, line)
+		}
+		return line, nil
+	}
+}
+
+// readHeader는 빈 줄이 나올 때까지 헤더 필드를 읽습니다.
+// 공백이나 탭으로 시작하는 줄은 앞 필드 값의 연장으로 처리합니다.
+func (r *Reader) readHeader() (Header, error) {
+	var header Header
+	for {
+		line, err := r.br.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return header, nil
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(header) == 0 {
+				return nil, fmt.Errorf("%w: %q", ErrHeader, line)
+			}
+			last := &header[len(header)-1]
+			last.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%w: %q", ErrHeader, line)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+}
+
+// readTrailer는 레코드 본문 뒤의 CRLF CRLF 두 줄을 읽습니다.
+func (r *Reader) readTrailer() error {
+	for i := 0; i < 2; i++ {
+		line, err := r.br.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("%w: %v", ErrTrailer, io.ErrUnexpectedEOF)
+			}
+			return err
+		}
+		if strings.TrimRight(line, "\r\n") != "" {
+			return fmt.Errorf("%w: %q", ErrTrailer, line)
+		}
+	}
+	return nil
+}
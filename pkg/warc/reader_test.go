@@ -0,0 +1,114 @@
+package warc
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// record는 WARC/1.0 레코드 하나를 만듭니다. 헤더 필드는 "이름: 값" 형식입니다.
+func record(block string, fields ...string) string {
+	var b strings.Builder
+	b.WriteString("WARC/1.0\r\n")
+	for _, f := range fields {
+		b.WriteString(f + "\r\n")
+	}
+	b.WriteString("Content-Length: " + strconv.Itoa(len(block)) + "\r\n\r\n")
+	b.WriteString(block)
+	b.WriteString("\r\n\r\n")
+	return b.String()
+}
+
+func TestReader(t *testing.T) {
+	resp := record("HTTP/1.1 200 OK\r\n\r\nbody", "WARC-Type: response", "WARC-Target-URI: https://a.com/")
+	meta := record("{}", "WARC-Type: metadata", "WARC-Target-URI: https://a.com/")
+
+	tests := []struct {
+		name   string
+		input  string
+		types  []string // 읽은 레코드의 WARC-Type
+		bodies []string // 읽은 레코드 본문 (nil이면 읽지 않고 건너뜀)
+		err    error    // 레코드를 모두 읽은 뒤의 오류, nil이면 io.EOF
+	}{
+		{"레코드 두 개", resp + meta, []string{"response", "metadata"}, []string{"HTTP/1.1 200 OK\r\n\r\nbody", "{}"}, nil},
+		{"본문 건너뜀", resp + meta, []string{"response", "metadata"}, nil, nil},
+		{"빈 입력", "", nil, nil, nil},
+		{"앞의 빈 줄", "\r\n\r\n" + resp, []string{"response"}, nil, nil},
+		{"WARC/1.1", strings.Replace(resp, "WARC/1.0", "WARC/1.1", 1), []string{"response"}, nil, nil},
+		{"LF 줄바꿈", "WARC/1.0\nWARC-Type: resource\nContent-Length: 2\n\nab\n\n", []string{"resource"}, []string{"ab"}, nil},
+		{"빈 본문", record("", "WARC-Type: request"), []string{"request"}, []string{""}, nil},
+		{"지원하지 않는 버전", "WARC/0.9\r\nContent-Length: 0\r\n\r\n\r\n\r\n", nil, nil, ErrVersion},
+		{"헤더 콜론 없음", "WARC/1.0\r\nWARC-Type response\r\n\r\n", nil, nil, ErrHeader},
+		{"첫 줄이 연장", "WARC/1.0\r\n WARC-Type: response\r\n\r\n", nil, nil, ErrHeader},
+		{"Content-Length 없음", "WARC/1.0\r\nWARC-Type: response\r\n\r\n", nil, nil, ErrContentLength},
+		{"Content-Length 음수", "WARC/1.0\r\nContent-Length: -1\r\n\r\n", nil, nil, ErrContentLength},
+		{"헤더 중간에서 끝남", "WARC/1.0\r\nWARC-Type: response\r\n", nil, nil, io.ErrUnexpectedEOF},
+		{"본문 잘림", resp[:len(resp)-10], []string{"response"}, nil, io.ErrUnexpectedEOF},
+		{"끝 CRLF 누락", strings.TrimSuffix(resp, "\r\n\r\n"), []string{"response"}, nil, ErrTrailer},
+		{"본문이 Content-Length보다 김", strings.Replace(resp, "body\r\n\r\n", "body!\r\n\r\n", 1), []string{"response"}, nil, ErrTrailer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(tt.input))
+			var types []string
+			var last error
+			for {
+				rec, err := r.Next()
+				if err != nil {
+					last = err
+					break
+				}
+				if tt.bodies != nil {
+					body, err := io.ReadAll(rec.Body)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if i := len(types); string(body) != tt.bodies[i] {
+						t.Errorf("레코드 %d 본문 = %q, want %q", i, body, tt.bodies[i])
+					}
+					if rec.ContentLength() != int64(len(body)) {
+						t.Errorf("ContentLength = %d, 본문 %d바이트", rec.ContentLength(), len(body))
+					}
+				}
+				types = append(types, rec.Type())
+			}
+			if strings.Join(types, ",") != strings.Join(tt.types, ",") {
+				t.Errorf("레코드 %q, want %q (오류 %v)", types, tt.types, last)
+			}
+			if tt.err == nil && last != io.EOF || tt.err != nil && !errors.Is(last, tt.err) {
+				t.Errorf("오류 = %v, want %v", last, tt.err)
+			}
+		})
+	}
+}
+
+func TestReaderHeader(t *testing.T) {
+	input := "WARC/1.0\r\n" +
+		"WARC-Type: response\r\n" +
+		"warc-target-uri:  https://a.com/path \r\n" +
+		"WARC-Concurrent-To: <urn:uuid:1>\r\n" +
+		"WARC-Concurrent-To: <urn:uuid:2>\r\n" +
+		"X-Long: 첫 줄\r\n" +
+		"\t이어지는 줄\r\n" +
+		"WARC-Date: 2025-04-01T00:00:01.5Z\r\n" +
+		"WARC-Record-ID: <urn:uuid:0>\r\n" +
+		"Content-Length: 0\r\n\r\n\r\n\r\n"
+	rec, err := NewReader(strings.NewReader(input)).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Version != Version10 || rec.TargetURI() != "https://a.com/path" || rec.RecordID() != "<urn:uuid:0>" {
+		t.Errorf("Version %q, TargetURI %q, RecordID %q", rec.Version, rec.TargetURI(), rec.RecordID())
+	}
+	if got := rec.Header.Values("WARC-Concurrent-To"); len(got) != 2 || got[1] != "<urn:uuid:2>" {
+		t.Errorf("Values = %q", got)
+	}
+	if got := rec.Header.Get("x-long"); got != "첫 줄 이어지는 줄" {
+		t.Errorf("연장 줄 = %q", got)
+	}
+	if d, err := rec.Date(); err != nil || d.Nanosecond() != 5e8 {
+		t.Errorf("Date = %v, %v", d, err)
+	}
+}
@@ -0,0 +1,114 @@
+// Package warc는 WARC 1.0/1.1 파일을 스트리밍 방식으로 읽고 쓰는 기능을 제공합니다.
+package warc
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 지원하는 WARC 버전 라인
+const (
+	Version10 = "WARC/1.0"
+	Version11 = "WARC/1.1"
+)
+
+// 자주 사용하는 WARC-Type 값
+const (
+	TypeWarcinfo     = "warcinfo"
+	TypeResponse     = "response"
+	TypeResource     = "resource"
+	TypeRequest      = "request"
+	TypeMetadata     = "metadata"
+	TypeRevisit      = "revisit"
+	TypeConversion   = "conversion"
+	TypeContinuation = "continuation"
+)
+
+// Field는 WARC 헤더의 이름/값 한 쌍입니다.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Header는 WARC 레코드 헤더입니다.
+// 필드 순서를 보존하며 같은 이름의 필드가 여러 번 나타날 수 있습니다(WARC-Concurrent-To 등).
+type Header []Field
+
+// Get은 이름이 일치하는 첫 번째 필드 값을 반환합니다. 이름은 대소문자를 구분하지 않습니다.
+func (h Header) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values는 이름이 일치하는 모든 필드 값을 순서대로 반환합니다.
+func (h Header) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
+
+// Add는 필드를 헤더 끝에 추가합니다.
+func (h *Header) Add(name, value string) {
+	*h = append(*h, Field{Name: name, Value: value})
+}
+
+// Set은 같은 이름의 기존 필드를 모두 제거하고 새 값을 설정합니다.
+func (h *Header) Set(name, value string) {
+	h.Del(name)
+	h.Add(name, value)
+}
+
+// Del은 이름이 일치하는 모든 필드를 제거합니다.
+func (h *Header) Del(name string) {
+	fields := (*h)[:0]
+	for _, f := range *h {
+		if !strings.EqualFold(f.Name, name) {
+			fields = append(fields, f)
+		}
+	}
+	*h = fields
+}
+
+// Record는 WARC 레코드 하나입니다.
+// Body는 Content-Length 만큼으로 제한된 Reader이며, Reader.Next 호출 전까지만 유효합니다.
+type Record struct {
+	Version string
+	Header  Header
+	Body    io.Reader
+}
+
+// Type은 WARC-Type 값을 반환합니다.
+func (r *Record) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// TargetURI는 WARC-Target-URI 값을 반환합니다.
+func (r *Record) TargetURI() string {
+	return r.Header.Get("WARC-Target-URI")
+}
+
+// RecordID는 WARC-Record-ID 값을 반환합니다.
+func (r *Record) RecordID() string {
+	return r.Header.Get("WARC-Record-ID")
+}
+
+// ContentLength는 Content-Length 값을 반환합니다.
+func (r *Record) ContentLength() int64 {
+	n, _ := strconv.ParseInt(r.Header.Get("Content-Length"), 10, 64)
+	return n
+}
+
+// Date는 WARC-Date 값을 시간으로 변환합니다.
+func (r *Record) Date() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, r.Header.Get("WARC-Date"))
+}
@@ -0,0 +1,150 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"parkjunwoo.com/crowl/internal/iox"
+)
+
+// Software는 warcinfo 레코드에 기록되는 소프트웨어 이름입니다.
+const Software = "crowl"
+
+// Writer는 WARC 레코드를 기록합니다.
+// Compress가 true이면 레코드마다 별도의 gzip 멤버로 압축하여 기록하므로
+// 표준 도구(warcio, pywb 등)에서 레코드 단위로 임의 접근할 수 있습니다.
+type Writer struct {
+	Version  string
+	Compress bool
+
+	w          io.Writer
+	offset     int64
+	warcinfoID string
+}
+
+// NewWriter는 레코드 단위 gzip 압축을 사용하는 WARC/1.0 Writer를 생성합니다.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{Version: Version10, Compress: true, w: w}
+}
+
+// Offset은 지금까지 기록한 바이트 수(다음 레코드가 시작될 위치)를 반환합니다.
+func (w *Writer) Offset() int64 {
+	return w.offset
+}
+
+// WriteWarcinfo는 파일 맨 앞에 warcinfo 레코드를 기록합니다.
+// 이후 기록되는 레코드에는 WARC-Warcinfo-ID가 이 레코드의 ID로 설정됩니다.
+func (w *Writer) WriteWarcinfo(filename string, fields Header) (string, error) {
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "software: %s\r\n", Software)
+	fmt.Fprintf(&block, "format: WARC File Format %s\r\n", strings.TrimPrefix(w.Version, "WARC/"))
+	for _, f := range fields {
+		fmt.Fprintf(&block, "%s: %s\r\n", f.Name, f.Value)
+	}
+
+	header := Header{
+		{Name: "WARC-Type", Value: TypeWarcinfo},
+		{Name: "WARC-Filename", Value: filename},
+		{Name: "Content-Type", Value: "application/warc-fields"},
+	}
+	id, err := w.WriteRecord(header, block.Bytes())
+	if err != nil {
+		return "", err
+	}
+	w.warcinfoID = id
+	return id, nil
+}
+
+// WriteRecord는 header와 block으로 레코드 하나를 기록하고 레코드 ID를 반환합니다.
+// WARC-Record-ID, WARC-Date, WARC-Block-Digest가 없으면 생성하고,
+// application/http 응답 레코드에는 WARC-Payload-Digest도 계산하여 채웁니다.
+// Content-Length는 항상 block 길이로 다시 설정합니다.
+func (w *Writer) WriteRecord(header Header, block []byte) (string, error) {
+	h := make(Header, len(header))
+	copy(h, header)
+
+	if h.Get("WARC-Record-ID") == "" {
+		h.Set("WARC-Record-ID", NewRecordID())
+	}
+	if h.Get("WARC-Date") == "" {
+		h.Set("WARC-Date", time.Now().UTC().Format(time.RFC3339))
+	}
+	if w.warcinfoID != "" && h.Get("WARC-Type") != TypeWarcinfo {
+		h.Set("WARC-Warcinfo-ID", w.warcinfoID)
+	}
+	if h.Get("WARC-Block-Digest") == "" {
+		h.Set("WARC-Block-Digest", Digest(block))
+	}
+	if h.Get("WARC-Payload-Digest") == "" && isHTTPResponse(h) {
+		if payload, ok := HTTPPayload(block); ok {
+			h.Set("WARC-Payload-Digest", Digest(payload))
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(block)))
+
+	var buf bytes.Buffer
+	buf.WriteString(w.Version + "\r\n")
+	for _, f := range h {
+		buf.WriteString(f.Name + ": " + f.Value + "\r\n")
+	}
+	buf.WriteString("\r\n")
+	buf.Write(block)
+	buf.WriteString("\r\n\r\n")
+
+	if err := w.writeMember(buf.Bytes()); err != nil {
+		return "", fmt.Errorf("warc: 레코드 기록 오류(%s): %w", h.Get("WARC-Target-URI"), err)
+	}
+	return h.Get("WARC-Record-ID"), nil
+}
+
+// writeMember는 레코드 하나를 (압축 시 독립된 gzip 멤버로) 기록합니다.
+func (w *Writer) writeMember(p []byte) error {
+	cw := &iox.CountWriter{W: w.w}
+	if w.Compress {
+		gw := gzip.NewWriter(cw)
+		if _, err := gw.Write(p); err != nil {
+			return err
+		}
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	} else if _, err := cw.Write(p); err != nil {
+		return err
+	}
+	w.offset += cw.N
+	return nil
+}
+
+// NewRecordID는 urn:uuid 형식의 새 레코드 ID를 생성합니다.
+func NewRecordID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// Digest는 Common Crawl과 같은 sha1 base32 형식의 다이제스트를 반환합니다.
+func Digest(p []byte) string {
+	sum := sha1.Sum(p)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// HTTPPayload는 application/http 블록에서 HTTP 헤더를 제외한 본문(인코딩 해제 전)을 반환합니다.
+func HTTPPayload(block []byte) ([]byte, bool) {
+	_, body, ok := splitHTTP(block)
+	return body, ok
+}
+
+func isHTTPResponse(h Header) bool {
+	return h.Get("WARC-Type") == TypeResponse &&
+		strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "application/http")
+}
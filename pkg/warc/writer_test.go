@@ -0,0 +1,172 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testBlocks = []struct {
+	header Header
+	block  string
+}{
+	{Header{{Name: "WARC-Type", Value: TypeResponse}, {Name: "WARC-Target-URI", Value: "https://a.com/"}, {Name: "Content-Type", Value: "application/http; msgtype=response"}},
+		"HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<p>첫 기사</p>"},
+	{Header{{Name: "WARC-Type", Value: TypeMetadata}, {Name: "WARC-Target-URI", Value: "https://a.com/"}, {Name: "Content-Type", Value: "application/json"}},
+		`{"lang":"ko"}`},
+	{Header{{Name: "WARC-Type", Value: TypeConversion}, {Name: "WARC-Target-URI", Value: "https://b.com/"}, {Name: "WARC-Record-ID", Value: "<urn:uuid:fixed>"}, {Name: "Content-Length", Value: "999"}},
+		""},
+}
+
+// writeTest는 warcinfo와 testBlocks를 기록하고 파일 내용과 레코드 ID, 각 레코드의 시작 오프셋을 반환합니다.
+func writeTest(t *testing.T, compress bool) ([]byte, []string, []int64) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Compress = compress
+
+	var ids []string
+	var offsets []int64
+	offsets = append(offsets, w.Offset())
+	id, err := w.WriteWarcinfo("out.warc.gz", Header{{Name: "description", Value: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = append(ids, id)
+	for _, b := range testBlocks {
+		offsets = append(offsets, w.Offset())
+		id, err := w.WriteRecord(b.header, []byte(b.block))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if w.Offset() != int64(buf.Len()) {
+		t.Errorf("Offset = %d, 기록한 바이트 %d", w.Offset(), buf.Len())
+	}
+	return buf.Bytes(), ids, offsets
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, compress := range []bool{true, false} {
+		name := "압축 안 함"
+		if compress {
+			name = "레코드별 gzip"
+		}
+		t.Run(name, func(t *testing.T) {
+			data, ids, _ := writeTest(t, compress)
+
+			var r io.Reader = bytes.NewReader(data)
+			if compress {
+				zr, err := gzip.NewReader(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r = zr
+			}
+			wr := NewReader(r)
+
+			info, err := wr.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			block, _ := io.ReadAll(info.Body)
+			if info.Type() != TypeWarcinfo || info.Header.Get("WARC-Filename") != "out.warc.gz" || info.Header.Get("WARC-Warcinfo-ID") != "" {
+				t.Errorf("warcinfo 헤더 = %v", info.Header)
+			}
+			if !strings.Contains(string(block), "software: crowl\r\n") || !strings.Contains(string(block), "description: test\r\n") {
+				t.Errorf("warcinfo 본문 = %q", block)
+			}
+
+			for i, want := range testBlocks {
+				rec, err := wr.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				block, err := io.ReadAll(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(block) != want.block || rec.Type() != want.header.Get("WARC-Type") || rec.TargetURI() != want.header.Get("WARC-Target-URI") {
+					t.Errorf("레코드 %d = %v %q", i, rec.Header, block)
+				}
+				if rec.RecordID() != ids[i+1] || rec.Header.Get("WARC-Warcinfo-ID") != ids[0] {
+					t.Errorf("레코드 %d ID %q, Warcinfo-ID %q, want %q, %q", i, rec.RecordID(), rec.Header.Get("WARC-Warcinfo-ID"), ids[i+1], ids[0])
+				}
+				if rec.ContentLength() != int64(len(want.block)) || len(rec.Header.Values("Content-Length")) != 1 {
+					t.Errorf("레코드 %d Content-Length %v", i, rec.Header.Values("Content-Length"))
+				}
+				if _, err := rec.Date(); err != nil {
+					t.Errorf("레코드 %d WARC-Date: %v", i, err)
+				}
+				if err := Verify(rec.Header, block); err != nil {
+					t.Errorf("레코드 %d: %v", i, err)
+				}
+			}
+			if _, err := wr.Next(); err != io.EOF {
+				t.Errorf("마지막 Next = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriterDigests(t *testing.T) {
+	data, _, offsets := writeTest(t, true)
+	resp, err := OpenRecord(bytes.NewReader(data), offsets[1], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Header.Get("WARC-Payload-Digest"), Digest([]byte("<p>첫 기사</p>")); got != want {
+		t.Errorf("WARC-Payload-Digest = %s, want %s", got, want)
+	}
+	if got, want := resp.Header.Get("WARC-Block-Digest"), Digest([]byte(testBlocks[0].block)); got != want {
+		t.Errorf("WARC-Block-Digest = %s, want %s", got, want)
+	}
+
+	// HTTP 응답이 아닌 레코드에는 페이로드 다이제스트가 없고, 주어진 레코드 ID는 그대로 씀
+	for i, o := range offsets[2:] {
+		rec, err := OpenRecord(bytes.NewReader(data), o, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Header.Get("WARC-Payload-Digest") != "" {
+			t.Errorf("레코드 %d WARC-Payload-Digest = %s", i+1, rec.Header.Get("WARC-Payload-Digest"))
+		}
+	}
+	if rec, _ := OpenRecord(bytes.NewReader(data), offsets[3], 0); rec.RecordID() != "<urn:uuid:fixed>" {
+		t.Errorf("WARC-Record-ID = %s", rec.RecordID())
+	}
+}
+
+// 레코드마다 독립된 gzip 멤버이므로 멤버 경계가 곧 Offset이 반환한 레코드 시작 위치입니다.
+func TestWriterMembers(t *testing.T) {
+	data, ids, offsets := writeTest(t, true)
+	members, err := Members(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != len(offsets) {
+		t.Fatalf("멤버 %d개, 레코드 %d개", len(members), len(offsets))
+	}
+	for i, m := range members {
+		if m.Offset != offsets[i] {
+			t.Errorf("멤버 %d 오프셋 %d, Writer 오프셋 %d", i, m.Offset, offsets[i])
+		}
+		rec, err := OpenRecord(bytes.NewReader(data), m.Offset, m.Length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.RecordID() != ids[i] {
+			t.Errorf("멤버 %d 레코드 ID %s, want %s", i, rec.RecordID(), ids[i])
+		}
+	}
+}
+
+func TestNewRecordID(t *testing.T) {
+	a, b := NewRecordID(), NewRecordID()
+	if a == b || len(a) != len("<urn:uuid:00000000-0000-4000-8000-000000000000>") || a[24] != '4' {
+		t.Errorf("NewRecordID = %s, %s", a, b)
+	}
+}
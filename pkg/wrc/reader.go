@@ -0,0 +1,408 @@
+// Package wrc는 crowl의 처리 결과 파일(.wrc.gz)을 읽고 씁니다.
+//
+// wrc 레코드는 "url\n길이\n본문\n\n" 형식이며, 뉴스 판별 결과가 있으면 url 다음 줄에
+// "Label: " 접두사를 붙인 판정이 들어갑니다 ("url\nLabel: 판정\n길이\n본문\n\n"). 접두사 없이 기록한
+// 이전 파일의 판정 줄은 길이로 해석되지 않는 줄로 구별합니다. 레코드마다 gzip 멤버 하나로 압축합니다.
+//
+// v2 파일은 형식 버전, 원본 파일, crowl 버전, 설정 지문을 담은 헤더("WRC/2")로 시작하고
+// 레코드 수, 레코드 오프셋, 체크섬을 담은 푸터("WRC-Footer")와 푸터 위치를 담은 고정 크기 gzip 멤버로
+// 끝나므로 잘린 파일을 구별할 수 있습니다. 헤더가 없는 v1 파일(이전 버전이 만든 단일 gzip 스트림 포함)도 읽습니다.
+package wrc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrVersion   = errors.New("wrc: 지원하지 않는 형식 버전")
+	ErrLength    = errors.New("wrc: 길이 라인 누락 또는 오류")
+	ErrTrailer   = errors.New("wrc: 레코드 끝 빈 줄 누락")
+	ErrTruncated = errors.New("wrc: 마지막 레코드가 잘림")
+	ErrChecksum  = errors.New("wrc: 푸터의 레코드 수 또는 체크섬 불일치")
+	ErrNoFooter  = errors.New("wrc: 푸터 없음")
+)
+
+// Record는 wrc 레코드 하나입니다.
+type Record struct {
+	URL     string
+	Label   string // 뉴스 판별 모델의 판정 (validate 출력에만 있음)
+	Content []byte
+}
+
+// Reader는 .wrc.gz 파일에서 레코드를 순서대로 읽습니다.
+type Reader struct {
+	gz     *gzip.Reader
+	br     *bufio.Reader
+	header Header
+	footer *Footer
+	sum    hash.Hash // v2 파일의 레코드 프레임 체크섬
+	n      int64     // 읽은 레코드 수
+	done   bool
+}
+
+// NewReader는 gzip 압축된 r에서 wrc 레코드를 읽는 Reader를 생성합니다.
+// v2 파일이면 헤더를 읽어 둡니다. 빈 입력이면 레코드가 없는 Reader를, 헤더가 잘렸으면 ErrTruncated를 반환합니다.
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err == io.EOF {
+		return &Reader{header: Header{Version: 1}, done: true}, nil
+	}
+	if err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%w (헤더): %w", ErrTruncated, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wr := &Reader{gz: gz, br: bufio.NewReader(gz), header: Header{Version: 1}}
+	if prefix, _ := wr.br.Peek(len(magic)); string(prefix) == magic {
+		if err := wr.readHeader(); err != nil {
+			gz.Close()
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("%w (헤더): %w", ErrTruncated, err)
+			}
+			return nil, err
+		}
+		wr.sum = sha256.New()
+	}
+	return wr, nil
+}
+
+// readHeader는 "WRC/버전" 줄과 빈 줄까지의 "이름: 값" 줄을 읽습니다.
+func (r *Reader) readHeader() error {
+	line, err := r.readLine()
+	if err != nil {
+		return fmt.Errorf("wrc 헤더 읽기 오류: %w", unexpected(err))
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(line, magic))
+	if err != nil || version < 2 || version > FormatVersion {
+		return fmt.Errorf("%w: %q", ErrVersion, line)
+	}
+	r.header.Version = version
+
+	return r.readFields(func(name, value string) error {
+		switch name {
+		case "Source":
+			r.header.Source = value
+		case "Crowl-Version":
+			r.header.Crowl = value
+		case "Config-Fingerprint":
+			r.header.Fingerprint = value
+		}
+		return nil
+	})
+}
+
+// readFields는 빈 줄이 나올 때까지 "이름: 값" 줄을 읽어 fn에 넘깁니다.
+func (r *Reader) readFields(fn func(name, value string) error) error {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return unexpected(err)
+		}
+		if line == "" {
+			return nil
+		}
+		name, value, _ := strings.Cut(line, ":")
+		if err := fn(strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+}
+
+// Header는 파일 헤더입니다. v1 파일은 Version만 1이고 나머지는 비어 있습니다.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Footer는 v2 파일의 푸터입니다. 레코드를 끝까지 읽기 전이나 v1 파일이면 nil입니다.
+func (r *Reader) Footer() *Footer {
+	return r.footer
+}
+
+// Next는 다음 레코드를 반환합니다. 더 이상 레코드가 없으면 io.EOF를 반환합니다.
+// 파일 끝의 레코드가 잘려 있거나(기록 중 중단, 다운로드 중단 등) v2 파일에 푸터가 없으면
+// ErrTruncated를, 푸터의 레코드 수나 체크섬이 읽은 내용과 다르면 ErrChecksum을 반환하며,
+// 이후 호출은 io.EOF를 반환합니다.
+func (r *Reader) Next() (*Record, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	rec, err := r.next()
+	if err == nil {
+		r.n++
+		return rec, nil
+	}
+
+	r.done = true
+	if err == io.EOF {
+		if r.header.Version >= 2 && r.footer == nil {
+			return nil, fmt.Errorf("%w (레코드 %d개 뒤): %w", ErrTruncated, r.n, ErrNoFooter)
+		}
+		return nil, io.EOF
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w (%d번째 레코드): %w", ErrTruncated, r.n+1, err)
+	}
+	if errors.Is(err, ErrChecksum) {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%d번째 레코드: %w", r.n+1, err)
+}
+
+func (r *Reader) next() (*Record, error) {
+	// 레코드 사이의 빈 줄은 건너뜀
+	var url string
+	for url == "" {
+		line, err := r.readLine()
+		if err == io.EOF && line == "" {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		url = line
+	}
+	if url == footerMagic && r.header.Version >= 2 {
+		if err := r.readFooter(); err != nil {
+			return nil, err
+		}
+		if err := r.readLocator(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	// url 다음 줄이 판정 줄이면 그 다음 줄이 길이 (접두사 없는 이전 판정 줄은 숫자가 아닌 줄)
+	rec := &Record{URL: url}
+	line, err := r.readLine()
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	var labelLine string
+	if label, ok := strings.CutPrefix(line, labelPrefix); ok {
+		rec.Label, labelLine = label, line
+	} else if _, err := strconv.Atoi(line); err != nil {
+		rec.Label, labelLine = line, line
+	}
+	if labelLine != "" {
+		if line, err = r.readLine(); err != nil {
+			return nil, unexpected(err)
+		}
+	}
+	length, err := strconv.Atoi(line)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("%w: %q (%s)", ErrLength, line, url)
+	}
+
+	rec.Content = make([]byte, length)
+	if _, err := io.ReadFull(r.br, rec.Content); err != nil {
+		return nil, unexpected(err)
+	}
+
+	// 본문 뒤의 "\n\n"
+	for i := 0; i < 2; i++ {
+		b, err := r.br.ReadByte()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		if b != '\n' {
+			return nil, fmt.Errorf("%w (%s)", ErrTrailer, url)
+		}
+	}
+
+	if r.sum != nil {
+		r.sum.Write(appendRawFrame(nil, url, labelLine, rec.Content))
+	}
+	return rec, nil
+}
+
+// readFooter는 푸터를 읽고 지금까지 읽은 레코드 수와 체크섬을 확인합니다.
+func (r *Reader) readFooter() error {
+	f, err := parseFooter(r)
+	if err != nil {
+		return err
+	}
+	r.footer = f
+
+	if f.Records != r.n {
+		return fmt.Errorf("%w: 레코드 %d개, 푸터 %d개", ErrChecksum, r.n, f.Records)
+	}
+	if sum := "sha256:" + hex.EncodeToString(r.sum.Sum(nil)); f.Checksum != sum {
+		return fmt.Errorf("%w: %s, 푸터 %s", ErrChecksum, sum, f.Checksum)
+	}
+	return nil
+}
+
+// readLocator는 푸터 뒤의 푸터 위치 멤버가 온전하고 그 뒤로 파일이 끝나는지 확인합니다.
+// 푸터 위치가 잘리거나 없으면 io.ErrUnexpectedEOF를 반환합니다.
+func (r *Reader) readLocator() error {
+	line, err := r.readLine()
+	if err != nil {
+		return unexpected(err)
+	}
+	if !strings.HasPrefix(line, strings.TrimSpace(locatorPrefix)) {
+		return fmt.Errorf("%w: 푸터 뒤에 %q", ErrNoFooter, line)
+	}
+	switch _, err := r.br.ReadByte(); err {
+	case io.EOF:
+		return nil
+	case nil:
+		return errors.New("wrc: 푸터 위치 뒤에 데이터가 있음")
+	default:
+		return err
+	}
+}
+
+// parseFooter는 "WRC-Footer" 다음 줄부터 빈 줄까지의 푸터 필드를 읽습니다.
+func parseFooter(r *Reader) (*Footer, error) {
+	f := &Footer{}
+	err := r.readFields(func(name, value string) error {
+		var err error
+		switch name {
+		case "Records":
+			f.Records, err = strconv.ParseInt(value, 10, 64)
+		case "Checksum":
+			f.Checksum = value
+		case "Offsets":
+			for _, s := range strings.Fields(value) {
+				o, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return fmt.Errorf("wrc 푸터 오프셋 오류: %q", s)
+				}
+				f.Offsets = append(f.Offsets, o)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wrc 푸터 읽기 오류: %w", err)
+	}
+	return f, nil
+}
+
+// readLine은 줄 하나를 끝의 공백을 제거하여 읽습니다. 줄바꿈 없이 입력이 끝나면 읽은 내용과 io.ErrUnexpectedEOF를 반환합니다.
+func (r *Reader) readLine() (string, error) {
+	line, err := r.br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		return strings.TrimSpace(line), io.ErrUnexpectedEOF
+	}
+	return strings.TrimSpace(line), err
+}
+
+// unexpected는 레코드 도중의 io.EOF를 io.ErrUnexpectedEOF로 바꿉니다.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// All은 남은 레코드를 차례로 돌려주는 반복자입니다.
+// 오류가 나면 (nil, 오류)를 한 번 돌려주고 끝납니다. 잘린 파일은 ErrTruncated로 알립니다.
+func (r *Reader) All() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for {
+			rec, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(rec, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Count는 지금까지 읽은 레코드 수입니다.
+func (r *Reader) Count() int64 {
+	return r.n
+}
+
+// Close는 gzip 리더를 닫습니다. 기반 io.Reader는 닫지 않습니다.
+func (r *Reader) Close() error {
+	if r.gz == nil {
+		return nil
+	}
+	return r.gz.Close()
+}
+
+// ReadFooter는 파일 끝의 푸터 위치를 읽어 v2 파일의 푸터를 반환합니다.
+// v1 파일이거나 끝이 잘린 파일이면 ErrNoFooter를 반환합니다.
+func ReadFooter(r io.ReaderAt, size int64) (*Footer, error) {
+	if size < locatorSize {
+		return nil, ErrNoFooter
+	}
+	gz, err := gzip.NewReader(io.NewSectionReader(r, size-locatorSize, locatorSize))
+	if err != nil {
+		return nil, ErrNoFooter
+	}
+	loc, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, ErrNoFooter
+	}
+	s, ok := strings.CutPrefix(strings.TrimSpace(string(loc)), locatorPrefix)
+	if !ok {
+		return nil, ErrNoFooter
+	}
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 || offset >= size-locatorSize {
+		return nil, ErrNoFooter
+	}
+
+	fr, err := openMember(r, offset, size-locatorSize-offset)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+	if line, err := fr.readLine(); err != nil || line != footerMagic {
+		return nil, ErrNoFooter
+	}
+	f, err := parseFooter(fr)
+	if err != nil {
+		return nil, err
+	}
+	f.Offset = offset
+	return f, nil
+}
+
+// ReadRecord는 offset(푸터나 CDXJ 인덱스의 오프셋)에서 시작하는 gzip 멤버의 레코드 하나를 읽습니다.
+func ReadRecord(r io.ReaderAt, offset int64) (*Record, error) {
+	rr, err := openMember(r, offset, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+	defer rr.Close()
+
+	if prefix, _ := rr.br.Peek(len(footerMagic)); strings.HasPrefix(string(prefix), magic) || string(prefix) == footerMagic {
+		return nil, fmt.Errorf("wrc: 오프셋 %d는 레코드가 아닌 헤더 또는 푸터입니다", offset)
+	}
+	rec, err := rr.next()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wrc 레코드 읽기 오류(오프셋 %d): %w", offset, err)
+	}
+	return rec, nil
+}
+
+// openMember는 offset의 gzip 멤버 하나만 읽는 Reader를 만듭니다.
+func openMember(r io.ReaderAt, offset, n int64) (*Reader, error) {
+	gz, err := gzip.NewReader(io.NewSectionReader(r, offset, n))
+	if err != nil {
+		return nil, fmt.Errorf("wrc gzip 멤버 오류(오프셋 %d): %w", offset, err)
+	}
+	gz.Multistream(false)
+	return &Reader{gz: gz, br: bufio.NewReader(gz), header: Header{Version: 1}}, nil
+}
@@ -0,0 +1,167 @@
+package wrc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReaderV1(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		urls  []string
+		err   error // 레코드를 모두 읽은 뒤의 오류, nil이면 io.EOF
+	}{
+		{"레코드 두 개", "https://a.com/\n3\nabc\n\nhttps://b.com/\n0\n\n\n",
+			[]string{"https://a.com/", "https://b.com/"}, nil},
+		{"판정 줄", "https://a.com/\nnews\n3\nabc\n\nhttps://b.com/\n뉴스 아님\n2\nde\n\n",
+			[]string{"https://a.com/", "https://b.com/"}, nil},
+		{"레코드 사이 빈 줄", "\n\nhttps://a.com/\n3\nabc\n\n\n\nhttps://b.com/\n1\nx\n\n\n",
+			[]string{"https://a.com/", "https://b.com/"}, nil},
+		{"본문에 빈 줄", "https://a.com/\n6\n\n\nab\n\n\n\n",
+			[]string{"https://a.com/"}, nil},
+		{"마지막 본문 잘림", "https://a.com/\n3\nabc\n\nhttps://b.com/\n10\nabc",
+			[]string{"https://a.com/"}, ErrTruncated},
+		{"길이 줄 뒤에서 잘림", "https://a.com/\n3\nabc\n\nhttps://b.com/\n10\n",
+			[]string{"https://a.com/"}, ErrTruncated},
+		{"URL 줄에서 잘림", "https://a.com/\n3\nabc\n\nhttps://b.c",
+			[]string{"https://a.com/"}, ErrTruncated},
+		{"판정 줄 뒤에서 잘림", "https://a.com/\nnews\n",
+			nil, ErrTruncated},
+		{"끝 빈 줄 없음", "https://a.com/\n3\nabc\n\nhttps://b.com/\n3\nabc",
+			[]string{"https://a.com/"}, ErrTruncated},
+		{"끝 빈 줄 하나", "https://a.com/\n3\nabc\n",
+			nil, ErrTruncated},
+		{"본문 뒤 빈 줄 누락", "https://a.com/\n3\nabcd\n\nhttps://b.com/\n1\nx\n\n",
+			nil, ErrTrailer},
+		{"길이 오류", "https://a.com/\nnews\nten\nabc\n\n",
+			nil, ErrLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := members(t, tt.input)
+			r, err := NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			var urls []string
+			var last error
+			for {
+				rec, err := r.Next()
+				if err != nil {
+					last = err
+					break
+				}
+				urls = append(urls, rec.URL)
+			}
+			if len(urls) != len(tt.urls) {
+				t.Fatalf("레코드 %q, want %q (오류 %v)", urls, tt.urls, last)
+			}
+			for i := range urls {
+				if urls[i] != tt.urls[i] {
+					t.Errorf("레코드 %d = %q, want %q", i, urls[i], tt.urls[i])
+				}
+			}
+			if tt.err == nil && last != io.EOF || tt.err != nil && !errors.Is(last, tt.err) {
+				t.Errorf("오류 = %v, want %v", last, tt.err)
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("오류 뒤 Next = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReaderLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		want  Record
+	}{
+		{"판정 줄", "https://a.com/\nLabel: news\n3\nabc\n\n", Record{URL: "https://a.com/", Label: "news", Content: []byte("abc")}},
+		{"숫자 판정", "https://a.com/\nLabel: 1\n3\nabc\n\n", Record{URL: "https://a.com/", Label: "1", Content: []byte("abc")}},
+		{"판정 없음", "https://b.com/\n3\ndef\n\n", Record{URL: "https://b.com/", Content: []byte("def")}},
+		{"접두사 없는 이전 판정", "https://a.com/\nnews\n3\nabc\n\n", Record{URL: "https://a.com/", Label: "news", Content: []byte("abc")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := members(t, tt.frame)
+			_, recs, err := readAll(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recs) != 1 || !equalRecord(recs[0], &tt.want) {
+				t.Errorf("레코드 %+v, want %+v", recs, tt.want)
+			}
+		})
+	}
+
+	// Writer가 기록한 판정은 숫자여도 길이와 구별됨
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Record{
+		{URL: "https://a.com/", Label: "3", Content: []byte("abc")},
+		{URL: "https://b.com/", Label: " 두 줄\n판정 ", Content: []byte("def")},
+	}
+	for _, rec := range want {
+		if _, _, err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, recs, err := readAll(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want[1].Label = "두 줄 판정"
+	if len(recs) != len(want) || !equalRecord(recs[0], want[0]) || !equalRecord(recs[1], want[1]) {
+		t.Errorf("레코드 %+v", recs)
+	}
+}
+
+func TestReaderAllStopsAfterError(t *testing.T) {
+	data, _ := members(t, "https://a.com/\n3\nabc\n\nhttps://b.com/\n10\nabc")
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs, errs int
+	for rec, err := range r.All() {
+		if err != nil {
+			if rec != nil || !errors.Is(err, ErrTruncated) {
+				t.Errorf("(%v, %v)", rec, err)
+			}
+			errs++
+			continue
+		}
+		recs++
+	}
+	if recs != 1 || errs != 1 || r.Count() != 1 {
+		t.Errorf("레코드 %d개, 오류 %d개, Count %d", recs, errs, r.Count())
+	}
+}
+
+func TestReaderEmpty(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next = %v, want io.EOF", err)
+	}
+	if r.Header().Version != 1 {
+		t.Errorf("Header = %+v", r.Header())
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,179 @@
+package wrc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"parkjunwoo.com/crowl/internal/iox"
+)
+
+// 컨테이너 구조를 나타내는 줄
+const (
+	magic         = "WRC/"       // 헤더 첫 줄 접두사, 뒤에 형식 버전
+	footerMagic   = "WRC-Footer" // 푸터 첫 줄
+	locatorPrefix = "WRC-Footer-Offset: "
+	labelPrefix   = "Label: " // 레코드 url 다음의 판정 줄 접두사
+)
+
+// FormatVersion은 Writer가 기록하는 컨테이너 형식 버전입니다.
+const FormatVersion = 2
+
+// Header는 v2 파일 첫 gzip 멤버에 기록하는 파일 정보입니다. v1 파일은 Version만 1입니다.
+type Header struct {
+	Version     int
+	Source      string // 원본 WARC/WET/WAT 파일 이름
+	Crowl       string // 파일을 만든 crowl 버전
+	Fingerprint string // 파일을 만든 처리 설정의 지문
+}
+
+// Footer는 v2 파일 끝에 기록하는 레코드 색인입니다.
+type Footer struct {
+	Records  int64
+	Offsets  []int64 // 레코드별 gzip 멤버의 파일 내 오프셋
+	Checksum string  // 압축 전 레코드 프레임 전체의 "sha256:<hex>"
+	Offset   int64   // 푸터 gzip 멤버의 파일 내 오프셋 (ReadFooter에서만 채움)
+}
+
+// Writer는 v2 wrc 파일을 기록합니다. 헤더, 레코드, 푸터를 각각 독립된 gzip 멤버로 압축하여
+// 푸터의 오프셋으로 레코드 하나만 읽을 수 있게 합니다. 동시에 사용할 수 없습니다.
+type Writer struct {
+	w       io.Writer
+	gw      *gzip.Writer
+	offset  int64
+	offsets []int64
+	sum     hash.Hash
+	closed  bool
+}
+
+// NewWriter는 w에 헤더를 기록하고 레코드를 기록할 Writer를 반환합니다. h.Version은 무시합니다.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	ww := &Writer{w: w, gw: gzip.NewWriter(io.Discard), sum: sha256.New()}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%d\n", magic, FormatVersion)
+	for _, f := range [][2]string{{"Source", h.Source}, {"Crowl-Version", h.Crowl}, {"Config-Fingerprint", h.Fingerprint}} {
+		if f[1] != "" {
+			fmt.Fprintf(&b, "%s: %s\n", f[0], oneLine(f[1]))
+		}
+	}
+	b.WriteString("\n")
+
+	if _, _, err := ww.member([]byte(b.String())); err != nil {
+		return nil, fmt.Errorf("wrc 헤더 기록 오류: %w", err)
+	}
+	return ww, nil
+}
+
+// Write는 레코드 하나를 gzip 멤버 하나로 기록하고 그 오프셋과 길이를 반환합니다.
+func (w *Writer) Write(rec *Record) (int64, int64, error) {
+	if w.closed {
+		return 0, 0, errors.New("wrc: 닫힌 Writer")
+	}
+	frame := appendFrame(nil, rec)
+	offset, length, err := w.member(frame)
+	if err != nil {
+		return 0, 0, err
+	}
+	w.offsets = append(w.offsets, offset)
+	w.sum.Write(frame)
+	return offset, length, nil
+}
+
+// Close는 푸터와 푸터 위치를 기록합니다. 기반 io.Writer는 닫지 않습니다.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nRecords: %d\nChecksum: sha256:%s\nOffsets:", footerMagic, len(w.offsets), hex.EncodeToString(w.sum.Sum(nil)))
+	for _, o := range w.offsets {
+		b.WriteString(" ")
+		b.WriteString(strconv.FormatInt(o, 10))
+	}
+	b.WriteString("\n\n")
+
+	footerOffset, _, err := w.member([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("wrc 푸터 기록 오류: %w", err)
+	}
+	loc, err := locator(footerOffset)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(loc)
+	return err
+}
+
+// member는 p를 gzip 멤버 하나로 기록하고 그 오프셋과 길이를 반환합니다.
+func (w *Writer) member(p []byte) (int64, int64, error) {
+	cw := &iox.CountWriter{W: w.w}
+	w.gw.Reset(cw)
+	if _, err := w.gw.Write(p); err != nil {
+		return 0, 0, err
+	}
+	if err := w.gw.Close(); err != nil {
+		return 0, 0, err
+	}
+	offset := w.offset
+	w.offset += cw.N
+	return offset, cw.N, nil
+}
+
+// appendFrame은 레코드의 압축 전 프레임("url\n[Label: 판정\n]길이\n본문\n\n")을 dst에 덧붙입니다.
+func appendFrame(dst []byte, rec *Record) []byte {
+	label := oneLine(rec.Label)
+	if label != "" {
+		label = labelPrefix + label
+	}
+	return appendRawFrame(dst, rec.URL, label, rec.Content)
+}
+
+// appendRawFrame은 판정 줄 labelLine(비어 있으면 생략)을 그대로 써서 프레임을 덧붙입니다.
+// Reader는 접두사 없는 이전 판정 줄도 읽은 그대로 체크섬에 넣습니다.
+func appendRawFrame(dst []byte, url, labelLine string, content []byte) []byte {
+	dst = append(dst, url...)
+	dst = append(dst, '\n')
+	if labelLine != "" {
+		dst = append(dst, labelLine...)
+		dst = append(dst, '\n')
+	}
+	dst = strconv.AppendInt(dst, int64(len(content)), 10)
+	dst = append(dst, '\n')
+	dst = append(dst, content...)
+	return append(dst, '\n', '\n')
+}
+
+// oneLine은 줄바꿈을 공백으로 바꾸고 앞뒤 공백을 지웁니다.
+func oneLine(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(s))
+}
+
+// locator는 파일 끝에 붙는 고정 크기 gzip 멤버로, 푸터의 오프셋을 담습니다.
+// 압축하지 않고 저장하므로 오프셋 값과 관계없이 크기가 locatorSize로 일정합니다.
+func locator(footerOffset int64) ([]byte, error) {
+	var buf bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&buf, gzip.NoCompression)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(gw, "%s%020d\n", locatorPrefix, footerOffset)
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var locatorSize = func() int64 {
+	b, _ := locator(0)
+	return int64(len(b))
+}()
@@ -0,0 +1,283 @@
+package wrc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testRecords = []*Record{
+	{URL: "https://example.com/a", Content: []byte("첫 번째 본문\n여러 줄")},
+	{URL: "https://example.com/b", Label: "news", Content: []byte("두 번째 본문")},
+	{URL: "https://example.com/empty", Content: []byte{}},
+	{URL: "https://example.com/blank", Label: "other", Content: []byte("\n\n빈 줄로 끝나는 본문\n\n")},
+}
+
+var testHeader = Header{Source: "CC-NEWS-20250401000000-00001.warc.gz", Crowl: "v1.2.3", Fingerprint: "sha256:abcd"}
+
+// writeV2는 recs를 v2 파일로 기록하고 파일 내용과 Write가 반환한 오프셋·길이를 반환합니다.
+func writeV2(t *testing.T, recs []*Record) ([]byte, [][2]int64) {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spans [][2]int64
+	for _, rec := range recs {
+		offset, length, err := w.Write(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, [2]int64{offset, length})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), spans
+}
+
+// readAll은 data의 레코드를 끝까지 읽고 첫 오류를 반환합니다.
+func readAll(data []byte) (*Reader, []*Record, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+	var recs []*Record
+	for rec, err := range r.All() {
+		if err != nil {
+			return r, recs, err
+		}
+		recs = append(recs, rec)
+	}
+	return r, recs, nil
+}
+
+func equalRecord(a, b *Record) bool {
+	return a.URL == b.URL && a.Label == b.Label && bytes.Equal(a.Content, b.Content)
+}
+
+func TestRoundTrip(t *testing.T) {
+	data, spans := writeV2(t, testRecords)
+
+	r, recs, err := readAll(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := r.Header(); h != (Header{Version: 2, Source: testHeader.Source, Crowl: testHeader.Crowl, Fingerprint: testHeader.Fingerprint}) {
+		t.Errorf("Header = %+v", h)
+	}
+	if len(recs) != len(testRecords) || r.Count() != int64(len(testRecords)) {
+		t.Fatalf("레코드 %d개(Count %d), 기대값 %d", len(recs), r.Count(), len(testRecords))
+	}
+	for i := range recs {
+		if !equalRecord(recs[i], testRecords[i]) {
+			t.Errorf("레코드 %d = %+v, want %+v", i, recs[i], testRecords[i])
+		}
+	}
+
+	f := r.Footer()
+	if f == nil {
+		t.Fatal("푸터 없음")
+	}
+	if f.Records != int64(len(testRecords)) || len(f.Offsets) != len(testRecords) {
+		t.Fatalf("Footer = %+v", f)
+	}
+	var frames []byte
+	for _, rec := range testRecords {
+		frames = appendFrame(frames, rec)
+	}
+	if sum := sha256.Sum256(frames); f.Checksum != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("Checksum = %s", f.Checksum)
+	}
+
+	// 푸터 오프셋은 Write가 반환한 오프셋과 같고, 멤버가 빈틈없이 이어짐
+	for i, o := range f.Offsets {
+		if o != spans[i][0] {
+			t.Errorf("Offsets[%d] = %d, Write 오프셋 %d", i, o, spans[i][0])
+		}
+		if i > 0 && spans[i-1][0]+spans[i-1][1] != o {
+			t.Errorf("레코드 %d 오프셋 %d가 앞 멤버의 끝 %d와 다름", i, o, spans[i-1][0]+spans[i-1][1])
+		}
+	}
+
+	ff, err := ReadFooter(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := spans[len(spans)-1]
+	if ff.Offset != last[0]+last[1] || ff.Records != f.Records || ff.Checksum != f.Checksum || fmt.Sprint(ff.Offsets) != fmt.Sprint(f.Offsets) {
+		t.Errorf("ReadFooter = %+v, 스트림 푸터 %+v", ff, f)
+	}
+
+	for i, o := range ff.Offsets {
+		rec, err := ReadRecord(bytes.NewReader(data), o)
+		if err != nil {
+			t.Fatalf("ReadRecord(%d): %v", o, err)
+		}
+		if !equalRecord(rec, testRecords[i]) {
+			t.Errorf("ReadRecord(%d) = %+v, want %+v", o, rec, testRecords[i])
+		}
+	}
+	for _, o := range []int64{0, ff.Offset} {
+		if _, err := ReadRecord(bytes.NewReader(data), o); err == nil {
+			t.Errorf("ReadRecord(%d): 헤더·푸터에서 오류가 없습니다", o)
+		}
+	}
+}
+
+func TestRoundTripEmpty(t *testing.T) {
+	data, _ := writeV2(t, nil)
+	r, recs, err := readAll(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 0 || r.Footer() == nil || r.Footer().Records != 0 {
+		t.Errorf("레코드 %d개, Footer = %+v", len(recs), r.Footer())
+	}
+	if _, err := ReadFooter(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error(err)
+	}
+}
+
+// 파일을 어느 바이트에서 자르더라도 레코드를 끝까지 읽으면 ErrTruncated가, ReadFooter는 ErrNoFooter가 나와야 합니다.
+func TestTruncatedEveryByte(t *testing.T) {
+	data, _ := writeV2(t, testRecords)
+
+	for n := 1; n < len(data); n++ {
+		prefix := data[:n]
+		_, recs, err := readAll(prefix)
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("%d바이트에서 자름: 레코드 %d개, 오류 %v", n, len(recs), err)
+		}
+		for i, rec := range recs {
+			if !equalRecord(rec, testRecords[i]) {
+				t.Errorf("%d바이트에서 자름: 레코드 %d = %+v", n, i, rec)
+			}
+		}
+		if _, err := ReadFooter(bytes.NewReader(prefix), int64(n)); !errors.Is(err, ErrNoFooter) {
+			t.Errorf("%d바이트에서 자름: ReadFooter 오류 %v", n, err)
+		}
+	}
+
+	// 0바이트 파일은 빈 입력으로 봄
+	if _, recs, err := readAll(nil); err != nil || len(recs) != 0 {
+		t.Errorf("빈 입력: 레코드 %d개, 오류 %v", len(recs), err)
+	}
+}
+
+// members는 parts를 각각 gzip 멤버 하나로 압축하여 이어 붙이고, 멤버별 오프셋을 반환합니다.
+func members(t *testing.T, parts ...string) ([]byte, []int64) {
+	t.Helper()
+	var buf bytes.Buffer
+	var offsets []int64
+	for _, p := range parts {
+		offsets = append(offsets, int64(buf.Len()))
+		gw := gzip.NewWriter(&buf)
+		if _, err := io.WriteString(gw, p); err != nil {
+			t.Fatal(err)
+		}
+		if err := gw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes(), offsets
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	a := string(appendFrame(nil, testRecords[0]))
+	b := string(appendFrame(nil, testRecords[1]))
+	sumAB := sha256.Sum256([]byte(a + b))
+
+	tests := []struct {
+		name   string
+		frames []string
+		footer string
+	}{
+		{"본문 변조", []string{a, strings.Replace(b, "두", "세", 1)},
+			fmt.Sprintf("Records: 2\nChecksum: sha256:%x\n", sumAB)},
+		{"레코드 수 불일치", []string{a, b},
+			fmt.Sprintf("Records: 3\nChecksum: sha256:%x\n", sumAB)},
+		{"레코드 누락", []string{a},
+			fmt.Sprintf("Records: 2\nChecksum: sha256:%x\n", sumAB)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := append([]string{"WRC/2\nSource: x\n\n"}, tt.frames...)
+			parts = append(parts, footerMagic+"\n"+tt.footer+"\n")
+			data, offsets := members(t, parts...)
+			loc, err := locator(offsets[len(offsets)-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = append(data, loc...)
+
+			if _, _, err := readAll(data); !errors.Is(err, ErrChecksum) {
+				t.Errorf("오류 = %v, want ErrChecksum", err)
+			}
+		})
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	data, _ := members(t, "WRC/3\n\n")
+	if _, err := NewReader(bytes.NewReader(data)); !errors.Is(err, ErrVersion) {
+		t.Errorf("오류 = %v, want ErrVersion", err)
+	}
+}
+
+func TestLegacyV1(t *testing.T) {
+	var frames []string
+	for _, rec := range testRecords {
+		frames = append(frames, string(appendFrame(nil, rec)))
+	}
+	single, _ := members(t, strings.Join(frames, ""))
+	multi, offsets := members(t, frames...)
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"단일 스트림", single},
+		{"레코드별 멤버", multi},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, recs, err := readAll(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Header().Version != 1 || r.Footer() != nil {
+				t.Errorf("Header = %+v, Footer = %+v", r.Header(), r.Footer())
+			}
+			if len(recs) != len(testRecords) {
+				t.Fatalf("레코드 %d개, 기대값 %d", len(recs), len(testRecords))
+			}
+			for i := range recs {
+				if !equalRecord(recs[i], testRecords[i]) {
+					t.Errorf("레코드 %d = %+v, want %+v", i, recs[i], testRecords[i])
+				}
+			}
+			if _, err := ReadFooter(bytes.NewReader(tt.data), int64(len(tt.data))); !errors.Is(err, ErrNoFooter) {
+				t.Errorf("ReadFooter 오류 = %v, want ErrNoFooter", err)
+			}
+		})
+	}
+
+	// 레코드별 멤버 v1 파일은 CDXJ 인덱스의 오프셋으로 레코드 하나를 읽을 수 있음
+	for i, o := range offsets {
+		rec, err := ReadRecord(bytes.NewReader(multi), o)
+		if err != nil {
+			t.Fatalf("ReadRecord(%d): %v", o, err)
+		}
+		if !equalRecord(rec, testRecords[i]) {
+			t.Errorf("ReadRecord(%d) = %+v", o, rec)
+		}
+	}
+}
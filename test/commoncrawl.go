@@ -1,20 +1,18 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
-	"strings"
 
 	"parkjunwoo.com/crowl/pkg/crowl"
+	"parkjunwoo.com/crowl/pkg/warc"
 )
 
 func main() {
-	cc, err := crowl.NewCommonCrawl("../config/commoncrawl.yaml")
+	cc, err := crowl.NewCommonCrawl("../config/crowl.yaml")
 	if err != nil {
 		panic(err)
 	}
@@ -44,8 +42,6 @@ func parseWarcTest(cc *crowl.CommonCrawl, filePath string, savePath string) erro
 	}
 	defer gzReader.Close()
 
-	// bufio.Reader를 사용하여 파일을 줄 단위로 읽기
-	reader := bufio.NewReader(gzReader)
 	if err := os.MkdirAll(cc.DataDir, os.ModePerm); err != nil {
 		return err
 	}
@@ -59,38 +55,26 @@ func parseWarcTest(cc *crowl.CommonCrawl, filePath string, savePath string) erro
 
 	// 읽고 작업 전달
 	iu := 0
+	errorCount := 0
+	const maxErrors = 10
+	wr := warc.NewReader(gzReader)
 	for {
-		headerLines := []string{}
-		errorCount := 0
-		const maxErrors = 10
-		for {
-			line, err := reader.ReadString('\n')
-			if err == io.EOF {
-				goto FINISH
-			}
-			if err != nil {
-				fmt.Println("[워커] 헤더 읽기 오류:", err)
-				goto FINISH
-			}
-
-			line = strings.TrimRight(line, "\r\n")
-			if line == "" {
-				break
-			}
-			headerLines = append(headerLines, line)
+		rec, err := wr.Next()
+		if err == io.EOF {
+			goto FINISH
+		}
+		if err != nil {
+			fmt.Println("[워커] 레코드 읽기 오류:", err)
+			goto FINISH
 		}
 
-		header := cc.ParseHeader(headerLines)
-
-		if header["WARC-Type"] != "response" {
-			skipBytes(reader, header["Content-Length"])
+		if rec.Type() != warc.TypeResponse {
 			continue
 		}
 
-		url := header["WARC-Target-URI"]
-		contentLength, _ := strconv.Atoi(header["Content-Length"])
-		content := make([]byte, contentLength)
-		if _, err = io.ReadFull(reader, content); err != nil {
+		url := rec.TargetURI()
+		content, err := io.ReadAll(rec.Body)
+		if err != nil {
 			fmt.Println("[워커] 본문 읽기 오류:", err)
 			errorCount++
 			if errorCount > maxErrors {
@@ -137,9 +121,3 @@ func parseWarcTest(cc *crowl.CommonCrawl, filePath string, savePath string) erro
 FINISH:
 	return nil
 }
-
-// skipBytes는 지정된 길이만큼 바이트를 건너뜁니다.
-func skipBytes(reader *bufio.Reader, lenStr string) {
-	n, _ := strconv.Atoi(lenStr)
-	io.CopyN(io.Discard, reader, int64(n))
-}